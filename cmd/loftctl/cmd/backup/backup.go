@@ -0,0 +1,750 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/clihelper"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/survey"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	scheme = runtime.NewScheme()
+
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = storagev1.AddToScheme(scheme)
+)
+
+// BackupCmd holds the cmd flags
+type BackupCmd struct {
+	*flags.GlobalFlags
+
+	EncryptionFlags
+	S3Flags
+
+	Namespace   string
+	Include     []string
+	Exclude     []string
+	Skip        []string
+	Filename    string
+	Directory   string
+	Strict      bool
+	Concurrency int
+	ViaLoft     bool
+	Keep        int
+
+	Log log.Logger
+}
+
+// NewBackupCmd creates a new command
+func NewBackupCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &BackupCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+##################### loft backup #####################
+#######################################################
+Backup creates a backup for the Loft management plane.
+All storage.loft.sh resources the server serves are
+backed up together with the secrets they reference and
+the project secrets, except tasks. Use --include or
+--exclude to select the resources.
+
+The backup contains secrets such as user passwords and
+access keys. Use --encrypt to encrypt it with a
+passphrase read from LOFT_BACKUP_PASSPHRASE or asked
+for, or --recipient to encrypt it for a public key
+created by loft backup keygen.
+
+Large backups can be written with --directory to a
+directory with one file per resource.
+
+Use --target to upload the backup to S3 compatible
+object storage such as AWS S3 or MinIO under a
+timestamped name, and --keep to remove older backups.
+Credentials are read from the flags or from
+AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+
+Without access to the cluster Loft runs in, use
+--via-loft to back up through the Loft api as the
+logged in admin. Such backups don't contain the
+passwords of users and the configs of clusters.
+
+Example:
+loft backup
+loft backup --exclude accesskeys,projectsecrets
+loft backup --directory backup
+loft backup --via-loft
+loft backup --target s3://backups/loft --keep 7
+loft backup --encrypt
+loft backup --recipient loft-backup-pk-...
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "backup",
+		Short: "Create a loft management plane backup",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd, args)
+		},
+	}
+
+	c.Flags().StringSliceVar(&cmd.Include, "include", []string{}, "The only resources the backup should contain, e.g. users, projects or devpodworkspaceinstances. By default all storage.loft.sh resources the server serves are backed up")
+	c.Flags().StringSliceVar(&cmd.Exclude, "exclude", []string{}, "What resources the backup should skip, e.g. users, accesskeys or projectsecrets")
+	c.Flags().StringSliceVar(&cmd.Skip, "skip", []string{}, "What resources the backup should skip")
+	_ = c.Flags().MarkDeprecated("skip", "please use --exclude instead")
+	c.Flags().StringVar(&cmd.Namespace, "namespace", "loft", "The namespace to loft was installed into")
+	c.Flags().StringVar(&cmd.Filename, "filename", "backup.yaml", "The filename to write the backup to")
+	c.Flags().StringVar(&cmd.Directory, "directory", "", "If set, writes the backup to this directory with a manifest and one file per resource instead of --filename")
+	c.Flags().IntVar(&cmd.Concurrency, "concurrency", 4, "The number of resources that are backed up at the same time")
+	c.Flags().BoolVar(&cmd.ViaLoft, "via-loft", false, "If enabled, backs up the resources through the loft api as the logged in user instead of the current kube context. Secrets referenced by users and clusters are not backed up")
+	addS3Flags(c, &cmd.S3Flags, "If set, uploads the backup to this S3 compatible object storage target in the form s3://bucket/prefix instead of writing it to --filename")
+	c.Flags().IntVar(&cmd.Keep, "keep", 0, "If set, removes all but the newest backups in --target after a complete backup. By default no backups are removed")
+	c.Flags().BoolVar(&cmd.Strict, "strict", false, "If enabled, fails instead of writing an incomplete backup if a resource can't be backed up")
+	c.Flags().BoolVar(&cmd.Encrypt, "encrypt", false, "If enabled, encrypts the backup with a passphrase from "+PassphraseEnv+" or the prompt")
+	c.Flags().StringVar(&cmd.Recipient, "recipient", "", "The public key or a file containing the public key to encrypt the backup for")
+
+	c.AddCommand(NewInspectCmd(globalFlags))
+	c.AddCommand(NewRestoreCmd(globalFlags))
+	c.AddCommand(NewKeygenCmd(globalFlags))
+	c.AddCommand(NewScheduleCmd(globalFlags))
+	c.AddCommand(NewDiffCmd(globalFlags))
+	return c
+}
+
+// Run executes the functionality
+func (cmd *BackupCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	// ask for the passphrase before the backup is created
+	recipient, err := cmd.recipient(cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	// backups for a target are written to a temporary file first
+	filename := cmd.Filename
+	var (
+		s3Client *pbackup.S3Client
+		s3Target *pbackup.S3Target
+	)
+	if cmd.Keep > 0 && cmd.Target == "" {
+		return fmt.Errorf("--keep can only be used together with --target")
+	} else if cmd.Target != "" {
+		if cmd.Directory != "" {
+			return fmt.Errorf("--directory can't be used together with --target")
+		}
+
+		s3Client, s3Target, err = cmd.S3Flags.client()
+		if err != nil {
+			return err
+		}
+
+		uploadDir, err := os.MkdirTemp("", "loft-backup-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(uploadDir)
+
+		filename = filepath.Join(uploadDir, pbackup.BackupName(time.Now()))
+	}
+
+	restConfig, kubeClient, err := cmd.restConfig()
+	if err != nil {
+		return err
+	}
+
+	serverVersion, err := cmd.serverVersion(cobraCmd.Context(), kubeClient)
+	if err != nil {
+		if cmd.Strict {
+			return err
+		}
+
+		cmd.Log.Warn(err)
+	}
+
+	// objects are streamed into temporary files next to the backup, so they don't need to be
+	// held in memory and don't end up on another file system
+	target := filename
+	if cmd.Directory != "" {
+		target = cmd.Directory
+	}
+	tempDir, err := os.MkdirTemp(filepath.Dir(filepath.Clean(target)), ".loft-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	manifest := pbackup.NewManifest(serverVersion, upgrade.GetVersion())
+	manifest.ViaLoft = cmd.ViaLoft
+	backups, err := cmd.backupResources(cobraCmd.Context(), restConfig, kubeClient, manifest, tempDir)
+	if err != nil {
+		return err
+	}
+	defer removeResourceBackups(backups)
+	if cmd.Strict && !manifest.Complete() {
+		return fmt.Errorf("resources not served by the server: %s", strings.Join(manifest.NotServed, ", "))
+	}
+
+	cmd.Log.Infof("Writing backup to %s...", target)
+	if cmd.Directory != "" {
+		err = writeBackupDirectory(cmd.Directory, manifest, backups, recipient)
+	} else {
+		err = writeBackup(filename, manifest, backups, recipient)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s3Target != nil {
+		key := s3Target.Key(filepath.Base(filename))
+		target = s3Target.URL(key)
+		cmd.Log.Infof("Uploading backup to %s...", target)
+		err = upload(cobraCmd.Context(), s3Client, s3Target, filename, key)
+		if err != nil {
+			return err
+		}
+
+		// an incomplete backup doesn't replace a complete one
+		if manifest.Complete() {
+			err = prune(cobraCmd.Context(), s3Client, s3Target, cmd.Keep, cmd.Log)
+			if err != nil {
+				return err
+			}
+		} else if cmd.Keep > 0 {
+			cmd.Log.Warnf("Not removing old backups from %s, because the backup is incomplete", s3Target)
+		}
+	}
+
+	if !manifest.Complete() {
+		missing := append([]string{}, manifest.NotServed...)
+		for resource := range manifest.Failed {
+			missing = append(missing, resource)
+		}
+		sort.Strings(missing)
+
+		cmd.Log.Warnf("Wrote incomplete backup to %s, missing resources: %s", target, strings.Join(missing, ", "))
+		return nil
+	}
+
+	cmd.Log.Donef("Wrote backup to %s", target)
+	return nil
+}
+
+// restConfig returns the config to back up the loft resources with. Through the loft api secrets
+// can't be read, so no kube client is returned.
+func (cmd *BackupCmd) restConfig() (*rest.Config, kubernetes.Interface, error) {
+	if cmd.ViaLoft {
+		restConfig, err := managementConfig(cmd.Config)
+		return restConfig, nil, err
+	}
+
+	// first load the kube config
+	kubeClientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+
+	// load the raw config
+	kubeConfig, err := kubeClientConfig.ClientConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	isInstalled, err := clihelper.IsLoftAlreadyInstalled(kubeClient, cmd.Namespace)
+	if err != nil {
+		return nil, nil, err
+	} else if !isInstalled {
+		answer, err := cmd.Log.Question(&survey.QuestionOptions{
+			Question:     "Seems like Loft was not installed into namespace %s, do you want to continue?",
+			DefaultValue: "Yes",
+			Options:      []string{"Yes", "No"},
+		})
+		if err != nil {
+			return nil, nil, err
+		} else if answer != "Yes" {
+			return nil, nil, errors.New("backup canceled")
+		}
+	}
+
+	return kubeConfig, kubeClient, nil
+}
+
+// managementConfig returns the config of the loft api for the logged in user
+func managementConfig(configPath string) (*rest.Config, error) {
+	baseClient, err := client.NewClientFromPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := baseClient.ManagementConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "create loft api config, please make sure you are logged into loft")
+	}
+
+	return restConfig, nil
+}
+
+// serverVersion returns the version of loft. Backups from the cluster read it from the loft
+// deployment, as scheduled backups run without a loft config. Backups via loft ask the loft api.
+func (cmd *BackupCmd) serverVersion(ctx context.Context, kubeClient kubernetes.Interface) (string, error) {
+	if kubeClient != nil {
+		version, err := clihelper.LoftVersion(ctx, kubeClient, cmd.Namespace)
+		if err != nil {
+			return "", errors.Wrap(err, "determine loft version")
+		}
+
+		return version, nil
+	}
+
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return "", errors.Wrap(err, "determine loft version")
+	}
+
+	version, err := baseClient.Version()
+	if err != nil {
+		return "", errors.Wrap(err, "determine loft version, please make sure you are logged into loft")
+	}
+
+	return version.Version, nil
+}
+
+// writeBackup writes the manifest and the backed up resources to the file
+func writeBackup(filename string, manifest *pbackup.Manifest, backups []*resourceBackup, recipient pbackup.Recipient) error {
+	parts := []io.ReadSeeker{}
+	for _, backup := range backups {
+		parts = append(parts, backup.file)
+	}
+
+	return writeFile(filename, recipient, func(w io.Writer) error {
+		return pbackup.Write(w, manifest, parts...)
+	})
+}
+
+// writeBackupDirectory writes every backed up resource to its own file in the directory. The
+// files are listed with their checksum in the manifest, which is written last. If writing fails,
+// the files written so far are removed again.
+func writeBackupDirectory(dir string, manifest *pbackup.Manifest, backups []*resourceBackup, recipient pbackup.Recipient) (retErr error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	written := []string{}
+	defer func() {
+		if retErr != nil {
+			for _, filename := range written {
+				_ = os.Remove(filename)
+			}
+		}
+	}()
+
+	for i, backup := range backups {
+		if len(backup.objects) == 0 {
+			continue
+		}
+
+		_, err := backup.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		// the resource is streamed into the file and hashed on the way
+		name := fmt.Sprintf("%02d-%s.yaml", i, backup.resource)
+		checksumWriter := pbackup.NewChecksumWriter()
+		written = append(written, filepath.Join(dir, name))
+		err = writeFile(filepath.Join(dir, name), recipient, func(w io.Writer) error {
+			_, err := io.Copy(io.MultiWriter(w, checksumWriter), backup.file)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		manifest.Files = append(manifest.Files, pbackup.ManifestFile{
+			Name:     name,
+			Resource: backup.resource,
+			Checksum: checksumWriter.Checksum(),
+		})
+	}
+
+	written = append(written, filepath.Join(dir, pbackup.ManifestFilename))
+	return writeFile(filepath.Join(dir, pbackup.ManifestFilename), recipient, func(w io.Writer) error {
+		return pbackup.Write(w, manifest)
+	})
+}
+
+// writeFile writes the file and encrypts it if a recipient is given. The file is only readable by
+// the current user, as backups contain secrets.
+func writeFile(filename string, recipient pbackup.Recipient, write func(w io.Writer) error) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if recipient == nil {
+		err = write(writer)
+	} else {
+		encryptWriter, err := pbackup.NewWriter(writer, recipient)
+		if err != nil {
+			return errors.Wrap(err, "encrypt backup")
+		}
+
+		err = write(encryptWriter)
+		if err != nil {
+			return err
+		}
+
+		err = encryptWriter.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// listLimit is the number of objects requested per list call
+const listLimit = 500
+
+// resourceBackup is a backed up resource, which is streamed into a temporary file
+type resourceBackup struct {
+	resource string
+	file     *os.File
+	objects  map[string]int
+}
+
+// remove closes and removes the temporary file of the resource
+func (b *resourceBackup) remove() {
+	_ = b.file.Close()
+	_ = os.Remove(b.file.Name())
+}
+
+// removeResourceBackups removes the temporary files of all resource backups that were written
+func removeResourceBackups(backups []*resourceBackup) {
+	for _, backup := range backups {
+		if backup != nil {
+			backup.remove()
+		}
+	}
+}
+
+// backupResources backs up all selected loft resources and the secrets they reference. Up to
+// --concurrency resources are backed up at the same time. Resources that fail or aren't served
+// are recorded in the manifest, in strict mode a failing resource fails the backup. Without a kube
+// client the management resources of the loft api are backed up instead.
+func (cmd *BackupCmd) backupResources(ctx context.Context, restConfig *rest.Config, kubeClient kubernetes.Interface, manifest *pbackup.Manifest, tempDir string) ([]*resourceBackup, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	gv := storagev1.SchemeGroupVersion
+	if kubeClient == nil {
+		gv = managementv1.SchemeGroupVersion
+	}
+	resourceList, err := discoveryClient.ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "discover loft resources")
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	served := resourceList.APIResources
+	if kubeClient == nil {
+		served = pbackup.ManagementResources(served)
+		cmd.Log.Warn("Secrets referenced by users and clusters, e.g. passwords, can't be read through the loft api and are not backed up")
+	}
+	resources, notServed := pbackup.SelectResources(served, cmd.Include, append(cmd.Exclude, cmd.Skip...))
+	for _, name := range notServed {
+		cmd.Log.Warnf("Skip %s, because the server doesn't serve it", name)
+	}
+	manifest.NotServed = notServed
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	backups := make([]*resourceBackup, len(resources))
+	errs := make([]error, len(resources))
+	indexes := make(chan int)
+	waitGroup := sync.WaitGroup{}
+	for i := 0; i < cmd.Concurrency || i == 0; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			for index := range indexes {
+				resource := resources[index]
+				cmd.Log.Infof("Backing up %s...", resource.Name)
+				backups[index], errs[index] = backupResourceToFile(ctx, dynamicClient, kubeClient, gv.WithResource(resource.Name), tempDir)
+				if errs[index] != nil && cmd.Strict {
+					cancel()
+				} else if errs[index] == nil {
+					cmd.Log.Donef("Backed up %s", resource.Name)
+				}
+			}
+		}()
+	}
+	for i := range resources {
+		indexes <- i
+	}
+	close(indexes)
+	waitGroup.Wait()
+
+	if cmd.Strict {
+		// resources that were canceled because of another one failing don't explain the failure
+		for i, resource := range resources {
+			if errs[i] != nil && !errors.Is(errs[i], context.Canceled) {
+				removeResourceBackups(backups)
+				return nil, errors.Wrapf(errs[i], "backup %s", resource.Name)
+			}
+		}
+	}
+
+	retBackups := []*resourceBackup{}
+	for i, resource := range resources {
+		if errs[i] != nil {
+			if cmd.Strict {
+				removeResourceBackups(backups)
+				return nil, errors.Wrapf(errs[i], "backup %s", resource.Name)
+			}
+
+			cmd.Log.Warn(errors.Wrapf(errs[i], "backup %s", resource.Name))
+			manifest.Failed[resource.Name] = errs[i].Error()
+			continue
+		}
+
+		for kind, count := range backups[i].objects {
+			manifest.Objects[kind] += count
+		}
+		retBackups = append(retBackups, backups[i])
+	}
+
+	return retBackups, nil
+}
+
+// backupResourceToFile streams the objects of the resource into a temporary file
+func backupResourceToFile(ctx context.Context, dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, resource schema.GroupVersionResource, tempDir string) (*resourceBackup, error) {
+	file, err := os.CreateTemp(tempDir, resource.Resource+"-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	writer := bufio.NewWriter(file)
+	documentWriter := pbackup.NewDocumentWriter(writer)
+	err = backupResource(ctx, dynamicClient, kubeClient, resource, documentWriter)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &resourceBackup{resource: resource.Resource, file: file, objects: documentWriter.Objects}, nil
+}
+
+// backupResource writes the objects of the resource. Project secrets and the secrets objects
+// reference are read with the kube client, unless the resource is served by the loft api.
+func backupResource(ctx context.Context, dynamicClient dynamic.Interface, kubeClient kubernetes.Interface, resource schema.GroupVersionResource, writer *pbackup.DocumentWriter) error {
+	if resource.GroupVersion() == storagev1.SchemeGroupVersion && resource.Resource == pbackup.ProjectSecrets {
+		return backupProjectSecrets(ctx, kubeClient, writer)
+	}
+
+	options := metav1.ListOptions{Limit: listLimit}
+	for {
+		list, err := dynamicClient.Resource(resource).List(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		for i := range list.Items {
+			u := &list.Items[i]
+			unstructured.RemoveNestedField(u.Object, "status")
+			err := resetMetadata(u)
+			if err != nil {
+				return err
+			}
+
+			err = writer.Write(u)
+			if err != nil {
+				return err
+			}
+
+			if kubeClient == nil {
+				continue
+			}
+
+			secrets, err := referencedSecrets(ctx, kubeClient, u)
+			if err != nil {
+				return err
+			}
+
+			for _, secret := range secrets {
+				err = writer.Write(secret)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if list.GetContinue() == "" {
+			return nil
+		}
+		options.Continue = list.GetContinue()
+	}
+}
+
+// secretReferences are the fields of loft resources that reference secrets by secretNamespace and
+// secretName, e.g. user passwords and cluster credentials
+var secretReferences = map[string][][]string{
+	"User":    {{"spec", "passwordRef"}, {"spec", "codesRef"}},
+	"Cluster": {{"spec", "config"}},
+}
+
+func referencedSecrets(ctx context.Context, kubeClient kubernetes.Interface, object *unstructured.Unstructured) ([]runtime.Object, error) {
+	retList := []runtime.Object{}
+	for _, path := range secretReferences[object.GetKind()] {
+		namespace, _, _ := unstructured.NestedString(object.Object, append(append([]string{}, path...), "secretNamespace")...)
+		name, _, _ := unstructured.NestedString(object.Object, append(append([]string{}, path...), "secretName")...)
+		secret, err := getSecret(ctx, kubeClient, namespace, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "get %s secret", strings.ToLower(object.GetKind()))
+		} else if secret != nil {
+			retList = append(retList, secret)
+		}
+	}
+
+	return retList, nil
+}
+
+func backupProjectSecrets(ctx context.Context, kubeClient kubernetes.Interface, writer *pbackup.DocumentWriter) error {
+	options := metav1.ListOptions{LabelSelector: pbackup.ProjectSecretLabel + "=true", Limit: listLimit}
+	for {
+		secretList, err := kubeClient.CoreV1().Secrets("").List(ctx, options)
+		if err != nil {
+			return err
+		}
+
+		for i := range secretList.Items {
+			u := &secretList.Items[i]
+			err := resetMetadata(u)
+			if err != nil {
+				return err
+			}
+
+			err = writer.Write(u)
+			if err != nil {
+				return err
+			}
+		}
+
+		if secretList.Continue == "" {
+			return nil
+		}
+		options.Continue = secretList.Continue
+	}
+}
+
+func getSecret(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) (*corev1.Secret, error) {
+	if namespace == "" || name == "" {
+		return nil, nil
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = resetMetadata(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "reset metadata secret")
+	}
+
+	return secret, nil
+}
+
+func resetMetadata(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	pbackup.StripMetadata(accessor)
+
+	gvk, err := GVKFrom(obj)
+	if err != nil {
+		return err
+	}
+
+	typeAccessor, err := meta.TypeAccessor(obj)
+	if err != nil {
+		return err
+	}
+
+	typeAccessor.SetKind(gvk.Kind)
+	typeAccessor.SetAPIVersion(gvk.GroupVersion().String())
+	return nil
+}
+
+func GVKFrom(obj runtime.Object) (schema.GroupVersionKind, error) {
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	} else if len(gvks) != 1 {
+		return schema.GroupVersionKind{}, fmt.Errorf("unexpected number of object kinds: %d", len(gvks))
+	}
+
+	return gvks[0], nil
+}
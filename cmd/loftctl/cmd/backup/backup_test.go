@@ -0,0 +1,328 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newResourceBackups writes every object to its own resource backup like backupResources does
+func newResourceBackups(t *testing.T, objects ...runtime.Object) []*resourceBackup {
+	backups := []*resourceBackup{}
+	for _, object := range objects {
+		file, err := os.CreateTemp(t.TempDir(), "resource-*.yaml")
+		assert.NilError(t, err)
+		t.Cleanup(func() { _ = file.Close() })
+
+		writer := pbackup.NewDocumentWriter(file)
+		assert.NilError(t, writer.Write(object))
+		backups = append(backups, &resourceBackup{resource: strings.ToLower(object.GetObjectKind().GroupVersionKind().Kind) + "s", file: file, objects: writer.Objects})
+	}
+
+	return backups
+}
+
+func TestWriteBackup(t *testing.T) {
+	t.Setenv(PassphraseEnv, "secret")
+	publicKey, secretKey, err := pbackup.GenerateKey()
+	assert.NilError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "backup.key")
+	assert.NilError(t, os.WriteFile(keyFile, []byte("# public key: "+publicKey+"\n"+secretKey+"\n"), 0600))
+
+	objects := []runtime.Object{
+		&storagev1.User{TypeMeta: metav1.TypeMeta{APIVersion: "storage.loft.sh/v1", Kind: "User"}, ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		&corev1.Secret{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}, ObjectMeta: metav1.ObjectMeta{Name: "loft-user-secret-admin", Namespace: "loft"}, Data: map[string][]byte{"password": []byte("hash")}},
+	}
+
+	testCases := []struct {
+		name       string
+		directory  bool
+		encryption EncryptionFlags
+		decryption DecryptionFlags
+	}{
+		{name: "plain"},
+		{name: "passphrase", encryption: EncryptionFlags{Encrypt: true}},
+		{name: "public key", encryption: EncryptionFlags{Recipient: publicKey}, decryption: DecryptionFlags{Identity: keyFile}},
+		{name: "plain directory", directory: true},
+		{name: "passphrase directory", directory: true, encryption: EncryptionFlags{Encrypt: true}},
+		{name: "public key directory", directory: true, encryption: EncryptionFlags{Recipient: publicKey}, decryption: DecryptionFlags{Identity: keyFile}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "backup.yaml")
+			recipient, err := testCase.encryption.recipient(log.Discard)
+			assert.NilError(t, err)
+			manifest := pbackup.NewManifest("v3.3.0", "v3.3.1")
+			manifest.NotServed = []string{"runners"}
+			manifest.Objects = map[string]int{"storage.loft.sh/v1/User": 1, "v1/Secret": 1}
+			if testCase.directory {
+				filename = filepath.Join(t.TempDir(), "backup")
+				assert.NilError(t, writeBackupDirectory(filename, manifest, newResourceBackups(t, objects...), recipient))
+				assert.DeepEqual(t, []string{manifest.Files[0].Name, manifest.Files[1].Name}, []string{"00-users.yaml", "01-secrets.yaml"})
+
+				stat, err := os.Stat(filepath.Join(filename, "01-secrets.yaml"))
+				assert.NilError(t, err)
+				assert.Equal(t, stat.Mode().Perm(), os.FileMode(0600))
+			} else {
+				assert.NilError(t, writeBackup(filename, manifest, newResourceBackups(t, objects...), recipient))
+
+				stat, err := os.Stat(filename)
+				assert.NilError(t, err)
+				assert.Equal(t, stat.Mode().Perm(), os.FileMode(0600))
+			}
+
+			readManifest, restored, err := readBackup(filename, &testCase.decryption, log.Discard)
+			assert.NilError(t, err)
+			assert.Equal(t, readManifest.ServerVersion, "v3.3.0")
+			assert.DeepEqual(t, readManifest.Objects, map[string]int{"storage.loft.sh/v1/User": 1, "v1/Secret": 1})
+			assert.DeepEqual(t, readManifest.NotServed, []string{"runners"})
+			assert.Equal(t, len(restored), 2)
+			assert.Equal(t, restored[0].GetName(), "admin")
+			assert.Equal(t, restored[1].GetKind(), "Secret")
+			assert.Equal(t, restored[1].GetNamespace(), "loft")
+		})
+	}
+}
+
+func TestReadModifiedBackup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backup.yaml")
+	objects := []runtime.Object{
+		&storagev1.User{TypeMeta: metav1.TypeMeta{APIVersion: "storage.loft.sh/v1", Kind: "User"}, ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+	}
+	manifest := pbackup.NewManifest("", "")
+	manifest.Objects = map[string]int{"storage.loft.sh/v1/User": 1}
+	assert.NilError(t, writeBackup(filename, manifest, newResourceBackups(t, objects...), nil))
+	content, err := os.ReadFile(filename)
+	assert.NilError(t, err)
+
+	modified := bytes.Replace(content, []byte("name: admin"), []byte("name: evil"), 1)
+	assert.NilError(t, os.WriteFile(filename, modified, 0600))
+	_, _, err = readBackup(filename, &DecryptionFlags{}, log.Discard)
+	assert.Assert(t, errors.Is(err, pbackup.ErrChecksumMismatch), "got %v", err)
+
+	// backups without manifest are still readable
+	_, body, _ := bytes.Cut(content, []byte(pbackup.Separator))
+	assert.NilError(t, os.WriteFile(filename, body, 0600))
+	readManifest, restored, err := readBackup(filename, &DecryptionFlags{}, log.Discard)
+	assert.NilError(t, err)
+	assert.Assert(t, readManifest == nil)
+	assert.Equal(t, len(restored), 1)
+
+	// files of a backup directory are verified against the manifest
+	dir := filepath.Join(t.TempDir(), "backup")
+	assert.NilError(t, writeBackupDirectory(dir, pbackup.NewManifest("", ""), newResourceBackups(t, objects...), nil))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "00-users.yaml"), modified[bytes.Index(modified, []byte(pbackup.Separator))+len(pbackup.Separator):], 0600))
+	_, _, err = readBackup(dir, &DecryptionFlags{}, log.Discard)
+	assert.Assert(t, errors.Is(err, pbackup.ErrChecksumMismatch), "got %v", err)
+}
+
+func TestWriteBackupDirectoryFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backup")
+	assert.NilError(t, os.MkdirAll(dir, 0700))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0600))
+	backups := newResourceBackups(t,
+		&storagev1.User{TypeMeta: metav1.TypeMeta{APIVersion: "storage.loft.sh/v1", Kind: "User"}, ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		&storagev1.Team{TypeMeta: metav1.TypeMeta{APIVersion: "storage.loft.sh/v1", Kind: "Team"}, ObjectMeta: metav1.ObjectMeta{Name: "team"}},
+	)
+
+	// the second resource can't be read anymore, so the first one is removed again
+	backups[1].remove()
+	assert.Assert(t, writeBackupDirectory(dir, pbackup.NewManifest("", ""), backups, nil) != nil)
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{filepath.Join(dir, "other.txt")})
+	_, err = os.Stat(backups[1].file.Name())
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestPassphraseAskedOnce(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "backup")
+	objects := []runtime.Object{
+		&storagev1.User{TypeMeta: metav1.TypeMeta{APIVersion: "storage.loft.sh/v1", Kind: "User"}, ObjectMeta: metav1.ObjectMeta{Name: "admin"}},
+		&storagev1.Team{TypeMeta: metav1.TypeMeta{APIVersion: "storage.loft.sh/v1", Kind: "Team"}, ObjectMeta: metav1.ObjectMeta{Name: "team"}},
+	}
+	manifest := pbackup.NewManifest("", "")
+	manifest.Objects = map[string]int{"storage.loft.sh/v1/User": 1, "storage.loft.sh/v1/Team": 1}
+	assert.NilError(t, writeBackupDirectory(dir, manifest, newResourceBackups(t, objects...), pbackup.PassphraseRecipient("secret")))
+
+	// the environment is only read the first time
+	t.Setenv(PassphraseEnv, "secret")
+	flags := &DecryptionFlags{}
+	identities, err := flags.identities(log.Discard)
+	assert.NilError(t, err)
+	file, err := os.Open(filepath.Join(dir, pbackup.ManifestFilename))
+	assert.NilError(t, err)
+	defer file.Close()
+	_, err = pbackup.NewReader(file, identities...)
+	assert.NilError(t, err)
+
+	t.Setenv(PassphraseEnv, "")
+	_, restored, err := readBackup(dir, flags, log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, len(restored), 2)
+}
+
+func TestBackupResource(t *testing.T) {
+	user := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "storage.loft.sh/v1",
+		"kind":       "User",
+		"metadata":   map[string]interface{}{"name": "admin", "resourceVersion": "12", "uid": "1234"},
+		"spec": map[string]interface{}{
+			"username":    "admin",
+			"passwordRef": map[string]interface{}{"secretNamespace": "loft", "secretName": "loft-user-secret-admin"},
+		},
+		"status": map[string]interface{}{"teams": []interface{}{"team"}},
+	}}
+	userGVR := storagev1.SchemeGroupVersion.WithResource("users")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{userGVR: "UserList"}, user)
+	kubeClient := fake.NewSimpleClientset(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "loft-user-secret-admin", Namespace: "loft", ResourceVersion: "3"}})
+
+	buffer := &bytes.Buffer{}
+	writer := pbackup.NewDocumentWriter(buffer)
+	assert.NilError(t, backupResource(context.Background(), dynamicClient, kubeClient, userGVR, writer))
+	assert.DeepEqual(t, writer.Objects, map[string]int{"storage.loft.sh/v1/User": 1, "v1/Secret": 1})
+
+	objects, err := pbackup.ReadObjects(buffer)
+	assert.NilError(t, err)
+	assert.Equal(t, len(objects), 2)
+
+	backedUpUser := objects[0]
+	assert.Equal(t, backedUpUser.GetResourceVersion(), "")
+	assert.Equal(t, string(backedUpUser.GetUID()), "")
+	_, found := backedUpUser.Object["status"]
+	assert.Assert(t, !found)
+
+	secret := objects[1]
+	assert.Equal(t, secret.GetName(), "loft-user-secret-admin")
+	assert.Equal(t, secret.GetResourceVersion(), "")
+	assert.Equal(t, secret.GetKind(), "Secret")
+}
+
+func TestBackupResourceViaLoft(t *testing.T) {
+	user := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "management.loft.sh/v1",
+		"kind":       "User",
+		"metadata":   map[string]interface{}{"name": "admin", "resourceVersion": "12"},
+		"spec": map[string]interface{}{
+			"username":    "admin",
+			"passwordRef": map[string]interface{}{"secretNamespace": "loft", "secretName": "loft-user-secret-admin"},
+		},
+	}}
+	userGVR := managementv1.SchemeGroupVersion.WithResource("users")
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{userGVR: "UserList"}, user)
+
+	// referenced secrets can't be read through the loft api
+	buffer := &bytes.Buffer{}
+	writer := pbackup.NewDocumentWriter(buffer)
+	assert.NilError(t, backupResource(context.Background(), dynamicClient, nil, userGVR, writer))
+	assert.DeepEqual(t, writer.Objects, map[string]int{"management.loft.sh/v1/User": 1})
+}
+
+func TestScheduleStatusAndDelete(t *testing.T) {
+	labels := map[string]string{pbackup.ScheduleLabel: "loft-backup"}
+	now := metav1.Now()
+	kubeClient := fake.NewSimpleClientset(
+		&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "loft-backup", Namespace: "loft", Labels: labels}, Spec: batchv1.CronJobSpec{Schedule: "0 3 * * *"}, Status: batchv1.CronJobStatus{LastScheduleTime: &now}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "loft-backup-1", Namespace: "loft", Labels: labels, CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))}, Status: batchv1.JobStatus{Succeeded: 1}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "loft-backup-2", Namespace: "loft", Labels: labels, CreationTimestamp: now}, Status: batchv1.JobStatus{Failed: 1}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "loft-backup", Namespace: "loft"}},
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "loft-loft-backup"}},
+	)
+
+	status := &ScheduleStatusCmd{Name: "loft-backup", Namespace: "loft", Log: log.Discard}
+	assert.NilError(t, status.Run(context.Background(), kubeClient))
+	missing := &ScheduleStatusCmd{Name: "other", Namespace: "loft", Log: log.Discard}
+	assert.ErrorContains(t, missing.Run(context.Background(), kubeClient), "no backup schedule other found")
+
+	deleteCmd := &ScheduleDeleteCmd{Name: "loft-backup", Namespace: "loft", Log: log.Discard}
+	assert.NilError(t, deleteCmd.Run(context.Background(), kubeClient))
+	_, err := kubeClient.BatchV1().CronJobs("loft").Get(context.Background(), "loft-backup", metav1.GetOptions{})
+	assert.Assert(t, kerrors.IsNotFound(err))
+	_, err = kubeClient.RbacV1().ClusterRoles().Get(context.Background(), "loft-loft-backup", metav1.GetOptions{})
+	assert.Assert(t, kerrors.IsNotFound(err))
+	jobs, err := kubeClient.BatchV1().Jobs("loft").List(context.Background(), metav1.ListOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(jobs.Items), 0)
+}
+
+func TestRedactSecrets(t *testing.T) {
+	objects := pbackup.ScheduleObjects(pbackup.ScheduleOptions{
+		Name:      "loft-backup",
+		Namespace: "loft",
+		Env:       map[string]string{"AWS_SECRET_ACCESS_KEY": "secret", PassphraseEnv: "passphrase"},
+	})
+	redacted := redactSecrets(objects)
+	assert.Equal(t, len(redacted), len(objects))
+
+	found := false
+	for i, object := range redacted {
+		secret, ok := object.(*corev1.Secret)
+		if !ok {
+			assert.Equal(t, object, objects[i])
+			continue
+		}
+
+		found = true
+		assert.DeepEqual(t, secret.StringData, map[string]string{"AWS_SECRET_ACCESS_KEY": redactedValue, PassphraseEnv: redactedValue})
+		assert.Equal(t, objects[i].(*corev1.Secret).StringData["AWS_SECRET_ACCESS_KEY"], "secret")
+	}
+	assert.Assert(t, found)
+}
+
+func TestScheduledBackupVersion(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "access")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	schedule := &ScheduleCmd{Name: "loft-backup", Namespace: "my-loft", Cron: "0 3 * * *", Strict: true, S3Flags: S3Flags{Target: "s3://backups/loft"}, Log: log.Discard}
+	options, err := schedule.options()
+	assert.NilError(t, err)
+
+	// the job has no loft config, only the kube client of its service account
+	globalFlags := &flags.GlobalFlags{Config: filepath.Join(t.TempDir(), "config.json")}
+	c := NewBackupCmd(globalFlags)
+	assert.NilError(t, c.ParseFlags(options.Args))
+	namespace, err := c.Flags().GetString("namespace")
+	assert.NilError(t, err)
+	strict, err := c.Flags().GetBool("strict")
+	assert.NilError(t, err)
+	cmd := &BackupCmd{GlobalFlags: globalFlags, Namespace: namespace, Strict: strict, Log: log.Discard}
+	assert.Assert(t, cmd.Strict)
+
+	kubeClient := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "loft", Namespace: "my-loft"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "manager", Image: "ghcr.io/loft-sh/loft:3.2.1"}},
+		}}},
+	})
+	version, err := cmd.serverVersion(context.Background(), kubeClient)
+	assert.NilError(t, err)
+	assert.Equal(t, version, "3.2.1")
+	_, err = os.Stat(globalFlags.Config)
+	assert.Assert(t, os.IsNotExist(err), "no loft config should be created")
+
+	_, err = cmd.serverVersion(context.Background(), fake.NewSimpleClientset())
+	assert.ErrorContains(t, err, "determine loft version")
+}
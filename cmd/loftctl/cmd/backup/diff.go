@@ -0,0 +1,203 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// DiffCmd holds the cmd flags
+type DiffCmd struct {
+	*flags.GlobalFlags
+	DecryptionFlags
+
+	Live        bool
+	ShowSecrets bool
+
+	Log log.Logger
+}
+
+// NewDiffCmd creates a new command
+func NewDiffCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &DiffCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################## loft backup diff ###################
+#######################################################
+Shows the objects that were added, removed or changed
+between two backups or between a backup and the
+objects in the cluster Loft runs in. Objects are
+matched by kind, namespace and name. The status and
+the metadata that isn't backed up are ignored.
+
+With --live only the kinds the backup contains are
+compared. Secret values are redacted unless
+--show-secrets is given.
+
+Example:
+loft backup diff backup-monday.yaml backup-tuesday.yaml
+loft backup diff backup.yaml --live
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "diff FROM [TO]",
+		Short: "Compare two backups or a backup with the live objects",
+		Long:  description,
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if cmd.Live && len(args) != 1 {
+				return fmt.Errorf("please specify only one backup to compare with the live objects")
+			} else if !cmd.Live && len(args) != 2 {
+				return fmt.Errorf("please specify two backups or use --live")
+			}
+
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+	}
+
+	c.Flags().BoolVar(&cmd.Live, "live", false, "If enabled, compares the backup with the objects in the cluster of the current kube context")
+	c.Flags().BoolVar(&cmd.ShowSecrets, "show-secrets", false, "If enabled, shows the values of secrets instead of redacting them")
+	c.Flags().StringVar(&cmd.Identity, "identity", "", "The secret key or a file containing the secret key to decrypt the backups with")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *DiffCmd) Run(ctx context.Context, args []string) error {
+	// the backups might be encrypted with different passphrases
+	fromFlags := DecryptionFlags{Identity: cmd.Identity}
+	_, from, err := readBackup(args[0], &fromFlags, cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	var to []*unstructured.Unstructured
+	if cmd.Live {
+		kubeConfig, _, err := kubeClient()
+		if err != nil {
+			return err
+		}
+
+		to, err = liveObjects(ctx, kubeConfig, from)
+		if err != nil {
+			return err
+		}
+	} else {
+		toFlags := DecryptionFlags{Identity: cmd.Identity}
+		_, to, err = readBackup(args[1], &toFlags, cmd.Log)
+		if err != nil {
+			return err
+		}
+	}
+
+	changes := pbackup.Diff(from, to)
+	counts := map[pbackup.ChangeType]int{}
+	for _, change := range changes {
+		counts[change.Type]++
+		switch change.Type {
+		case pbackup.Added:
+			cmd.Log.WriteString(logrus.InfoLevel, ansi.Color("+ "+change.Key.String(), "green")+"\n")
+		case pbackup.Removed:
+			cmd.Log.WriteString(logrus.InfoLevel, ansi.Color("- "+change.Key.String(), "red")+"\n")
+		case pbackup.Changed:
+			cmd.Log.WriteString(logrus.InfoLevel, ansi.Color("~ "+change.Key.String(), "yellow")+"\n")
+			for _, field := range change.Fields {
+				oldValue, newValue := field.Format(cmd.ShowSecrets)
+				cmd.Log.WriteString(logrus.InfoLevel, fmt.Sprintf("    %s: %s -> %s\n", field.Path, oldValue, newValue))
+			}
+		}
+	}
+
+	if len(changes) == 0 {
+		cmd.Log.Donef("No differences found")
+		return nil
+	}
+
+	cmd.Log.Infof("%d added, %d removed, %d changed", counts[pbackup.Added], counts[pbackup.Removed], counts[pbackup.Changed])
+	return nil
+}
+
+// liveObjects returns the objects of the kinds the backup contains. Secrets are only looked up by
+// the names in the backup and the project secret label, as a backup doesn't contain all secrets.
+func liveObjects(ctx context.Context, restConfig *rest.Config, objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	listed := map[schema.GroupVersionKind]bool{}
+	live := []*unstructured.Unstructured{}
+	for _, object := range objects {
+		gvk := object.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		resource := dynamicClient.Resource(mapping.Resource)
+		if gvk == secretGVK && object.GetLabels()[pbackup.ProjectSecretLabel] != "true" {
+			liveObject, err := resource.Namespace(object.GetNamespace()).Get(ctx, object.GetName(), metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			live = append(live, liveObject)
+			continue
+		}
+
+		if listed[gvk] {
+			continue
+		}
+		listed[gvk] = true
+
+		options := metav1.ListOptions{Limit: listLimit}
+		if gvk == secretGVK {
+			options.LabelSelector = pbackup.ProjectSecretLabel + "=true"
+		}
+		for {
+			list, err := resource.List(ctx, options)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range list.Items {
+				live = append(live, &list.Items[i])
+			}
+
+			if list.GetContinue() == "" {
+				break
+			}
+			options.Continue = list.GetContinue()
+		}
+	}
+
+	return live, nil
+}
@@ -0,0 +1,264 @@
+package backup
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/survey"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PassphraseEnv is the environment variable the passphrase of encrypted backups is read from
+const PassphraseEnv = "LOFT_BACKUP_PASSPHRASE"
+
+// EncryptionFlags holds the flags to encrypt backups
+type EncryptionFlags struct {
+	Encrypt   bool
+	Recipient string
+}
+
+// DecryptionFlags holds the flags to decrypt backups
+type DecryptionFlags struct {
+	Identity string
+
+	// passphrase is remembered, so it is only asked for once per backup directory
+	passphrase string
+}
+
+// recipient returns the recipient to encrypt the backup for or nil if it shouldn't be encrypted
+func (f *EncryptionFlags) recipient(log log.Logger) (pbackup.Recipient, error) {
+	if f.Recipient != "" {
+		publicKey, err := readKey(f.Recipient, pbackup.PublicKeyPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		return pbackup.ParseRecipient(publicKey)
+	} else if !f.Encrypt {
+		return nil, nil
+	}
+
+	passphrase, err := passphrase(log, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pbackup.PassphraseRecipient(passphrase), nil
+}
+
+// identities returns the identities an encrypted backup can be decrypted with. The passphrase is
+// only asked for if the backup was encrypted with one.
+func (f *DecryptionFlags) identities(log log.Logger) ([]pbackup.Identity, error) {
+	identities := []pbackup.Identity{
+		pbackup.PassphraseIdentity(func() (string, error) {
+			if f.passphrase != "" {
+				return f.passphrase, nil
+			}
+
+			passphrase, err := passphrase(log, false)
+			if err != nil {
+				return "", err
+			}
+
+			f.passphrase = passphrase
+			return passphrase, nil
+		}),
+	}
+	if f.Identity != "" {
+		secretKey, err := readKey(f.Identity, pbackup.SecretKeyPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		identity, err := pbackup.ParseIdentity(secretKey)
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, identity)
+	}
+
+	return identities, nil
+}
+
+// openBackup opens the backup and decrypts it if needed
+func openBackup(filename string, flags *DecryptionFlags, log log.Logger) (io.Reader, func() error, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(file)
+	if !pbackup.IsEncrypted(reader) {
+		return reader, file.Close, nil
+	}
+
+	identities, err := flags.identities(log)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, err
+	}
+
+	decrypted, err := pbackup.NewReader(reader, identities...)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, errors.Wrapf(err, "decrypt %s", filename)
+	}
+
+	return decrypted, file.Close, nil
+}
+
+// readBackup decrypts the backup, verifies it against its manifest and parses its objects. The
+// manifest is nil for backups created without one. Backups written as directory are read in the
+// order of the files their manifest lists.
+func readBackup(filename string, flags *DecryptionFlags, log log.Logger) (*pbackup.Manifest, []*unstructured.Unstructured, error) {
+	stat, err := os.Stat(filename)
+	if err != nil {
+		return nil, nil, err
+	} else if stat.IsDir() {
+		return readBackupDirectory(filename, flags, log)
+	}
+
+	reader, closeBackup, err := openBackup(filename, flags, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeBackup()
+
+	manifest, body, err := pbackup.Read(reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, filename)
+	}
+
+	objects, err := pbackup.ReadObjects(body)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, filename)
+	}
+
+	if manifest != nil {
+		err = manifest.Verify(pbackup.CountObjects(objects))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, filename)
+		}
+	}
+
+	return manifest, objects, nil
+}
+
+func readBackupDirectory(dir string, flags *DecryptionFlags, log log.Logger) (*pbackup.Manifest, []*unstructured.Unstructured, error) {
+	manifestFile := filepath.Join(dir, pbackup.ManifestFilename)
+	reader, closeManifest, err := openBackup(manifestFile, flags, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeManifest()
+
+	manifest, body, err := pbackup.Read(reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, manifestFile)
+	} else if manifest == nil {
+		return nil, nil, fmt.Errorf("%s is not a backup manifest", manifestFile)
+	}
+
+	_, err = io.Copy(io.Discard, body)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, manifestFile)
+	}
+
+	objects := []*unstructured.Unstructured{}
+	for _, file := range manifest.Files {
+		fileObjects, err := readBackupFile(filepath.Join(dir, file.Name), file.Checksum, flags, log)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		objects = append(objects, fileObjects...)
+	}
+
+	err = manifest.Verify(pbackup.CountObjects(objects))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, dir)
+	}
+
+	return manifest, objects, nil
+}
+
+// readBackupFile reads a file of a backup directory and verifies it against the checksum its
+// manifest lists
+func readBackupFile(filename, checksum string, flags *DecryptionFlags, log log.Logger) ([]*unstructured.Unstructured, error) {
+	reader, closeFile, err := openBackup(filename, flags, log)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile()
+
+	objects, err := pbackup.ReadObjects(pbackup.NewVerifyingReader(reader, checksum))
+	if err != nil {
+		return nil, errors.Wrap(err, filename)
+	}
+
+	return objects, nil
+}
+
+// passphrase reads the passphrase from the environment or asks for it
+func passphrase(log log.Logger, confirm bool) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	for {
+		passphrase, err := log.Question(&survey.QuestionOptions{
+			Question:   "Please enter the backup passphrase",
+			IsPassword: true,
+		})
+		if err != nil {
+			return "", err
+		} else if passphrase == "" {
+			log.Error("Please enter a passphrase")
+			continue
+		} else if !confirm {
+			return passphrase, nil
+		}
+
+		confirmation, err := log.Question(&survey.QuestionOptions{
+			Question:   "Please repeat the backup passphrase",
+			IsPassword: true,
+		})
+		if err != nil {
+			return "", err
+		} else if confirmation != passphrase {
+			log.Error("Passphrases don't match")
+			continue
+		}
+
+		return passphrase, nil
+	}
+}
+
+// readKey returns the key itself or reads it from the file it points to
+func readKey(keyOrFile, prefix string) (string, error) {
+	if strings.HasPrefix(keyOrFile, prefix) {
+		return keyOrFile, nil
+	}
+
+	out, err := os.ReadFile(keyOrFile)
+	if err != nil {
+		return "", fmt.Errorf("%s is neither a key starting with %s nor a readable file: %w", keyOrFile, prefix, err)
+	}
+
+	// key files might contain comments
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			return strings.TrimSpace(line), nil
+		}
+	}
+
+	return "", fmt.Errorf("no key starting with %s found in %s", prefix, keyOrFile)
+}
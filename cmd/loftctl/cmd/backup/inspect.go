@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// InspectCmd holds the cmd flags
+type InspectCmd struct {
+	*flags.GlobalFlags
+	DecryptionFlags
+
+	Log log.Logger
+}
+
+// NewInspectCmd creates a new command
+func NewInspectCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &InspectCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft backup inspect #################
+#######################################################
+Decrypts the backup if needed, verifies it against its
+manifest and shows where it comes from and how many
+objects of each kind it contains. Fails if the backup
+was modified.
+
+Example:
+loft backup inspect backup.yaml
+loft backup inspect backup.yaml --identity backup.key
+loft backup inspect backup/
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "inspect FILE|DIRECTORY",
+		Short: "Summarize a loft management plane backup",
+		Long:  description,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(args[0])
+		},
+	}
+
+	c.Flags().StringVar(&cmd.Identity, "identity", "", "The secret key or a file containing the secret key to decrypt the backup with")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *InspectCmd) Run(filename string) error {
+	encryption, err := encryptionMode(filename)
+	if err != nil {
+		return err
+	}
+
+	manifest, objects, err := readBackup(filename, &cmd.DecryptionFlags, cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	values := [][]string{}
+	for kind, count := range pbackup.CountObjects(objects) {
+		values = append(values, []string{kind, fmt.Sprint(count)})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i][0] < values[j][0]
+	})
+
+	table.PrintTable(cmd.Log, []string{"Kind", "Objects"}, values)
+	cmd.Log.Infof("Encryption: %s", ansi.Color(encryption, "white+b"))
+	if manifest == nil {
+		cmd.Log.Warnf("Backup %s has no manifest, so its content can't be verified", filename)
+		cmd.Log.Donef("Backup %s contains %s objects", filename, ansi.Color(fmt.Sprint(len(objects)), "white+b"))
+		return nil
+	}
+
+	cmd.Log.Infof("Created: %s", ansi.Color(manifest.CreationTimestamp.Local().Format(time.RFC3339), "white+b"))
+	cmd.Log.Infof("Loft version: %s", ansi.Color(valueOrUnknown(manifest.ServerVersion), "white+b"))
+	cmd.Log.Infof("CLI version: %s", ansi.Color(valueOrUnknown(manifest.CLIVersion), "white+b"))
+	cmd.Log.Infof("Checksum: %s", ansi.Color(manifest.Checksum, "white+b"))
+	if manifest.ViaLoft {
+		cmd.Log.Warn("Backup was created through the loft api and doesn't contain the passwords of users and the configs of clusters")
+	}
+	if len(manifest.NotServed) > 0 {
+		cmd.Log.Warnf("Resources not served by the server: %s", strings.Join(manifest.NotServed, ", "))
+	}
+	failed := []string{}
+	for resource := range manifest.Failed {
+		failed = append(failed, resource)
+	}
+	sort.Strings(failed)
+	for _, resource := range failed {
+		cmd.Log.Warnf("Resource %s failed: %s", resource, manifest.Failed[resource])
+	}
+
+	if !manifest.Complete() {
+		cmd.Log.Warnf("Backup %s is incomplete, it contains %s verified objects", filename, ansi.Color(fmt.Sprint(len(objects)), "white+b"))
+		return nil
+	}
+
+	cmd.Log.Donef("Backup %s is complete and contains %s verified objects", filename, ansi.Color(fmt.Sprint(len(objects)), "white+b"))
+	return nil
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
+
+// encryptionMode returns how the backup is encrypted. Backups written as directory are encrypted
+// like their manifest.
+func encryptionMode(filename string) (string, error) {
+	if stat, err := os.Stat(filename); err == nil && stat.IsDir() {
+		filename = filepath.Join(filename, pbackup.ManifestFilename)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	if !pbackup.IsEncrypted(reader) {
+		return "none", nil
+	}
+
+	mode, err := pbackup.Mode(reader)
+	if err != nil {
+		return "", err
+	} else if mode == pbackup.ModeX25519 {
+		return "public key", nil
+	}
+
+	return mode, nil
+}
@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// KeygenCmd holds the cmd flags
+type KeygenCmd struct {
+	*flags.GlobalFlags
+
+	Output string
+
+	Log log.Logger
+}
+
+// NewKeygenCmd creates a new command
+func NewKeygenCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &KeygenCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft backup keygen ##################
+#######################################################
+Creates a key pair to encrypt backups with. The secret
+key is written to the output file, the public key is
+printed and can be used with loft backup --recipient
+without being able to decrypt the backups.
+
+Example:
+loft backup keygen --output backup.key
+loft backup --recipient loft-backup-pk-...
+loft backup restore backup.yaml --identity backup.key
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create a key pair to encrypt backups with",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+	}
+
+	c.Flags().StringVar(&cmd.Output, "output", "backup.key", "The file to write the secret key to")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *KeygenCmd) Run() error {
+	publicKey, secretKey, err := pbackup.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	// never overwrite an existing key, backups encrypted for it couldn't be restored anymore
+	file, err := os.OpenFile(cmd.Output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "# public key: %s\n%s\n", publicKey, secretKey)
+	if err != nil {
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	cmd.Log.Donef("Wrote secret key to %s, public key: %s", cmd.Output, ansi.Color(publicKey, "white+b"))
+	return nil
+}
@@ -0,0 +1,302 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/loftctl/v3/pkg/clihelper"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/survey"
+	"github.com/loft-sh/log/table"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RestoreCmd holds the cmd flags
+type RestoreCmd struct {
+	*flags.GlobalFlags
+	DecryptionFlags
+	S3Flags
+
+	Namespace string
+	ViaLoft   bool
+	List      bool
+
+	Log log.Logger
+}
+
+// NewRestoreCmd creates a new command
+func NewRestoreCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &RestoreCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft backup restore #################
+#######################################################
+Restore creates or updates the objects of a backup in
+the Loft management plane. Encrypted backups are
+decrypted with the passphrase from
+LOFT_BACKUP_PASSPHRASE, the prompt or --identity.
+
+Use --target to restore the newest or the named
+backup uploaded to S3 compatible object storage and
+--list to list the backups there.
+
+Use --via-loft to restore through the Loft api as the
+logged in admin instead of the current kube context.
+
+Example:
+loft backup restore backup.yaml
+loft backup restore backup.yaml --identity backup.key
+loft backup restore backup/
+loft backup restore backup.yaml --via-loft
+loft backup restore --target s3://backups/loft --list
+loft backup restore --target s3://backups/loft
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "restore [FILE|DIRECTORY|NAME]",
+		Short: "Restore a loft management plane backup",
+		Long:  description,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			if cmd.Target != "" {
+				return cmd.RunTarget(cobraCmd.Context(), args)
+			} else if len(args) != 1 {
+				return fmt.Errorf("please specify the backup to restore")
+			}
+
+			return cmd.Run(cobraCmd.Context(), args[0])
+		},
+	}
+
+	c.Flags().StringVar(&cmd.Namespace, "namespace", "loft", "The namespace to loft was installed into")
+	c.Flags().BoolVar(&cmd.ViaLoft, "via-loft", false, "If enabled, restores the backup through the loft api as the logged in user instead of the current kube context. Secrets other than project secrets are skipped")
+	c.Flags().StringVar(&cmd.Identity, "identity", "", "The secret key or a file containing the secret key to decrypt the backup with")
+	addS3Flags(c, &cmd.S3Flags, "If set, restores the backup with the given name or the newest backup from this S3 compatible object storage target in the form s3://bucket/prefix")
+	c.Flags().BoolVar(&cmd.List, "list", false, "If enabled, lists the backups in --target instead of restoring one")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *RestoreCmd) Run(ctx context.Context, filename string) error {
+	// read the backup first, so a wrong key or a modified backup doesn't leave a partial restore behind
+	manifest, objects, err := readBackup(filename, &cmd.DecryptionFlags, cmd.Log)
+	if err != nil {
+		return err
+	} else if manifest == nil {
+		cmd.Log.Warnf("Backup %s has no manifest, so it can't be verified", filename)
+	} else if !manifest.Complete() {
+		cmd.Log.Warnf("Backup %s is incomplete, run loft backup inspect %s for details", filename, filename)
+	}
+
+	if manifest != nil && manifest.ViaLoft {
+		cmd.Log.Warnf("Backup %s was created through the loft api, so it doesn't contain the passwords of users and the configs of clusters", filename)
+	}
+
+	restConfig, objects, err := cmd.restConfig(objects)
+	if err != nil {
+		return err
+	}
+
+	failed := restoreObjects(ctx, restConfig, objects, cmd.Log)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d objects couldn't be restored", failed, len(objects))
+	}
+
+	cmd.Log.Donef("Restored %s objects from %s", ansi.Color(fmt.Sprint(len(objects)), "white+b"), filename)
+	return nil
+}
+
+// RunTarget lists the backups of the target or restores the named or newest one
+func (cmd *RestoreCmd) RunTarget(ctx context.Context, args []string) error {
+	s3Client, s3Target, err := cmd.S3Flags.client()
+	if err != nil {
+		return err
+	}
+
+	if cmd.List {
+		objects, err := s3Client.List(ctx, s3Target.Bucket, s3Target.Key(""))
+		if err != nil {
+			return err
+		}
+
+		values := [][]string{}
+		for _, backup := range s3Target.Backups(objects) {
+			values = append(values, []string{
+				strings.TrimPrefix(backup.Key, s3Target.Key("")),
+				fmt.Sprint(backup.Size),
+				duration.HumanDuration(time.Since(backup.LastModified)),
+			})
+		}
+
+		table.PrintTable(cmd.Log, []string{"Name", "Bytes", "Age"}, values)
+		return nil
+	}
+
+	name := ""
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	downloadDir, err := os.MkdirTemp("", "loft-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(downloadDir)
+
+	filename := filepath.Join(downloadDir, "backup.yaml")
+	url, err := download(ctx, s3Client, s3Target, name, filename)
+	if err != nil {
+		return err
+	}
+
+	cmd.Log.Infof("Downloaded backup %s", url)
+	return cmd.Run(ctx, filename)
+}
+
+// restConfig returns the config to restore the objects with and converts the objects to the
+// resources it serves. Secrets other than project secrets can't be restored through the loft
+// api, so they are skipped.
+func (cmd *RestoreCmd) restConfig(objects []*unstructured.Unstructured) (*rest.Config, []*unstructured.Unstructured, error) {
+	converted := []*unstructured.Unstructured{}
+	if cmd.ViaLoft {
+		restConfig, err := managementConfig(cmd.Config)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, object := range objects {
+			managementObject, ok := pbackup.ToManagement(object)
+			if !ok {
+				cmd.Log.Warnf("Skip %s %s, because it can't be restored through the loft api", object.GetKind(), objectName(object))
+				continue
+			}
+
+			converted = append(converted, managementObject)
+		}
+
+		return restConfig, converted, nil
+	}
+
+	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	isInstalled, err := clihelper.IsLoftAlreadyInstalled(kubeClient, cmd.Namespace)
+	if err != nil {
+		return nil, nil, err
+	} else if !isInstalled {
+		answer, err := cmd.Log.Question(&survey.QuestionOptions{
+			Question:     fmt.Sprintf("Seems like Loft was not installed into namespace %s, do you want to continue?", cmd.Namespace),
+			DefaultValue: "Yes",
+			Options:      []string{"Yes", "No"},
+		})
+		if err != nil {
+			return nil, nil, err
+		} else if answer != "Yes" {
+			return nil, nil, errors.New("restore canceled")
+		}
+	}
+
+	for _, object := range objects {
+		converted = append(converted, pbackup.ToStorage(object))
+	}
+
+	return kubeConfig, converted, nil
+}
+
+// restoreObjects creates or updates the objects in order and returns how many failed. Later
+// objects are still restored if one fails, as they rarely depend on each other.
+func restoreObjects(ctx context.Context, restConfig *rest.Config, objects []*unstructured.Unstructured, log log.Logger) int {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		log.Error(errors.Wrap(err, "create discovery client"))
+		return len(objects)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		log.Error(errors.Wrap(err, "create dynamic client"))
+		return len(objects)
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	failed := 0
+	for _, object := range objects {
+		err := restoreObject(ctx, dynamicClient, mapper, object)
+		if err != nil {
+			log.Warnf("Error restoring %s %s: %v", object.GetKind(), objectName(object), err)
+			failed++
+			continue
+		}
+
+		log.Debugf("Restored %s %s", object.GetKind(), objectName(object))
+	}
+
+	return failed
+}
+
+func restoreObject(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, object *unstructured.Unstructured) error {
+	gvk := object.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+
+	var resource dynamic.ResourceInterface = dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		resource = dynamicClient.Resource(mapping.Resource).Namespace(object.GetNamespace())
+	}
+
+	_, err = resource.Create(ctx, object, metav1.CreateOptions{})
+	if !kerrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, err := resource.Get(ctx, object.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	object.SetResourceVersion(existing.GetResourceVersion())
+	_, err = resource.Update(ctx, object, metav1.UpdateOptions{})
+	return err
+}
+
+func objectName(object *unstructured.Unstructured) string {
+	if object.GetNamespace() != "" {
+		return object.GetNamespace() + "/" + object.GetName()
+	}
+
+	return object.GetName()
+}
@@ -0,0 +1,137 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// S3Flags holds the flags to store backups in S3 compatible object storage
+type S3Flags struct {
+	Target          string
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+func addS3Flags(c *cobra.Command, f *S3Flags, targetUsage string) {
+	c.Flags().StringVar(&f.Target, "target", "", targetUsage)
+	c.Flags().StringVar(&f.Endpoint, "s3-endpoint", "", "The endpoint of the S3 compatible object storage, e.g. http://localhost:9000 for MinIO. Defaults to AWS_ENDPOINT_URL or AWS S3")
+	c.Flags().StringVar(&f.Region, "s3-region", "", "The region of the bucket. Defaults to AWS_REGION, AWS_DEFAULT_REGION or "+pbackup.DefaultS3Region)
+	c.Flags().StringVar(&f.AccessKeyID, "s3-access-key-id", "", "The access key id to use. Defaults to AWS_ACCESS_KEY_ID")
+	c.Flags().StringVar(&f.SecretAccessKey, "s3-secret-access-key", "", "The secret access key to use. Defaults to AWS_SECRET_ACCESS_KEY")
+}
+
+// client returns the client and the target of the backups. Flags take precedence over the
+// environment.
+func (f *S3Flags) client() (*pbackup.S3Client, *pbackup.S3Target, error) {
+	target, err := pbackup.ParseS3Target(f.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := pbackup.NewS3ClientFromEnv()
+	if f.Endpoint != "" {
+		client.Endpoint = f.Endpoint
+	}
+	if f.Region != "" {
+		client.Region = f.Region
+	}
+	if f.AccessKeyID != "" {
+		client.AccessKeyID = f.AccessKeyID
+		client.SessionToken = ""
+	}
+	if f.SecretAccessKey != "" {
+		client.SecretAccessKey = f.SecretAccessKey
+	}
+	if client.AccessKeyID == "" || client.SecretAccessKey == "" {
+		return nil, nil, fmt.Errorf("no credentials for %s found, please set --s3-access-key-id and --s3-secret-access-key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", f.Target)
+	}
+
+	return client, target, nil
+}
+
+// upload uploads the backup file to the target
+func upload(ctx context.Context, client *pbackup.S3Client, target *pbackup.S3Target, filename, key string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	return client.Put(ctx, target.Bucket, key, file, stat.Size())
+}
+
+// prune removes all but the newest keep backups of the target. If keep is 0, no backups are
+// removed.
+func prune(ctx context.Context, client *pbackup.S3Client, target *pbackup.S3Target, keep int, log log.Logger) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	objects, err := client.List(ctx, target.Bucket, target.Key(""))
+	if err != nil {
+		return errors.Wrap(err, "prune backups")
+	}
+
+	backups := target.Backups(objects)
+	for i := keep; i < len(backups); i++ {
+		err = client.Delete(ctx, target.Bucket, backups[i].Key)
+		if err != nil {
+			return errors.Wrap(err, "prune backups")
+		}
+
+		log.Infof("Removed old backup %s", target.URL(backups[i].Key))
+	}
+
+	return nil
+}
+
+// download downloads the backup with the name from the target into the file. If name is empty,
+// the newest backup is downloaded.
+func download(ctx context.Context, client *pbackup.S3Client, target *pbackup.S3Target, name, filename string) (string, error) {
+	key := target.Key(name)
+	if name == "" {
+		objects, err := client.List(ctx, target.Bucket, target.Key(""))
+		if err != nil {
+			return "", err
+		}
+
+		backups := target.Backups(objects)
+		if len(backups) == 0 {
+			return "", fmt.Errorf("no backups found in %s", target)
+		}
+		key = backups[0].Key
+	}
+
+	reader, err := client.Get(ctx, target.Bucket, key)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		return "", errors.Wrapf(err, "download %s", key)
+	}
+
+	return target.URL(key), file.Close()
+}
@@ -0,0 +1,264 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ScheduleCmd holds the cmd flags
+type ScheduleCmd struct {
+	*flags.GlobalFlags
+	EncryptionFlags
+	S3Flags
+
+	Name        string
+	Namespace   string
+	Cron        string
+	Image       string
+	Keep        int
+	Include     []string
+	Exclude     []string
+	Strict      bool
+	Print       bool
+	ShowSecrets bool
+
+	Log log.Logger
+}
+
+// NewScheduleCmd creates a new command
+func NewScheduleCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ScheduleCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################ loft backup schedule #################
+#######################################################
+Schedule creates a CronJob in the cluster Loft runs in
+that periodically uploads a backup to the --target.
+The job runs as a ServiceAccount that can only read
+the backed up resources and secrets. The storage
+credentials and the passphrase of --encrypt are
+stored in a Secret next to the CronJob.
+
+Use --print to show the objects instead of creating
+them. The values of the Secret are redacted, unless
+--show-secrets is set. Use loft backup schedule
+status to show the last
+runs and loft backup schedule delete to remove the
+schedule again.
+
+Example:
+loft backup schedule --target s3://backups/loft --keep 7
+loft backup schedule --cron "0 3 * * *" --target s3://backups/loft --recipient backup.key --print
+loft backup schedule status
+loft backup schedule delete
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "schedule",
+		Short: "Run loft management plane backups periodically",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context())
+		},
+	}
+
+	image := pbackup.DefaultScheduleImage + ":latest"
+	if version := upgrade.GetVersion(); version != "" {
+		image = pbackup.DefaultScheduleImage + ":" + version
+	}
+
+	c.Flags().StringVar(&cmd.Name, "name", "loft-backup", "The name of the schedule")
+	c.Flags().StringVar(&cmd.Namespace, "namespace", "loft", "The namespace to loft was installed into")
+	c.Flags().StringVar(&cmd.Cron, "cron", "0 3 * * *", "When to run the backup in cron format")
+	c.Flags().StringVar(&cmd.Image, "image", image, "The image with the loft cli to run the backup with")
+	addS3Flags(c, &cmd.S3Flags, "The S3 compatible object storage target in the form s3://bucket/prefix to upload the backups to")
+	c.Flags().IntVar(&cmd.Keep, "keep", 0, "If set, removes all but the newest backups in --target after a complete backup. By default no backups are removed")
+	c.Flags().StringSliceVar(&cmd.Include, "include", []string{}, "The only resources the backups should contain")
+	c.Flags().StringSliceVar(&cmd.Exclude, "exclude", []string{}, "What resources the backups should skip")
+	c.Flags().BoolVar(&cmd.Strict, "strict", false, "If enabled, a backup fails instead of uploading an incomplete backup")
+	c.Flags().BoolVar(&cmd.Encrypt, "encrypt", false, "If enabled, encrypts the backups with a passphrase from "+PassphraseEnv+" or the prompt, which is stored in the secret of the schedule")
+	c.Flags().StringVar(&cmd.Recipient, "recipient", "", "The public key or a file containing the public key to encrypt the backups for")
+	c.Flags().BoolVar(&cmd.Print, "print", false, "If enabled, prints the objects of the schedule instead of creating them")
+	c.Flags().BoolVar(&cmd.ShowSecrets, "show-secrets", false, "If enabled, --print shows the storage credentials and the passphrase instead of redacting them")
+	_ = c.MarkFlagRequired("target")
+
+	c.AddCommand(NewScheduleStatusCmd(globalFlags))
+	c.AddCommand(NewScheduleDeleteCmd(globalFlags))
+	return c
+}
+
+// Run executes the functionality
+func (cmd *ScheduleCmd) Run(ctx context.Context) error {
+	options, err := cmd.options()
+	if err != nil {
+		return err
+	}
+
+	objects := pbackup.ScheduleObjects(*options)
+	if cmd.Print {
+		if !cmd.ShowSecrets {
+			objects = redactSecrets(objects)
+		}
+
+		writer := pbackup.NewDocumentWriter(os.Stdout)
+		for _, object := range objects {
+			err = writer.Write(object)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = os.Stdout.Write([]byte("\n"))
+		return err
+	}
+
+	kubeConfig, _, err := kubeClient()
+	if err != nil {
+		return err
+	}
+
+	unstructuredObjects := []*unstructured.Unstructured{}
+	for _, object := range objects {
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return err
+		}
+
+		unstructuredObjects = append(unstructuredObjects, &unstructured.Unstructured{Object: raw})
+	}
+
+	failed := restoreObjects(ctx, kubeConfig, unstructuredObjects, cmd.Log)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d objects of the schedule couldn't be created", failed, len(objects))
+	}
+
+	cmd.Log.Donef("Scheduled backup %s to %s at %s", ansi.Color(cmd.Name, "white+b"), cmd.Target, ansi.Color(cmd.Cron, "white+b"))
+	return nil
+}
+
+// redactedValue replaces the values of secrets in printed objects
+const redactedValue = "REDACTED"
+
+// redactSecrets returns the objects with the values of secrets replaced, so credentials don't end
+// up in terminals or logs
+func redactSecrets(objects []runtime.Object) []runtime.Object {
+	redacted := []runtime.Object{}
+	for _, object := range objects {
+		if secret, ok := object.(*corev1.Secret); ok {
+			secret = secret.DeepCopy()
+			for key := range secret.StringData {
+				secret.StringData[key] = redactedValue
+			}
+			for key := range secret.Data {
+				secret.Data[key] = []byte(redactedValue)
+			}
+			object = secret
+		}
+
+		redacted = append(redacted, object)
+	}
+
+	return redacted
+}
+
+// options returns the schedule for the flags. Keys are passed to the job directly, as key files
+// don't exist in the cluster.
+func (cmd *ScheduleCmd) options() (*pbackup.ScheduleOptions, error) {
+	if len(strings.Fields(cmd.Cron)) != 5 && !strings.HasPrefix(cmd.Cron, "@") {
+		return nil, fmt.Errorf("--cron %q is not in cron format, e.g. \"0 3 * * *\"", cmd.Cron)
+	}
+
+	s3Client, _, err := cmd.S3Flags.client()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"--namespace=" + cmd.Namespace, "--target=" + cmd.Target}
+	if s3Client.Endpoint != "" {
+		args = append(args, "--s3-endpoint="+s3Client.Endpoint)
+	}
+	if s3Client.Region != "" {
+		args = append(args, "--s3-region="+s3Client.Region)
+	}
+	if cmd.Keep > 0 {
+		args = append(args, fmt.Sprintf("--keep=%d", cmd.Keep))
+	}
+	if len(cmd.Include) > 0 {
+		args = append(args, "--include="+strings.Join(cmd.Include, ","))
+	}
+	if len(cmd.Exclude) > 0 {
+		args = append(args, "--exclude="+strings.Join(cmd.Exclude, ","))
+	}
+	if cmd.Strict {
+		args = append(args, "--strict")
+	}
+
+	env := map[string]string{
+		"AWS_ACCESS_KEY_ID":     s3Client.AccessKeyID,
+		"AWS_SECRET_ACCESS_KEY": s3Client.SecretAccessKey,
+	}
+	if s3Client.SessionToken != "" {
+		env["AWS_SESSION_TOKEN"] = s3Client.SessionToken
+	}
+	if cmd.Recipient != "" {
+		publicKey, err := readKey(cmd.Recipient, pbackup.PublicKeyPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, "--recipient="+publicKey)
+	} else if cmd.Encrypt {
+		passphrase, err := passphrase(cmd.Log, true)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, "--encrypt")
+		env[PassphraseEnv] = passphrase
+	}
+
+	return &pbackup.ScheduleOptions{
+		Name:      cmd.Name,
+		Namespace: cmd.Namespace,
+		Cron:      cmd.Cron,
+		Image:     cmd.Image,
+		Args:      args,
+		Env:       env,
+	}, nil
+}
+
+// kubeClient loads the current kube context, which points to the cluster loft runs in
+func kubeClient() (*rest.Config, kubernetes.Interface, error) {
+	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	return kubeConfig, kubeClient, nil
+}
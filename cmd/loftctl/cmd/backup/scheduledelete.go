@@ -0,0 +1,123 @@
+package backup
+
+import (
+	"context"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ScheduleDeleteCmd holds the cmd flags
+type ScheduleDeleteCmd struct {
+	*flags.GlobalFlags
+
+	Name      string
+	Namespace string
+
+	Log log.Logger
+}
+
+// NewScheduleDeleteCmd creates a new command
+func NewScheduleDeleteCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ScheduleDeleteCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+############ loft backup schedule delete ##############
+#######################################################
+Deletes a backup schedule with its jobs, secret and
+permissions. Uploaded backups are kept.
+
+Example:
+loft backup schedule delete
+loft backup schedule delete --name loft-backup --namespace loft
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a backup schedule",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			_, kubeClient, err := kubeClient()
+			if err != nil {
+				return err
+			}
+
+			return cmd.Run(cobraCmd.Context(), kubeClient)
+		},
+	}
+
+	c.Flags().StringVar(&cmd.Name, "name", "loft-backup", "The name of the schedule")
+	c.Flags().StringVar(&cmd.Namespace, "namespace", "loft", "The namespace to loft was installed into")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *ScheduleDeleteCmd) Run(ctx context.Context, kubeClient kubernetes.Interface) error {
+	options := pbackup.ScheduleOptions{Name: cmd.Name, Namespace: cmd.Namespace}
+	propagation := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{PropagationPolicy: &propagation}
+
+	// the cron job is deleted first, so it doesn't start another job in between
+	found := false
+	for _, deleteObject := range []func() error{
+		func() error {
+			return kubeClient.BatchV1().CronJobs(cmd.Namespace).Delete(ctx, cmd.Name, deleteOptions)
+		},
+		func() error {
+			return kubeClient.CoreV1().Secrets(cmd.Namespace).Delete(ctx, cmd.Name, deleteOptions)
+		},
+		func() error {
+			return kubeClient.RbacV1().RoleBindings(cmd.Namespace).Delete(ctx, cmd.Name, deleteOptions)
+		},
+		func() error {
+			return kubeClient.RbacV1().Roles(cmd.Namespace).Delete(ctx, cmd.Name, deleteOptions)
+		},
+		func() error {
+			return kubeClient.RbacV1().ClusterRoleBindings().Delete(ctx, options.ClusterName(), deleteOptions)
+		},
+		func() error {
+			return kubeClient.RbacV1().ClusterRoles().Delete(ctx, options.ClusterName(), deleteOptions)
+		},
+		func() error {
+			return kubeClient.CoreV1().ServiceAccounts(cmd.Namespace).Delete(ctx, cmd.Name, deleteOptions)
+		},
+	} {
+		err := deleteObject()
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+
+		found = found || err == nil
+	}
+
+	// jobs that are not owned by the cron job anymore aren't deleted by the garbage collector
+	jobList, err := kubeClient.BatchV1().Jobs(cmd.Namespace).List(ctx, metav1.ListOptions{LabelSelector: pbackup.ScheduleLabel + "=" + cmd.Name})
+	if err != nil {
+		return err
+	}
+	for _, job := range jobList.Items {
+		err = kubeClient.BatchV1().Jobs(cmd.Namespace).Delete(ctx, job.Name, deleteOptions)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if !found {
+		cmd.Log.Warnf("No backup schedule %s found in namespace %s", cmd.Name, cmd.Namespace)
+		return nil
+	}
+
+	cmd.Log.Donef("Deleted backup schedule %s", cmd.Name)
+	return nil
+}
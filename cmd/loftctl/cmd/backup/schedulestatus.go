@@ -0,0 +1,131 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pbackup "github.com/loft-sh/loftctl/v3/pkg/backup"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ScheduleStatusCmd holds the cmd flags
+type ScheduleStatusCmd struct {
+	*flags.GlobalFlags
+
+	Name      string
+	Namespace string
+
+	Log log.Logger
+}
+
+// NewScheduleStatusCmd creates a new command
+func NewScheduleStatusCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ScheduleStatusCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+############ loft backup schedule status ##############
+#######################################################
+Shows when the scheduled backup ran last and the
+results of its recent runs.
+
+Example:
+loft backup schedule status
+loft backup schedule status --name loft-backup --namespace loft
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "status",
+		Short: "Show the last runs of a backup schedule",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			_, kubeClient, err := kubeClient()
+			if err != nil {
+				return err
+			}
+
+			return cmd.Run(cobraCmd.Context(), kubeClient)
+		},
+	}
+
+	c.Flags().StringVar(&cmd.Name, "name", "loft-backup", "The name of the schedule")
+	c.Flags().StringVar(&cmd.Namespace, "namespace", "loft", "The namespace to loft was installed into")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *ScheduleStatusCmd) Run(ctx context.Context, kubeClient kubernetes.Interface) error {
+	cronJob, err := kubeClient.BatchV1().CronJobs(cmd.Namespace).Get(ctx, cmd.Name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return fmt.Errorf("no backup schedule %s found in namespace %s", cmd.Name, cmd.Namespace)
+	} else if err != nil {
+		return err
+	}
+
+	jobList, err := kubeClient.BatchV1().Jobs(cmd.Namespace).List(ctx, metav1.ListOptions{LabelSelector: pbackup.ScheduleLabel + "=" + cmd.Name})
+	if err != nil {
+		return err
+	}
+
+	jobs := jobList.Items
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[j].CreationTimestamp.Before(&jobs[i].CreationTimestamp)
+	})
+
+	values := [][]string{}
+	for _, job := range jobs {
+		values = append(values, []string{
+			job.Name,
+			jobStatus(&job),
+			duration.HumanDuration(time.Since(job.CreationTimestamp.Time)),
+		})
+	}
+	table.PrintTable(cmd.Log, []string{"Job", "Status", "Age"}, values)
+
+	cmd.Log.Infof("Schedule: %s", ansi.Color(cronJob.Spec.Schedule, "white+b"))
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		cmd.Log.Warnf("Backup schedule %s is suspended", cmd.Name)
+	}
+	cmd.Log.Infof("Last run: %s", ansi.Color(timeOrNever(cronJob.Status.LastScheduleTime), "white+b"))
+	cmd.Log.Infof("Last successful run: %s", ansi.Color(timeOrNever(cronJob.Status.LastSuccessfulTime), "white+b"))
+	if len(jobs) > 0 && jobStatus(&jobs[0]) == "Failed" {
+		cmd.Log.Warnf("The last backup failed, run kubectl logs -n %s job/%s for details", cmd.Namespace, jobs[0].Name)
+	}
+
+	return nil
+}
+
+func jobStatus(job *batchv1.Job) string {
+	switch {
+	case job.Status.Succeeded > 0:
+		return "Succeeded"
+	case job.Status.Failed > 0:
+		return "Failed"
+	}
+
+	return "Running"
+}
+
+func timeOrNever(t *metav1.Time) string {
+	if t == nil {
+		return "never"
+	}
+
+	return t.Local().Format(time.RFC3339) + " (" + duration.HumanDuration(time.Since(t.Time)) + " ago)"
+}
@@ -0,0 +1,66 @@
+package certs
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewCertsCmd creates a new cobra command
+func NewCertsCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	description := `
+#######################################################
+###################### loft certs #####################
+#######################################################
+Manages the cached client certificates of virtual
+clusters with an access point
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+#################### devspace certs ###################
+#######################################################
+Manages the cached client certificates of virtual
+clusters with an access point
+	`
+	}
+	certsCmd := &cobra.Command{
+		Use:   "certs",
+		Short: "Manages cached access point certificates",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	certsCmd.AddCommand(NewListCmd(globalFlags))
+	certsCmd.AddCommand(NewRefreshCmd(globalFlags))
+	certsCmd.AddCommand(NewRevokeCmd(globalFlags))
+	certsCmd.AddCommand(NewTTLCmd(globalFlags))
+	return certsCmd
+}
+
+// resolveVirtualCluster returns the project and virtual cluster of the context. Contexts that
+// aren't cached anymore are looked up in the kube config.
+func resolveVirtualCluster(certificates []client.CachedCertificate, contextName string) (string, string, error) {
+	for _, certificate := range certificates {
+		if certificate.Context == contextName && certificate.Project != "" && certificate.VirtualCluster != "" {
+			return certificate.Project, certificate.VirtualCluster, nil
+		}
+	}
+
+	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(kubeconfig.LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	metadata, ok := kubeconfig.ParseLoftContext(&kubeConfig, contextName)
+	if !ok || metadata.Kind != kubeconfig.ContextKindVirtualClusterInstance {
+		return "", "", fmt.Errorf("context %s is not a virtual cluster of a project", contextName)
+	}
+
+	return metadata.Project, metadata.Name, nil
+}
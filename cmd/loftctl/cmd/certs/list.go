@@ -0,0 +1,96 @@
+package certs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// ListCmd holds the cmd flags
+type ListCmd struct {
+	*flags.GlobalFlags
+
+	log log.Logger
+}
+
+// NewListCmd creates a new command
+func NewListCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ListCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################### loft certs list ###################
+#######################################################
+Lists the cached access point certificates with their
+subject and expiry. Expired certificates are removed.
+
+Example:
+loft certs list
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################# devspace certs list #################
+#######################################################
+Lists the cached access point certificates with their
+subject and expiry. Expired certificates are removed.
+
+Example:
+devspace certs list
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the cached access point certificates",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+	}
+
+	return c
+}
+
+// Run executes the command
+func (cmd *ListCmd) Run() error {
+	certificates, err := client.CachedCertificates(cmd.Config)
+	if err != nil {
+		return err
+	} else if len(certificates) == 0 {
+		cmd.log.Info("No cached certificates found")
+		return nil
+	}
+
+	now := time.Now()
+	values := [][]string{}
+	for _, certificate := range certificates {
+		ttl := "default"
+		if certificate.TTL > 0 {
+			ttl = fmt.Sprintf("%ds", certificate.TTL)
+		}
+
+		values = append(values, []string{
+			certificate.Context,
+			certificate.Subject,
+			certificate.NotAfter.Local().Format(time.RFC3339),
+			duration.HumanDuration(certificate.NotAfter.Sub(now)),
+			ttl,
+		})
+	}
+
+	table.PrintTable(cmd.log, []string{"Context", "Subject", "Expires", "Remaining", "TTL"}, values)
+	return nil
+}
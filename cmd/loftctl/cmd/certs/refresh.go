@@ -0,0 +1,151 @@
+package certs
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// RefreshCmd holds the cmd flags
+type RefreshCmd struct {
+	*flags.GlobalFlags
+
+	Project        string
+	VirtualCluster string
+	All            bool
+
+	log log.Logger
+}
+
+// NewRefreshCmd creates a new command
+func NewRefreshCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &RefreshCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft certs refresh ##################
+#######################################################
+Requests a new access point certificate for the given
+kube context or virtual cluster, even if the cached
+certificate is still valid. With --all every cached
+certificate is refreshed.
+
+Example:
+loft certs refresh loft-vcluster_my-vcluster_my-project
+loft certs refresh --project my-project --virtual-cluster my-vcluster
+loft certs refresh --all
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############### devspace certs refresh ################
+#######################################################
+Requests a new access point certificate for the given
+kube context or virtual cluster, even if the cached
+certificate is still valid. With --all every cached
+certificate is refreshed.
+
+Example:
+devspace certs refresh --project my-project --virtual-cluster my-vcluster
+devspace certs refresh --all
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "refresh [CONTEXT]",
+		Short: "Requests new access point certificates",
+		Long:  description,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(args)
+		},
+	}
+
+	c.Flags().StringVarP(&cmd.Project, "project", "p", "", "The project of the virtual cluster")
+	c.Flags().StringVar(&cmd.VirtualCluster, "virtual-cluster", "", "The virtual cluster to refresh the certificate of")
+	c.Flags().BoolVar(&cmd.All, "all", false, "Refresh all cached certificates")
+	return c
+}
+
+// Run executes the command
+func (cmd *RefreshCmd) Run(args []string) error {
+	certificates, err := client.CachedCertificates(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	targets, err := cmd.targets(certificates, args)
+	if err != nil {
+		return err
+	} else if len(targets) == 0 {
+		cmd.log.Info("No cached certificates found")
+		return nil
+	}
+
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	err = client.VerifyVersion(baseClient)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		_, _, err = baseClient.VirtualClusterAccessPointCertificate(target[0], target[1], true)
+		if err != nil {
+			return errors.Wrapf(err, "refresh certificate of virtual cluster %s in project %s", target[1], target[0])
+		}
+
+		cmd.log.Donef("Successfully refreshed certificate of virtual cluster %s in project %s", ansi.Color(target[1], "white+b"), ansi.Color(target[0], "white+b"))
+	}
+
+	return nil
+}
+
+// targets returns the project and virtual cluster pairs to refresh
+func (cmd *RefreshCmd) targets(certificates []client.CachedCertificate, args []string) ([][2]string, error) {
+	switch {
+	case cmd.All:
+		if len(args) > 0 || cmd.Project != "" || cmd.VirtualCluster != "" {
+			return nil, fmt.Errorf("--all can't be used together with a context or virtual cluster")
+		}
+
+		targets := [][2]string{}
+		for _, certificate := range certificates {
+			if certificate.Project == "" || certificate.VirtualCluster == "" {
+				cmd.log.Warnf("Skip certificate of context %s as its virtual cluster is unknown", certificate.Context)
+				continue
+			}
+
+			targets = append(targets, [2]string{certificate.Project, certificate.VirtualCluster})
+		}
+		return targets, nil
+	case len(args) == 1:
+		if cmd.Project != "" || cmd.VirtualCluster != "" {
+			return nil, fmt.Errorf("please specify either a context or --project and --virtual-cluster")
+		}
+
+		project, virtualCluster, err := resolveVirtualCluster(certificates, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return [][2]string{{project, virtualCluster}}, nil
+	case cmd.Project != "" && cmd.VirtualCluster != "":
+		return [][2]string{{cmd.Project, cmd.VirtualCluster}}, nil
+	}
+
+	return nil, fmt.Errorf("please specify a context, --project and --virtual-cluster or --all, e.g. loft certs refresh %s", kubeconfig.VirtualClusterInstanceContextName("my-project", "my-vcluster"))
+}
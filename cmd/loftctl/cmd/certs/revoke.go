@@ -0,0 +1,109 @@
+package certs
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// RevokeCmd holds the cmd flags
+type RevokeCmd struct {
+	*flags.GlobalFlags
+
+	All bool
+
+	log log.Logger
+}
+
+// NewRevokeCmd creates a new command
+func NewRevokeCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &RevokeCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################## loft certs revoke ##################
+#######################################################
+Removes the cached access point certificate of the
+given kube context, or all cached certificates with
+--all. A new certificate is requested the next time
+the context is used. Certificates that were already
+issued stay valid until they expire.
+
+Example:
+loft certs revoke loft-vcluster_my-vcluster_my-project
+loft certs revoke --all
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################ devspace certs revoke ################
+#######################################################
+Removes the cached access point certificate of the
+given kube context, or all cached certificates with
+--all. A new certificate is requested the next time
+the context is used. Certificates that were already
+issued stay valid until they expire.
+
+Example:
+devspace certs revoke --all
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "revoke [CONTEXT]",
+		Short: "Removes cached access point certificates",
+		Long:  description,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(args)
+		},
+	}
+
+	c.Flags().BoolVar(&cmd.All, "all", false, "Remove all cached certificates")
+	return c
+}
+
+// Run executes the command
+func (cmd *RevokeCmd) Run(args []string) error {
+	if cmd.All == (len(args) == 1) {
+		return fmt.Errorf("please specify either a context or --all")
+	}
+
+	contexts := args
+	if cmd.All {
+		certificates, err := client.CachedCertificates(cmd.Config)
+		if err != nil {
+			return err
+		}
+
+		for _, certificate := range certificates {
+			contexts = append(contexts, certificate.Context)
+		}
+		if len(contexts) == 0 {
+			cmd.log.Info("No cached certificates found")
+			return nil
+		}
+	}
+
+	for _, contextName := range contexts {
+		removed, err := client.RemoveCachedCertificate(cmd.Config, contextName)
+		if err != nil {
+			return err
+		} else if !removed {
+			return fmt.Errorf("no certificate cached for context %s", contextName)
+		}
+
+		cmd.log.Donef("Successfully removed cached certificate of context %s", ansi.Color(contextName, "white+b"))
+	}
+
+	return nil
+}
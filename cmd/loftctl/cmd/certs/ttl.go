@@ -0,0 +1,108 @@
+package certs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// TTLCmd holds the cmd flags
+type TTLCmd struct {
+	*flags.GlobalFlags
+
+	Reset bool
+
+	log log.Logger
+}
+
+// NewTTLCmd creates a new command
+func NewTTLCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &TTLCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+#################### loft certs ttl ###################
+#######################################################
+Prints or sets the ttl in seconds new access point
+certificates are requested with. The ttl is stored in
+the loft config, so every profile has its own ttl.
+
+Example:
+loft certs ttl
+loft certs ttl 3600
+loft certs ttl --reset
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################## devspace certs ttl #################
+#######################################################
+Prints or sets the ttl in seconds new access point
+certificates are requested with. The ttl is stored in
+the loft config, so every profile has its own ttl.
+
+Example:
+devspace certs ttl
+devspace certs ttl 3600
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "ttl [SECONDS]",
+		Short: "Prints or sets the access point certificate ttl",
+		Long:  description,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(args)
+		},
+	}
+
+	c.Flags().BoolVar(&cmd.Reset, "reset", false, "Resets the ttl to the default")
+	return c
+}
+
+// Run executes the command
+func (cmd *TTLCmd) Run(args []string) error {
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	config := baseClient.Config()
+	if len(args) == 0 && !cmd.Reset {
+		_, err = fmt.Fprintln(os.Stdout, config.CertificateTTL())
+		return err
+	} else if len(args) == 1 && cmd.Reset {
+		return fmt.Errorf("please specify either a ttl or --reset")
+	}
+
+	ttl := int32(0)
+	if len(args) == 1 {
+		parsed, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil || parsed <= 0 {
+			return fmt.Errorf("ttl must be a positive number of seconds, got %s", args[0])
+		}
+
+		ttl = int32(parsed)
+	}
+
+	config.AccessPointCertificateTTL = ttl
+	err = baseClient.Save()
+	if err != nil {
+		return err
+	}
+
+	cmd.log.Donef("Successfully set access point certificate ttl to %s seconds", ansi.Color(strconv.Itoa(int(config.CertificateTTL())), "white+b"))
+	return nil
+}
@@ -53,8 +53,7 @@ type SpaceCmd struct {
 	DisableDirectClusterEndpoint bool
 	Template                     string
 	Version                      string
-	Set                          []string
-	ParametersFile               string
+	Parameters                   parameters.Sources
 	SkipWait                     bool
 
 	UseExisting bool
@@ -136,8 +135,7 @@ devspace create space myspace --project myproject --team myteam
 	c.Flags().BoolVar(&cmd.UseExisting, "use", false, "If loft should use the space if its already there")
 	c.Flags().StringVar(&cmd.Template, "template", "", "The space template to use")
 	c.Flags().StringVar(&cmd.Version, "version", "", "The template version to use")
-	c.Flags().StringSliceVar(&cmd.Set, "set", []string{}, "Allows specific template parameters to be set. E.g. --set myParameter=myValue")
-	c.Flags().StringVar(&cmd.ParametersFile, "parameters", "", "The file where the parameter values for the apps are specified")
+	cmd.Parameters.AddFlags(c.Flags())
 	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the space")
 	return c
 }
@@ -380,8 +378,13 @@ func (cmd *SpaceCmd) resolveTemplate(baseClient client.Client) (*managementv1.Sp
 		templateParameters = spaceTemplate.Spec.Parameters
 	}
 
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return nil, "", err
+	}
+
 	// resolve space template parameters
-	resolvedParameters, err := parameters.ResolveTemplateParameters(cmd.Set, templateParameters, cmd.ParametersFile)
+	resolvedParameters, err := parameters.ResolveTemplateParameters(templateParameters, &cmd.Parameters, parameters.NewProjectSecrets(managementClient, cmd.Project))
 	if err != nil {
 		return nil, "", err
 	}
@@ -553,7 +556,7 @@ func (cmd *SpaceCmd) legacyCreateSpace(baseClient client.Client, spaceName strin
 				return errors.Wrap(err, "resolve space template apps")
 			}
 
-			appsWithParameters, err := parameters.ResolveAppParameters(apps, cmd.ParametersFile, cmd.Log)
+			appsWithParameters, err := parameters.ResolveAppParameters(apps, &cmd.Parameters, nil, cmd.Log)
 			if err != nil {
 				return err
 			}
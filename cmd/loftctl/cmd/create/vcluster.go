@@ -63,9 +63,8 @@ type VirtualClusterCmd struct {
 	Recreate    bool
 	Update      bool
 
-	Set            []string
-	ParametersFile string
-	Version        string
+	Parameters parameters.Sources
+	Version    string
 
 	DisplayName string
 	Description string
@@ -149,10 +148,9 @@ devspace create vcluster test --project myproject
 	c.Flags().BoolVar(&cmd.UseExisting, "use", false, "If loft should use the virtual cluster if its already there")
 	c.Flags().StringVar(&cmd.Template, "template", "", "The virtual cluster template to use to create the virtual cluster")
 	c.Flags().StringVar(&cmd.Version, "version", "", "The template version to use")
-	c.Flags().StringSliceVar(&cmd.Set, "set", []string{}, "Allows specific template parameters to be set. E.g. --set myParameter=myValue")
-	c.Flags().StringVar(&cmd.ParametersFile, "parameters", "", "The file where the parameter values for the apps are specified")
+	cmd.Parameters.AddFlags(c.Flags())
 	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the vcluster")
-	c.Flags().Int32Var(&cmd.AccessPointCertificateTTL, "ttl", 86_400, "Sets certificate TTL when using virtual cluster via access point")
+	c.Flags().Int32Var(&cmd.AccessPointCertificateTTL, "ttl", 0, "Sets certificate TTL when using virtual cluster via access point. If 0, the ttl of the loft config is used")
 	return c
 }
 
@@ -351,6 +349,14 @@ func (cmd *VirtualClusterCmd) createVirtualCluster(baseClient client.Client, vir
 			return err
 		}
 
+		// request the certificate with the given ttl, it is kept when the certificate is refreshed
+		if contextOptions.VirtualClusterAccessPointEnabled && cmd.AccessPointCertificateTTL > 0 {
+			_, _, err = baseClient.VirtualClusterAccessPointCertificateWithTTL(cmd.Project, virtualClusterName, cmd.AccessPointCertificateTTL)
+			if err != nil {
+				return err
+			}
+		}
+
 		// update kube config
 		err = kubeconfig.UpdateKubeConfig(contextOptions)
 		if err != nil {
@@ -394,8 +400,13 @@ func (cmd *VirtualClusterCmd) resolveTemplate(baseClient client.Client) (*manage
 		templateParameters = virtualClusterTemplate.Spec.Parameters
 	}
 
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return nil, "", err
+	}
+
 	// resolve space template parameters
-	resolvedParameters, err := parameters.ResolveTemplateParameters(cmd.Set, templateParameters, cmd.ParametersFile)
+	resolvedParameters, err := parameters.ResolveTemplateParameters(templateParameters, &cmd.Parameters, parameters.NewProjectSecrets(managementClient, cmd.Project))
 	if err != nil {
 		return nil, "", err
 	}
@@ -575,7 +586,7 @@ func (cmd *VirtualClusterCmd) legacyCreateVirtualCluster(baseClient client.Clien
 			return errors.Wrap(err, "resolve virtual cluster template apps")
 		}
 
-		appsWithParameters, err := parameters.ResolveAppParameters(vClusterApps, cmd.ParametersFile, cmd.Log)
+		appsWithParameters, err := parameters.ResolveAppParameters(vClusterApps, &cmd.Parameters, nil, cmd.Log)
 		if err != nil {
 			return err
 		}
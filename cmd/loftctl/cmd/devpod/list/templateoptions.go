@@ -109,16 +109,42 @@ func parametersToOptions(parameters []storagev1.AppParameter) map[string]*Option
 	options := map[string]*Option{}
 	for _, parameter := range parameters {
 		optionName := VariableToEnvironmentVariable(parameter.Variable)
-		options[optionName] = &Option{
+		option := &Option{
 			Description: parameter.Description,
 			Required:    parameter.Required,
 			Enum:        parameter.Options,
 			Default:     parameter.DefaultValue,
 		}
+
+		// options are a single value in DevPod, so describe the expected format for the other types
+		switch parameter.Type {
+		case "boolean":
+			option.Enum = []string{"true", "false"}
+		case "list":
+			option.Enum = nil
+			option.Suggestions = parameter.Options
+			option.Description = appendDescription(option.Description, "Comma separated list of values")
+		case "duration":
+			option.Description = appendDescription(option.Description, "Duration such as 30m or 1h")
+		case "quantity":
+			option.Description = appendDescription(option.Description, "Quantity such as 500m or 10Gi")
+		case "secret":
+			option.Description = appendDescription(option.Description, "Project secret key in the format SECRET_NAME.KEY")
+		}
+
+		options[optionName] = option
 	}
 	return options
 }
 
+func appendDescription(description, format string) string {
+	if description == "" {
+		return format
+	}
+
+	return description + " (" + format + ")"
+}
+
 func FindTemplate(ctx context.Context, managementClient kube.Interface, projectName, templateName string) (*managementv1.DevPodWorkspaceTemplate, error) {
 	templateList, err := managementClient.Loft().ManagementV1().Projects().ListTemplates(ctx, projectName, metav1.GetOptions{})
 	if err != nil {
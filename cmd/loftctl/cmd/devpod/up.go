@@ -221,6 +221,7 @@ func getParametersFromEnvironment(ctx context.Context, kubeClient kube.Interface
 
 	// parse versions
 	outMap := map[string]interface{}{}
+	secrets := parameters.NewProjectSecrets(kubeClient, projectName)
 	for _, parameter := range templateParameters {
 		// check if its in environment
 		val := envMap[list.VariableToEnvironmentVariable(parameter.Variable)]
@@ -229,6 +230,12 @@ func getParametersFromEnvironment(ctx context.Context, kubeClient kube.Interface
 			return "", fmt.Errorf("validate parameter %s: %w", parameter.Variable, err)
 		}
 
+		strVal, _ := outVal.(string)
+		err = secrets.Verify(ctx, strVal, parameter)
+		if err != nil {
+			return "", fmt.Errorf("validate parameter %s: %w", parameter.Variable, err)
+		}
+
 		outMap[parameter.Variable] = outVal
 	}
 
@@ -0,0 +1,108 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterCmd holds the cmd flags
+type ClusterCmd struct {
+	*flags.GlobalFlags
+
+	DisableDirectClusterEndpoint bool
+
+	Log log.Logger
+}
+
+// NewClusterCmd creates a new command
+func NewClusterCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &ClusterCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################## loft exec cluster ##################
+#######################################################
+Runs a command with a temporary kube context for the
+given cluster. The current kube config is not changed.
+
+Example:
+loft exec cluster mycluster -- kubectl get nodes
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################ devspace exec cluster ################
+#######################################################
+Runs a command with a temporary kube context for the
+given cluster. The current kube config is not changed.
+
+Example:
+devspace exec cluster mycluster -- kubectl get nodes
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "cluster [CLUSTER_NAME] -- COMMAND [ARGS...]",
+		Short: "Runs a command against the given cluster",
+		Long:  description,
+		Args:  commandArgsValidator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			clusterName, command := splitArgs(cobraCmd, args)
+			return instance.ExitWithCode(cmd.Run(cobraCmd.Context(), clusterName, command))
+		},
+	}
+
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the cluster")
+	return c
+}
+
+// Run executes the command and returns its exit code
+func (cmd *ClusterCmd) Run(ctx context.Context, clusterName string, args []string) (int, error) {
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return 1, err
+	}
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return 1, err
+	}
+
+	if clusterName == "" {
+		clusterName, err = helper.SelectCluster(baseClient, cmd.Log)
+		if err != nil {
+			return 1, err
+		}
+	}
+
+	cluster, err := managementClient.Loft().ManagementV1().Clusters().Get(ctx, clusterName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsForbidden(err) {
+			return 1, fmt.Errorf("cluster '%s' does not exist, or you don't have permission to use it", clusterName)
+		}
+
+		return 1, err
+	}
+
+	contextOptions, err := use.CreateClusterContextOptions(baseClient, cmd.Config, cluster, "", cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+
+	return runWithContext(ctx, contextOptions, args)
+}
@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/command"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// NewExecCmd creates a new cobra command
+func NewExecCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	description := `
+#######################################################
+###################### loft exec ######################
+#######################################################
+Runs a command against a cluster / space / vcluster
+without changing the current kube config.
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+#################### devspace exec ####################
+#######################################################
+Runs a command against a cluster / space / vcluster
+without changing the current kube config.
+	`
+	}
+	execCmd := &cobra.Command{
+		Use:   "exec",
+		Short: "Runs a command against loft resources",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	execCmd.AddCommand(NewClusterCmd(globalFlags))
+	execCmd.AddCommand(NewSpaceCmd(globalFlags, defaults))
+	execCmd.AddCommand(NewVirtualClusterCmd(globalFlags, defaults))
+	return execCmd
+}
+
+// commandArgsValidator validates that there is at most one name before and a command after the --
+func commandArgsValidator(cobraCmd *cobra.Command, args []string) error {
+	dashIndex := cobraCmd.ArgsLenAtDash()
+	if dashIndex == -1 || dashIndex == len(args) {
+		return fmt.Errorf("please specify the command to run after --, e.g. %s -- kubectl get pods", cobraCmd.CommandPath())
+	} else if dashIndex > 1 {
+		return fmt.Errorf("%s accepts at most one name before --, but got %d", cobraCmd.CommandPath(), dashIndex)
+	}
+
+	return nil
+}
+
+// splitArgs returns the optional name in front of the -- and the command after it
+func splitArgs(cobraCmd *cobra.Command, args []string) (string, []string) {
+	dashIndex := cobraCmd.ArgsLenAtDash()
+	if dashIndex == 1 {
+		return args[0], args[1:]
+	}
+
+	return "", args[dashIndex:]
+}
+
+// runWithContext runs the command with a temporary kube config that only contains the given
+// context. The user's kube config is never touched and the temporary one is removed afterwards.
+func runWithContext(ctx context.Context, contextOptions kubeconfig.ContextOptions, args []string) (int, error) {
+	kubeConfigPath, err := kubeconfig.WriteTempKubeConfig(contextOptions)
+	if err != nil {
+		return 1, fmt.Errorf("write temporary kube config: %w", err)
+	}
+	defer os.Remove(kubeConfigPath)
+
+	env := append(os.Environ(), "KUBECONFIG="+kubeConfigPath)
+	return command.Run(ctx, args, env, os.Stdin, os.Stdout, os.Stderr)
+}
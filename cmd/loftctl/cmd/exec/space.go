@@ -0,0 +1,135 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SpaceCmd holds the cmd flags
+type SpaceCmd struct {
+	*flags.GlobalFlags
+
+	Cluster                      string
+	Project                      string
+	DisableDirectClusterEndpoint bool
+
+	Log log.Logger
+}
+
+// NewSpaceCmd creates a new command
+func NewSpaceCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &SpaceCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################### loft exec space ###################
+#######################################################
+Runs a command with a temporary kube context for the
+given space. The space is woken up if it is sleeping
+and the current kube config is not changed.
+
+Example:
+loft exec space myspace --project myproject -- kubectl get pods
+loft exec space myspace --cluster mycluster -- helm list
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################# devspace exec space #################
+#######################################################
+Runs a command with a temporary kube context for the
+given space. The space is woken up if it is sleeping
+and the current kube config is not changed.
+
+Example:
+devspace exec space myspace --project myproject -- kubectl get pods
+devspace exec space myspace --cluster mycluster -- helm list
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "space [SPACE_NAME] -- COMMAND [ARGS...]",
+		Short: "Runs a command against the given space",
+		Long:  description,
+		Args:  commandArgsValidator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			spaceName, command := splitArgs(cobraCmd, args)
+			return instance.ExitWithCode(cmd.Run(cobraCmd.Context(), spaceName, command))
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVar(&cmd.Cluster, "cluster", "", "The cluster to use")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the space")
+	return c
+}
+
+// Run executes the command and returns its exit code
+func (cmd *SpaceCmd) Run(ctx context.Context, spaceName string, args []string) (int, error) {
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return 1, err
+	}
+
+	cmd.Cluster, cmd.Project, spaceName, err = helper.SelectSpaceInstanceOrSpace(baseClient, spaceName, cmd.Project, cmd.Cluster, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+
+	var contextOptions kubeconfig.ContextOptions
+	if cmd.Project == "" {
+		contextOptions, err = cmd.legacyContextOptions(ctx, baseClient, spaceName)
+	} else {
+		contextOptions, err = cmd.contextOptions(ctx, baseClient, spaceName)
+	}
+	if err != nil {
+		return 1, err
+	}
+
+	return runWithContext(ctx, contextOptions, args)
+}
+
+func (cmd *SpaceCmd) contextOptions(ctx context.Context, baseClient client.Client, spaceName string) (kubeconfig.ContextOptions, error) {
+	spaceInstance, err := instance.WaitForSpaceInstance(ctx, baseClient, cmd.Project, spaceName, cmd.Log)
+	if err != nil {
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	return use.CreateSpaceInstanceOptions(baseClient, cmd.Config, cmd.Project, spaceInstance, cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+}
+
+func (cmd *SpaceCmd) legacyContextOptions(ctx context.Context, baseClient client.Client, spaceName string) (kubeconfig.ContextOptions, error) {
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	cluster, err := managementClient.Loft().ManagementV1().Clusters().Get(ctx, cmd.Cluster, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsForbidden(err) {
+			return kubeconfig.ContextOptions{}, fmt.Errorf("cluster '%s' does not exist, or you don't have permission to use it", cmd.Cluster)
+		}
+
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	return use.CreateClusterContextOptions(baseClient, cmd.Config, cluster, spaceName, cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+}
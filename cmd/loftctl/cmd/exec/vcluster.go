@@ -0,0 +1,145 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/vcluster"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VirtualClusterCmd holds the cmd flags
+type VirtualClusterCmd struct {
+	*flags.GlobalFlags
+
+	Space                        string
+	Cluster                      string
+	Project                      string
+	DisableDirectClusterEndpoint bool
+
+	Log log.Logger
+}
+
+// NewVirtualClusterCmd creates a new command
+func NewVirtualClusterCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &VirtualClusterCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft exec vcluster ##################
+#######################################################
+Runs a command with a temporary kube context for the
+given virtual cluster. The virtual cluster is woken up
+if it is sleeping and the current kube config is not
+changed.
+
+Example:
+loft exec vcluster myvcluster --project myproject -- kubectl get pods
+loft exec vcluster myvcluster --cluster mycluster --space myspace -- helm list
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############### devspace exec vcluster ################
+#######################################################
+Runs a command with a temporary kube context for the
+given virtual cluster. The virtual cluster is woken up
+if it is sleeping and the current kube config is not
+changed.
+
+Example:
+devspace exec vcluster myvcluster --project myproject -- kubectl get pods
+devspace exec vcluster myvcluster --cluster mycluster --space myspace -- helm list
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "vcluster [VCLUSTER_NAME] -- COMMAND [ARGS...]",
+		Short: "Runs a command against the given virtual cluster",
+		Long:  description,
+		Args:  commandArgsValidator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			virtualClusterName, command := splitArgs(cobraCmd, args)
+			return instance.ExitWithCode(cmd.Run(cobraCmd.Context(), virtualClusterName, command))
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVar(&cmd.Space, "space", "", "The space to use")
+	c.Flags().StringVar(&cmd.Cluster, "cluster", "", "The cluster to use")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the vcluster")
+	return c
+}
+
+// Run executes the command and returns its exit code
+func (cmd *VirtualClusterCmd) Run(ctx context.Context, virtualClusterName string, args []string) (int, error) {
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return 1, err
+	}
+
+	cmd.Cluster, cmd.Project, cmd.Space, virtualClusterName, err = helper.SelectVirtualClusterInstanceOrVirtualCluster(baseClient, virtualClusterName, cmd.Space, cmd.Project, cmd.Cluster, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+
+	var contextOptions kubeconfig.ContextOptions
+	if cmd.Project == "" {
+		contextOptions, err = cmd.legacyContextOptions(ctx, baseClient, virtualClusterName)
+	} else {
+		contextOptions, err = cmd.contextOptions(ctx, baseClient, virtualClusterName)
+	}
+	if err != nil {
+		return 1, err
+	}
+
+	return runWithContext(ctx, contextOptions, args)
+}
+
+func (cmd *VirtualClusterCmd) contextOptions(ctx context.Context, baseClient client.Client, virtualClusterName string) (kubeconfig.ContextOptions, error) {
+	virtualClusterInstance, err := instance.WaitForVirtualClusterInstance(ctx, baseClient, cmd.Project, virtualClusterName, cmd.Log)
+	if err != nil {
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	return use.CreateVirtualClusterInstanceOptions(baseClient, cmd.Config, cmd.Project, virtualClusterInstance, cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+}
+
+func (cmd *VirtualClusterCmd) legacyContextOptions(ctx context.Context, baseClient client.Client, virtualClusterName string) (kubeconfig.ContextOptions, error) {
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	cluster, err := managementClient.Loft().ManagementV1().Clusters().Get(ctx, cmd.Cluster, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsForbidden(err) {
+			return kubeconfig.ContextOptions{}, fmt.Errorf("cluster '%s' does not exist, or you don't have permission to use it", cmd.Cluster)
+		}
+
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	err = vcluster.WaitForVCluster(ctx, baseClient, cmd.Cluster, cmd.Space, virtualClusterName, cmd.Log)
+	if err != nil {
+		return kubeconfig.ContextOptions{}, err
+	}
+
+	return use.CreateVClusterContextOptions(baseClient, cmd.Config, cluster, cmd.Space, virtualClusterName, cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+}
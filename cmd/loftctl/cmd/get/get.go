@@ -30,5 +30,6 @@ func NewGetCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *co
 
 	c.AddCommand(NewUserCmd(globalFlags))
 	c.AddCommand(NewSecretCmd(globalFlags, defaults))
+	c.AddCommand(NewTemplateCmd(globalFlags, defaults))
 	return c
 }
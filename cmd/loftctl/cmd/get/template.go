@@ -0,0 +1,161 @@
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/parameters"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/util"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// TemplateCmd holds the flags
+type TemplateCmd struct {
+	*flags.GlobalFlags
+
+	Project string
+	Version string
+	Kind    string
+	Schema  bool
+
+	log log.Logger
+}
+
+// NewTemplateCmd creates a new command
+func NewTemplateCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &TemplateCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+	description := `
+#######################################################
+################## loft get template ##################
+#######################################################
+Returns the parameters of a template allowed in a
+project. With --schema a JSON schema for parameter
+files is printed instead, which can be used by editors
+for validation and autocompletion.
+
+Example:
+loft get template my-template --project myproject
+loft get template my-template --project myproject --schema > schema.json
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################ devspace get template ################
+#######################################################
+Returns the parameters of a template allowed in a
+project. With --schema a JSON schema for parameter
+files is printed instead, which can be used by editors
+for validation and autocompletion.
+
+Example:
+devspace get template my-template --project myproject
+devspace get template my-template --project myproject --schema > schema.json
+#######################################################
+	`
+	}
+	useLine, validator := util.NamedPositionalArgsValidator(true, "TEMPLATE_NAME")
+	c := &cobra.Command{
+		Use:   "template" + useLine,
+		Short: "Returns the parameters of a template",
+		Long:  description,
+		Args:  validator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(args)
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project the template is allowed in")
+	c.Flags().StringVar(&cmd.Version, "version", "", "The template version to use, e.g. 1.2.0 or 1.x.x. Defaults to the latest version")
+	c.Flags().StringVar(&cmd.Kind, "kind", "", "The kind of the template, one of: vcluster, space, devpod. Only needed if the template name is ambiguous")
+	c.Flags().BoolVar(&cmd.Schema, "schema", false, "If true, prints a JSON schema for the template parameters")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *TemplateCmd) Run(args []string) error {
+	if cmd.Project == "" {
+		return fmt.Errorf("please specify a project via --project")
+	}
+
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return err
+	}
+
+	template, err := helper.FindProjectTemplate(context.TODO(), managementClient, cmd.Project, cmd.Kind, args[0])
+	if err != nil {
+		return err
+	}
+
+	templateParameters, resolvedVersion, err := template.GetParameters(cmd.Version)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Schema {
+		title := template.Name
+		if resolvedVersion != "" {
+			title += " " + resolvedVersion
+		}
+
+		schema, err := parameters.GenerateJSONSchema(title, templateParameters)
+		if err != nil {
+			return err
+		}
+
+		out, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "marshal schema")
+		}
+
+		_, err = os.Stdout.Write(append(out, '\n'))
+		return err
+	}
+
+	header := []string{
+		"Variable",
+		"Label",
+		"Type",
+		"Required",
+		"Default",
+	}
+	values := [][]string{}
+	for _, parameter := range templateParameters {
+		parameterType := parameter.Type
+		if parameterType == "" {
+			parameterType = "string"
+		}
+
+		values = append(values, []string{
+			parameter.Variable,
+			parameter.Label,
+			parameterType,
+			strconv.FormatBool(parameter.Required),
+			parameter.DefaultValue,
+		})
+	}
+
+	table.PrintTable(cmd.log, header, values)
+	return nil
+}
@@ -0,0 +1,36 @@
+package kubeconfig
+
+import (
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// NewKubeConfigCmd creates a new cobra command
+func NewKubeConfigCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	description := `
+#######################################################
+################### loft kubeconfig ###################
+#######################################################
+Manages the kube contexts created by loft
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################# devspace kubeconfig #################
+#######################################################
+Manages the kube contexts created by loft
+	`
+	}
+	kubeConfigCmd := &cobra.Command{
+		Use:   "kubeconfig",
+		Short: "Manages the kube contexts created by loft",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	kubeConfigCmd.AddCommand(NewPathCmd(globalFlags))
+	kubeConfigCmd.AddCommand(NewPruneCmd(globalFlags))
+	kubeConfigCmd.AddCommand(NewSyncCmd(globalFlags))
+	return kubeConfigCmd
+}
@@ -0,0 +1,91 @@
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pkubeconfig "github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// PathCmd holds the cmd flags
+type PathCmd struct {
+	*flags.GlobalFlags
+
+	log log.Logger
+}
+
+// NewPathCmd creates a new command
+func NewPathCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &PathCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################ loft kubeconfig path #################
+#######################################################
+Prints a KUBECONFIG value that merges the kubeconfig
+target loft writes its contexts to with your own
+kubeconfig files. The target is set via
+--kubeconfig-target, LOFT_KUBECONFIG_TARGET or
+loft defaults set kubeconfig-target
+
+Example:
+loft defaults set kubeconfig-target ~/.kube/loft.yaml
+export KUBECONFIG=$(loft kubeconfig path)
+
+If the target is a directory, contexts created
+afterwards are only included after running the
+command again.
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############## devspace kubeconfig path ###############
+#######################################################
+Prints a KUBECONFIG value that merges the kubeconfig
+target loft writes its contexts to with your own
+kubeconfig files. The target is set via
+--kubeconfig-target, LOFT_KUBECONFIG_TARGET or
+devspace defaults set kubeconfig-target
+
+Example:
+export KUBECONFIG=$(devspace kubeconfig path)
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "path",
+		Short: "Prints a KUBECONFIG value including the loft contexts",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+	}
+
+	return c
+}
+
+// Run executes the command
+func (cmd *PathCmd) Run() error {
+	if pkubeconfig.Target() == "" {
+		return fmt.Errorf("no kubeconfig target configured, please use --kubeconfig-target, LOFT_KUBECONFIG_TARGET or set the kubeconfig-target default")
+	}
+
+	path, err := pkubeconfig.MergedPath(os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+	if err != nil {
+		return errors.Wrap(err, "list kubeconfig target")
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, path)
+	return err
+}
@@ -0,0 +1,263 @@
+package kubeconfig
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/naming"
+	"github.com/loft-sh/loftctl/v3/pkg/kube"
+	pkubeconfig "github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// PruneCmd holds the cmd flags
+type PruneCmd struct {
+	*flags.GlobalFlags
+
+	DryRun bool
+	All    bool
+
+	log log.Logger
+}
+
+// NewPruneCmd creates a new command
+func NewPruneCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &PruneCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################ loft kubeconfig prune ################
+#######################################################
+Removes the kube contexts of clusters, spaces and
+virtual clusters that don't exist anymore or that you
+can't access anymore. Contexts created for another
+loft instance are kept.
+
+Example:
+loft kubeconfig prune --dry-run
+loft kubeconfig prune
+loft kubeconfig prune --all
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############## devspace kubeconfig prune ##############
+#######################################################
+Removes the kube contexts of clusters, spaces and
+virtual clusters that don't exist anymore or that you
+can't access anymore. Contexts created for another
+loft instance are kept.
+
+Example:
+devspace kubeconfig prune --dry-run
+devspace kubeconfig prune
+devspace kubeconfig prune --all
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "prune",
+		Short: "Removes kube contexts of deleted or inaccessible instances",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context())
+		},
+	}
+
+	c.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "Only print the contexts that would be removed")
+	c.Flags().BoolVar(&cmd.All, "all", false, "Remove all loft contexts, including the management context and contexts of other loft instances, without checking them")
+	return c
+}
+
+// Run executes the command
+func (cmd *PruneCmd) Run(ctx context.Context) error {
+	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(pkubeconfig.LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return errors.Wrap(err, "load kube config")
+	}
+
+	contextNames := []string{}
+	for name := range kubeConfig.Contexts {
+		contextNames = append(contextNames, name)
+	}
+	sort.Strings(contextNames)
+
+	var checker *contextChecker
+	if !cmd.All {
+		checker, err = newContextChecker(cmd.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	values := [][]string{}
+	for _, name := range contextNames {
+		loftContext, ok := pkubeconfig.ParseLoftContext(&kubeConfig, name)
+		if !ok {
+			continue
+		}
+
+		reason := "--all"
+		if !cmd.All {
+			if loftContext.Kind == pkubeconfig.ContextKindManagement {
+				continue
+			} else if !checker.owns(&kubeConfig, name, loftContext) {
+				cmd.log.Debugf("Skip context %s, because it belongs to another loft instance", name)
+				continue
+			}
+
+			reason, err = checker.check(ctx, loftContext)
+			if err != nil {
+				cmd.log.Warnf("Error checking context %s: %v", name, err)
+				continue
+			} else if reason == "" {
+				continue
+			}
+		}
+
+		status := "would be removed"
+		if !cmd.DryRun {
+			err = pkubeconfig.DeleteContext(name)
+			if err != nil {
+				return errors.Wrapf(err, "delete context %s", name)
+			}
+
+			status = "removed"
+		}
+
+		values = append(values, []string{name, loftContext.Kind, reason, status})
+	}
+
+	if len(values) == 0 {
+		cmd.log.Done("No kube contexts to prune")
+		return nil
+	}
+
+	table.PrintTable(cmd.log, []string{"Context", "Kind", "Reason", "Status"}, values)
+	if cmd.DryRun {
+		cmd.log.Infof("Would remove %s kube contexts, run without --dry-run to remove them", ansi.Color(fmt.Sprint(len(values)), "white+b"))
+	} else {
+		cmd.log.Donef("Successfully removed %s kube contexts", ansi.Color(fmt.Sprint(len(values)), "white+b"))
+	}
+
+	return nil
+}
+
+// contextChecker checks through the management API whether the instances of loft contexts still exist
+type contextChecker struct {
+	baseClient       client.Client
+	managementClient kube.Interface
+	configPath       string
+}
+
+func newContextChecker(config string) (*contextChecker, error) {
+	baseClient, err := client.NewClientFromPath(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.VerifyVersion(baseClient)
+	if err != nil {
+		return nil, err
+	}
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return nil, err
+	}
+
+	configPath, err := filepath.Abs(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contextChecker{
+		baseClient:       baseClient,
+		managementClient: managementClient,
+		configPath:       configPath,
+	}, nil
+}
+
+// owns returns true if the context was created for the loft instance the checker is logged into
+func (c *contextChecker) owns(kubeConfig *api.Config, contextName string, loftContext pkubeconfig.ContextMetadata) bool {
+	host := strings.TrimSuffix(c.baseClient.Config().Host, "/")
+	if loftContext.Host != "" {
+		return strings.TrimSuffix(loftContext.Host, "/") == host
+	}
+
+	// contexts without metadata are matched by their server or token command
+	kubeContext := kubeConfig.Contexts[contextName]
+	if cluster, ok := kubeConfig.Clusters[kubeContext.Cluster]; ok && strings.HasPrefix(cluster.Server, host+"/") {
+		return true
+	}
+
+	authInfo, ok := kubeConfig.AuthInfos[kubeContext.AuthInfo]
+	if !ok || authInfo.Exec == nil {
+		return false
+	}
+
+	// the token command of direct cluster endpoints and access points references the loft config
+	args := authInfo.Exec.Args
+	for i := range args {
+		if args[i] == "--config" && i+1 < len(args) {
+			return args[i+1] == c.configPath
+		}
+	}
+
+	defaultConfigPath, _ := filepath.Abs(client.DefaultCacheConfig)
+	return len(args) > 0 && args[0] == "token" && c.configPath == defaultConfigPath
+}
+
+// check returns the reason why the context should be pruned or an empty string if it should be kept
+func (c *contextChecker) check(ctx context.Context, loftContext pkubeconfig.ContextMetadata) (string, error) {
+	var err error
+	switch loftContext.Kind {
+	case pkubeconfig.ContextKindCluster:
+		_, err = c.managementClient.Loft().ManagementV1().Clusters().Get(ctx, loftContext.Cluster, metav1.GetOptions{})
+	case pkubeconfig.ContextKindSpace, pkubeconfig.ContextKindVirtualCluster:
+		clusterClient, clusterErr := c.baseClient.Cluster(loftContext.Cluster)
+		if clusterErr != nil {
+			return "", clusterErr
+		}
+
+		if loftContext.Kind == pkubeconfig.ContextKindSpace {
+			_, err = clusterClient.Agent().ClusterV1().Spaces().Get(ctx, loftContext.Namespace, metav1.GetOptions{})
+		} else {
+			_, err = clusterClient.Agent().StorageV1().VirtualClusters(loftContext.Namespace).Get(ctx, loftContext.Name, metav1.GetOptions{})
+		}
+	case pkubeconfig.ContextKindSpaceInstance:
+		_, err = c.managementClient.Loft().ManagementV1().SpaceInstances(naming.ProjectNamespace(loftContext.Project)).Get(ctx, loftContext.Name, metav1.GetOptions{})
+	case pkubeconfig.ContextKindVirtualClusterInstance:
+		_, err = c.managementClient.Loft().ManagementV1().VirtualClusterInstances(naming.ProjectNamespace(loftContext.Project)).Get(ctx, loftContext.Name, metav1.GetOptions{})
+	default:
+		return "", nil
+	}
+
+	// other errors, e.g. if the login expired, must never lead to removed contexts
+	if kerrors.IsNotFound(err) {
+		return "not found", nil
+	} else if kerrors.IsForbidden(err) {
+		return "no access", nil
+	}
+
+	return "", err
+}
@@ -0,0 +1,219 @@
+package kubeconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	pkubeconfig "github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncCmd holds the cmd flags
+type SyncCmd struct {
+	*flags.GlobalFlags
+
+	Project                      string
+	CurrentContext               string
+	DisableDirectClusterEndpoint bool
+
+	log log.Logger
+}
+
+// NewSyncCmd creates a new command
+func NewSyncCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &SyncCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################ loft kubeconfig sync #################
+#######################################################
+Creates or updates the kube contexts of all clusters,
+spaces and virtual clusters you have access to. The
+current context is not changed unless
+--current-context is given. If a project is given,
+only the spaces and virtual clusters of that project
+are synced.
+
+Example:
+loft kubeconfig sync
+loft kubeconfig sync --project my-project
+loft kubeconfig sync --current-context loft-vcluster_my-vcluster_my-project
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############## devspace kubeconfig sync ###############
+#######################################################
+Creates or updates the kube contexts of all clusters,
+spaces and virtual clusters you have access to. The
+current context is not changed unless
+--current-context is given. If a project is given,
+only the spaces and virtual clusters of that project
+are synced.
+
+Example:
+devspace kubeconfig sync
+devspace kubeconfig sync --project my-project
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "sync",
+		Short: "Creates kube contexts for all accessible instances",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context())
+		},
+	}
+
+	c.Flags().StringVarP(&cmd.Project, "project", "p", "", "Only sync the spaces and virtual clusters of this project")
+	c.Flags().StringVar(&cmd.CurrentContext, "current-context", "", "The synced context to switch to. If empty the current context is kept")
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint for the contexts")
+	return c
+}
+
+type syncedContext struct {
+	kind    string
+	options pkubeconfig.ContextOptions
+}
+
+// Run executes the command
+func (cmd *SyncCmd) Run(ctx context.Context) error {
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	err = client.VerifyVersion(baseClient)
+	if err != nil {
+		return err
+	}
+
+	contexts, err := cmd.collectContexts(ctx, baseClient)
+	if err != nil {
+		return err
+	}
+
+	options := []pkubeconfig.ContextOptions{}
+	kinds := map[string]string{}
+	for _, syncedContext := range contexts {
+		if syncedContext.options.Name == cmd.CurrentContext {
+			syncedContext.options.SetActive = true
+		}
+
+		options = append(options, syncedContext.options)
+		kinds[syncedContext.options.Name] = syncedContext.kind
+	}
+	if cmd.CurrentContext != "" && kinds[cmd.CurrentContext] == "" {
+		return fmt.Errorf("context %s is not one of the synced contexts", cmd.CurrentContext)
+	} else if len(options) == 0 {
+		cmd.log.Info("No clusters, spaces or virtual clusters found to sync")
+		return nil
+	}
+
+	results, err := pkubeconfig.UpdateKubeConfigs(options)
+	if err != nil {
+		return errors.Wrap(err, "update kube config")
+	}
+
+	counts := map[string]int{}
+	values := [][]string{}
+	for _, option := range options {
+		result := results[option.Name]
+		counts[result]++
+		values = append(values, []string{option.Name, kinds[option.Name], result})
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i][0] < values[j][0]
+	})
+
+	table.PrintTable(cmd.log, []string{"Context", "Kind", "Result"}, values)
+	cmd.log.Donef(
+		"Successfully synced kube contexts: %s added, %s updated, %s unchanged",
+		ansi.Color(fmt.Sprint(counts[pkubeconfig.ResultAdded]), "white+b"),
+		ansi.Color(fmt.Sprint(counts[pkubeconfig.ResultUpdated]), "white+b"),
+		ansi.Color(fmt.Sprint(counts[pkubeconfig.ResultUnchanged]), "white+b"),
+	)
+	return nil
+}
+
+// collectContexts creates the context options for all accessible instances. Instances whose
+// context can't be created are skipped with a warning.
+func (cmd *SyncCmd) collectContexts(ctx context.Context, baseClient client.Client) ([]syncedContext, error) {
+	contexts := []syncedContext{}
+	if cmd.Project == "" {
+		managementClient, err := baseClient.Management()
+		if err != nil {
+			return nil, err
+		}
+
+		clusterList, err := managementClient.Loft().ManagementV1().Clusters().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "list clusters")
+		}
+
+		for i := range clusterList.Items {
+			options, err := use.CreateClusterContextOptions(baseClient, cmd.Config, &clusterList.Items[i], "", cmd.DisableDirectClusterEndpoint, false, log.Discard)
+			if err != nil {
+				cmd.log.Warnf("Skip cluster %s: %v", clusterList.Items[i].Name, err)
+				continue
+			}
+
+			contexts = append(contexts, syncedContext{kind: "cluster", options: options})
+		}
+	}
+
+	spaceInstances, err := helper.GetSpaceInstances(baseClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "list spaces")
+	}
+	for _, spaceInstance := range spaceInstances {
+		if cmd.Project != "" && spaceInstance.Project != cmd.Project {
+			continue
+		}
+
+		options, err := use.CreateSpaceInstanceOptions(baseClient, cmd.Config, spaceInstance.Project, &spaceInstance.SpaceInstance, cmd.DisableDirectClusterEndpoint, false, log.Discard)
+		if err != nil {
+			cmd.log.Warnf("Skip space %s in project %s: %v", spaceInstance.SpaceInstance.Name, spaceInstance.Project, err)
+			continue
+		}
+
+		contexts = append(contexts, syncedContext{kind: "space", options: options})
+	}
+
+	virtualClusterInstances, err := helper.GetVirtualClusterInstances(baseClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "list virtual clusters")
+	}
+	for _, virtualClusterInstance := range virtualClusterInstances {
+		if cmd.Project != "" && virtualClusterInstance.Project != cmd.Project {
+			continue
+		}
+
+		options, err := use.CreateVirtualClusterInstanceOptions(baseClient, cmd.Config, virtualClusterInstance.Project, &virtualClusterInstance.VirtualClusterInstance, cmd.DisableDirectClusterEndpoint, false, log.Discard)
+		if err != nil {
+			cmd.log.Warnf("Skip virtual cluster %s in project %s: %v", virtualClusterInstance.VirtualClusterInstance.Name, virtualClusterInstance.Project, err)
+			continue
+		}
+
+		contexts = append(contexts, syncedContext{kind: "virtual cluster", options: options})
+	}
+
+	return contexts, nil
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/ghodss/yaml"
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	"github.com/loft-sh/loftctl/v3/pkg/kube"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/version"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	OutdatedKindVirtualCluster  = "VirtualClusterInstance"
+	OutdatedKindSpace           = "SpaceInstance"
+	OutdatedKindDevPodWorkspace = "DevPodWorkspaceInstance"
+)
+
+// OutdatedCmd holds the cmd flags
+type OutdatedCmd struct {
+	*flags.GlobalFlags
+
+	Projects []string
+	Output   string
+
+	log log.Logger
+}
+
+// OutdatedInstance is an instance that uses an older template version than the latest one
+type OutdatedInstance struct {
+	Kind           string                `json:"kind"`
+	Project        string                `json:"project"`
+	Name           string                `json:"name"`
+	Owner          *storagev1.UserOrTeam `json:"owner,omitempty"`
+	Phase          string                `json:"phase,omitempty"`
+	Template       string                `json:"template"`
+	VersionPattern string                `json:"versionPattern"`
+	CurrentVersion string                `json:"currentVersion"`
+	LatestVersion  string                `json:"latestVersion"`
+}
+
+// NewOutdatedCmd creates a new command
+func NewOutdatedCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &OutdatedCmd{
+		GlobalFlags: globalFlags,
+		log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+#################### loft outdated ####################
+#######################################################
+Lists all virtual clusters, spaces and DevPod workspaces
+you have access to that use an older template version
+than the latest one. Sleeping instances are included.
+
+Example:
+loft outdated
+loft outdated --project myproject -o json
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################## devspace outdated ##################
+#######################################################
+Lists all virtual clusters, spaces and DevPod workspaces
+you have access to that use an older template version
+than the latest one. Sleeping instances are included.
+
+Example:
+devspace outdated
+devspace outdated --project myproject -o json
+#######################################################
+	`
+	}
+
+	outdatedCmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "Lists instances that use outdated template versions",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+	}
+
+	outdatedCmd.Flags().StringSliceVarP(&cmd.Projects, "project", "p", []string{}, "If set, only checks instances in the given projects")
+	outdatedCmd.Flags().StringVarP(&cmd.Output, "output", "o", "", "Output format. One of: (json, yaml). If empty, prints a table")
+	return outdatedCmd
+}
+
+// Run executes the command
+func (cmd *OutdatedCmd) Run() error {
+	if cmd.Output != "" && cmd.Output != "json" && cmd.Output != "yaml" {
+		return errors.Errorf("unknown output format %s, allowed formats are: json, yaml", cmd.Output)
+	}
+
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return err
+	}
+
+	outdated, err := cmd.findOutdated(baseClient, managementClient)
+	if err != nil {
+		return err
+	}
+
+	switch cmd.Output {
+	case "json":
+		out, err := json.MarshalIndent(outdated, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.Write(append(out, '\n'))
+		return err
+	case "yaml":
+		out, err := yaml.Marshal(outdated)
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+
+	if len(outdated) == 0 {
+		cmd.log.Donef("All instances use the latest template version")
+		return nil
+	}
+
+	header := []string{
+		"Kind",
+		"Name",
+		"Project",
+		"Owner",
+		"Status",
+		"Template",
+		"Current",
+		"Latest",
+	}
+	values := [][]string{}
+	for _, instance := range outdated {
+		owner := ""
+		if instance.Owner != nil {
+			owner = instance.Owner.User
+			if owner == "" {
+				owner = instance.Owner.Team
+			}
+		}
+
+		values = append(values, []string{
+			instance.Kind,
+			instance.Name,
+			instance.Project,
+			owner,
+			instance.Phase,
+			instance.Template,
+			instance.CurrentVersion,
+			instance.LatestVersion,
+		})
+	}
+
+	table.PrintTable(cmd.log, header, values)
+	return nil
+}
+
+func (cmd *OutdatedCmd) findOutdated(baseClient client.Client, managementClient kube.Interface) ([]OutdatedInstance, error) {
+	projectTemplates := map[string]*managementv1.ProjectTemplates{}
+	getTemplates := func(project string) (*managementv1.ProjectTemplates, error) {
+		if templates, ok := projectTemplates[project]; ok {
+			return templates, nil
+		}
+
+		templates, err := managementClient.Loft().ManagementV1().Projects().ListTemplates(context.TODO(), project, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list templates of project %s", project)
+		}
+
+		projectTemplates[project] = templates
+		return templates, nil
+	}
+
+	outdated := []OutdatedInstance{}
+	virtualClusterInstances, err := helper.GetVirtualClusterInstances(baseClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, virtualCluster := range virtualClusterInstances {
+		instance := virtualCluster.VirtualClusterInstance
+		if instance.Spec.TemplateRef == nil || !cmd.includesProject(virtualCluster.Project) {
+			continue
+		}
+
+		templates, err := getTemplates(virtualCluster.Project)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range templates.VirtualClusterTemplates {
+			if templates.VirtualClusterTemplates[i].Name == instance.Spec.TemplateRef.Name {
+				outdated = cmd.appendIfOutdated(outdated, &templates.VirtualClusterTemplates[i], OutdatedInstance{
+					Kind:     OutdatedKindVirtualCluster,
+					Project:  virtualCluster.Project,
+					Name:     instance.Name,
+					Owner:    instance.Spec.Owner,
+					Phase:    string(instance.Status.Phase),
+					Template: instance.Spec.TemplateRef.Name,
+				}, instance.Spec.TemplateRef.Version)
+				break
+			}
+		}
+	}
+
+	spaceInstances, err := helper.GetSpaceInstances(baseClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, space := range spaceInstances {
+		instance := space.SpaceInstance
+		if instance.Spec.TemplateRef == nil || !cmd.includesProject(space.Project) {
+			continue
+		}
+
+		templates, err := getTemplates(space.Project)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range templates.SpaceTemplates {
+			if templates.SpaceTemplates[i].Name == instance.Spec.TemplateRef.Name {
+				outdated = cmd.appendIfOutdated(outdated, &templates.SpaceTemplates[i], OutdatedInstance{
+					Kind:     OutdatedKindSpace,
+					Project:  space.Project,
+					Name:     instance.Name,
+					Owner:    instance.Spec.Owner,
+					Phase:    string(instance.Status.Phase),
+					Template: instance.Spec.TemplateRef.Name,
+				}, instance.Spec.TemplateRef.Version)
+				break
+			}
+		}
+	}
+
+	workspaceInstances, err := helper.GetDevPodWorkspaceInstances(baseClient)
+	if isResourceNotServed(err) {
+		// older loft versions don't serve DevPod workspaces, so there is nothing to check
+		cmd.log.Debugf("Skipping DevPod workspaces: %v", err)
+		workspaceInstances = nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, workspace := range workspaceInstances {
+		instance := workspace.DevPodWorkspaceInstance
+		if instance.Spec.TemplateRef == nil || !cmd.includesProject(workspace.Project) {
+			continue
+		}
+
+		templates, err := getTemplates(workspace.Project)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range templates.DevPodWorkspaceTemplates {
+			if templates.DevPodWorkspaceTemplates[i].Name == instance.Spec.TemplateRef.Name {
+				outdated = cmd.appendIfOutdated(outdated, &templates.DevPodWorkspaceTemplates[i], OutdatedInstance{
+					Kind:     OutdatedKindDevPodWorkspace,
+					Project:  workspace.Project,
+					Name:     instance.Name,
+					Owner:    instance.Spec.Owner,
+					Phase:    string(instance.Status.Phase),
+					Template: instance.Spec.TemplateRef.Name,
+				}, instance.Spec.TemplateRef.Version)
+				break
+			}
+		}
+	}
+
+	return outdated, nil
+}
+
+func (cmd *OutdatedCmd) appendIfOutdated(outdated []OutdatedInstance, versions storagev1.VersionsAccessor, instance OutdatedInstance, versionPattern string) []OutdatedInstance {
+	latestVersion, currentVersion, err := version.ResolveVersion(versions, versionPattern)
+	if err != nil {
+		cmd.log.Warnf("Error resolving template version of %s %s in project %s: %v", instance.Kind, instance.Name, instance.Project, err)
+		return outdated
+	} else if latestVersion == nil || currentVersion == nil || !version.IsOlder(currentVersion, latestVersion) {
+		return outdated
+	}
+
+	instance.VersionPattern = versionPattern
+	instance.CurrentVersion = currentVersion.GetVersion()
+	instance.LatestVersion = latestVersion.GetVersion()
+	return append(outdated, instance)
+}
+
+// isResourceNotServed returns true if the error says that the server doesn't know the requested resource
+func isResourceNotServed(err error) bool {
+	return kerrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
+
+func (cmd *OutdatedCmd) includesProject(project string) bool {
+	if len(cmd.Projects) == 0 {
+		return true
+	}
+
+	for _, p := range cmd.Projects {
+		if p == project {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsResourceNotServed(t *testing.T) {
+	resource := schema.GroupResource{Group: "management.loft.sh", Resource: "devpodworkspaceinstances"}
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "no error"},
+		{name: "not found", err: kerrors.NewNotFound(resource, ""), expected: true},
+		{name: "no match", err: &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: resource.Group, Kind: "DevPodWorkspaceInstance"}}, expected: true},
+		{name: "forbidden", err: kerrors.NewForbidden(resource, "", errors.New("denied"))},
+		{name: "other", err: errors.New("connection refused")},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, isResourceNotServed(testCase.err), testCase.expected, testCase.name)
+	}
+}
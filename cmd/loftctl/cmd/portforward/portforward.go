@@ -0,0 +1,38 @@
+package portforward
+
+import (
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// NewPortForwardCmd creates a new cobra command
+func NewPortForwardCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	description := `
+#######################################################
+################## loft port-forward ##################
+#######################################################
+Forwards local ports to a pod or service within a
+space / vcluster.
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################ devspace port-forward ################
+#######################################################
+Forwards local ports to a pod or service within a
+space / vcluster.
+	`
+	}
+	portForwardCmd := &cobra.Command{
+		Use:   "port-forward",
+		Short: "Forwards ports to loft resources",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	portForwardCmd.AddCommand(NewSpaceCmd(globalFlags, defaults))
+	portForwardCmd.AddCommand(NewVirtualClusterCmd(globalFlags, defaults))
+	return portForwardCmd
+}
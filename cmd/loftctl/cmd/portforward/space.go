@@ -0,0 +1,114 @@
+package portforward
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	pportforward "github.com/loft-sh/loftctl/v3/pkg/portforward"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// SpaceCmd holds the cmd flags
+type SpaceCmd struct {
+	*flags.GlobalFlags
+
+	Project string
+
+	Log log.Logger
+}
+
+// NewSpaceCmd creates a new command
+func NewSpaceCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &SpaceCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+############### loft port-forward space ###############
+#######################################################
+Forwards one or more local ports to a pod or service
+within a space. For services the remote port is the
+service port and a running pod of the service is
+selected. If the pod restarts or goes away, a new pod is
+selected and the ports are forwarded again.
+
+Example:
+loft port-forward space myspace svc/my-api 8080:80 --project myproject
+loft port-forward space myspace pod/my-pod 8080 9090:9000
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############# devspace port-forward space #############
+#######################################################
+Forwards one or more local ports to a pod or service
+within a space. For services the remote port is the
+service port and a running pod of the service is
+selected. If the pod restarts or goes away, a new pod is
+selected and the ports are forwarded again.
+
+Example:
+devspace port-forward space myspace svc/my-api 8080:80 --project myproject
+devspace port-forward space myspace pod/my-pod 8080 9090:9000
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "space SPACE_NAME TYPE/NAME [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT_N:]REMOTE_PORT_N]",
+		Short: "Forwards ports to a pod or service in a space",
+		Long:  description,
+		Args:  cobra.MinimumNArgs(3),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd, args)
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	return c
+}
+
+// Run executes the command
+func (cmd *SpaceCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	target, err := pportforward.ParseTarget(args[1])
+	if err != nil {
+		return err
+	}
+
+	// stop forwarding gracefully on interrupt
+	ctx, stop := signal.NotifyContext(cobraCmd.Context(), os.Interrupt)
+	defer stop()
+
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	project, spaceInstance, err := instance.SelectSpaceInstance(ctx, baseClient, cmd.Project, args[0], cmd.Log)
+	if err != nil {
+		return err
+	}
+	cmd.Project = project
+
+	spaceName := spaceInstance.Name
+	restConfig, err := baseClient.SpaceInstanceConfig(cmd.Project, spaceName)
+	if err != nil {
+		return err
+	}
+
+	// the space is a namespace, so pods and services are always looked up within it
+	namespace := spaceInstance.Spec.ClusterRef.Namespace
+	if namespace == "" {
+		namespace = spaceName
+	}
+
+	return pportforward.ForwardWithReconnect(ctx, restConfig, namespace, target, args[2:], cmd.Log)
+}
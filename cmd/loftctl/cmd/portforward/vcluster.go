@@ -0,0 +1,109 @@
+package portforward
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	pportforward "github.com/loft-sh/loftctl/v3/pkg/portforward"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// VirtualClusterCmd holds the cmd flags
+type VirtualClusterCmd struct {
+	*flags.GlobalFlags
+
+	Project   string
+	Namespace string
+
+	Log log.Logger
+}
+
+// NewVirtualClusterCmd creates a new command
+func NewVirtualClusterCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &VirtualClusterCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+############# loft port-forward vcluster ##############
+#######################################################
+Forwards one or more local ports to a pod or service
+within a virtual cluster. For services the remote port
+is the service port and a running pod of the service is
+selected. If the pod restarts or goes away, a new pod is
+selected and the ports are forwarded again.
+
+Example:
+loft port-forward vcluster myvcluster svc/my-api 8080:80 --project myproject
+loft port-forward vcluster myvcluster pod/my-pod 8080 9090:9000 --namespace default
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+########### devspace port-forward vcluster ############
+#######################################################
+Forwards one or more local ports to a pod or service
+within a virtual cluster. For services the remote port
+is the service port and a running pod of the service is
+selected. If the pod restarts or goes away, a new pod is
+selected and the ports are forwarded again.
+
+Example:
+devspace port-forward vcluster myvcluster svc/my-api 8080:80 --project myproject
+devspace port-forward vcluster myvcluster pod/my-pod 8080 9090:9000 --namespace default
+#######################################################
+	`
+	}
+	c := &cobra.Command{
+		Use:   "vcluster VCLUSTER_NAME TYPE/NAME [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT_N:]REMOTE_PORT_N]",
+		Short: "Forwards ports to a pod or service in a virtual cluster",
+		Long:  description,
+		Args:  cobra.MinimumNArgs(3),
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd, args)
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().StringVarP(&cmd.Namespace, "namespace", "n", "default", "The namespace within the virtual cluster")
+	return c
+}
+
+// Run executes the command
+func (cmd *VirtualClusterCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	target, err := pportforward.ParseTarget(args[1])
+	if err != nil {
+		return err
+	}
+
+	// stop forwarding gracefully on interrupt
+	ctx, stop := signal.NotifyContext(cobraCmd.Context(), os.Interrupt)
+	defer stop()
+
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	project, virtualClusterInstance, err := instance.SelectVirtualClusterInstance(ctx, baseClient, cmd.Project, args[0], cmd.Log)
+	if err != nil {
+		return err
+	}
+	cmd.Project = project
+
+	restConfig, err := baseClient.VirtualClusterInstanceConfig(cmd.Project, virtualClusterInstance.Name)
+	if err != nil {
+		return err
+	}
+
+	return pportforward.ForwardWithReconnect(ctx, restConfig, cmd.Namespace, target, args[2:], cmd.Log)
+}
@@ -0,0 +1,35 @@
+package proxy
+
+import (
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// NewProxyCmd creates a new cobra command
+func NewProxyCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	description := `
+#######################################################
+###################### loft proxy #####################
+#######################################################
+Runs a local kubernetes API proxy to loft resources
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+#################### devspace proxy ###################
+#######################################################
+Runs a local kubernetes API proxy to loft resources
+	`
+	}
+	proxyCmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Runs a local kubernetes API proxy",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	proxyCmd.AddCommand(NewVirtualClusterCmd(globalFlags, defaults))
+	return proxyCmd
+}
@@ -0,0 +1,241 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	pproxy "github.com/loft-sh/loftctl/v3/pkg/proxy"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/util"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
+)
+
+// VirtualClusterCmd holds the cmd flags
+type VirtualClusterCmd struct {
+	*flags.GlobalFlags
+
+	Project                      string
+	Address                      string
+	Port                         int
+	TLS                          bool
+	KubeConfig                   string
+	DisableDirectClusterEndpoint bool
+
+	Log log.Logger
+}
+
+// NewVirtualClusterCmd creates a new command
+func NewVirtualClusterCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &VirtualClusterCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft proxy vcluster #################
+#######################################################
+Runs a local kubernetes API proxy to the given virtual
+cluster for tools that cannot use exec credential
+plugins. The proxy authenticates all requests itself
+and refreshes its credentials before they expire, so
+it only listens on loopback addresses and only accepts
+requests for localhost, 127.0.0.1 or [::1]. A kube
+config for the proxy is printed or written to
+--kubeconfig.
+
+Example:
+loft proxy vcluster myvcluster --project myproject --port 8001
+loft proxy vcluster myvcluster --tls --kubeconfig ./proxy.yaml
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############### devspace proxy vcluster ###############
+#######################################################
+Runs a local kubernetes API proxy to the given virtual
+cluster for tools that cannot use exec credential
+plugins. The proxy authenticates all requests itself
+and refreshes its credentials before they expire, so
+it only listens on loopback addresses and only accepts
+requests for localhost, 127.0.0.1 or [::1]. A kube
+config for the proxy is printed or written to
+--kubeconfig.
+
+Example:
+devspace proxy vcluster myvcluster --project myproject --port 8001
+devspace proxy vcluster myvcluster --tls --kubeconfig ./proxy.yaml
+#######################################################
+	`
+	}
+	useLine, validator := util.NamedPositionalArgsValidator(false, "VCLUSTER_NAME")
+	c := &cobra.Command{
+		Use:   "vcluster" + useLine,
+		Short: "Runs a local kubernetes API proxy to a virtual cluster",
+		Long:  description,
+		Args:  validator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context(), args)
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().StringVar(&cmd.Address, "address", "127.0.0.1", "The loopback address the proxy listens on, e.g. 127.0.0.1 or ::1")
+	c.Flags().IntVar(&cmd.Port, "port", 8001, "The port the proxy listens on, 0 selects a random port")
+	c.Flags().BoolVar(&cmd.TLS, "tls", false, "If true, serves the proxy via https with a self-signed certificate")
+	c.Flags().StringVar(&cmd.KubeConfig, "kubeconfig", "", "If set, writes the kube config for the proxy to this file instead of printing it")
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the vcluster")
+	return c
+}
+
+// Run executes the command
+func (cmd *VirtualClusterCmd) Run(ctx context.Context, args []string) error {
+	if !pproxy.IsLoopbackHost(cmd.Address) {
+		return fmt.Errorf("the proxy authenticates every request with your credentials, so it only listens on a loopback address like 127.0.0.1 or ::1, not on %s", cmd.Address)
+	}
+
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	virtualClusterName := ""
+	if len(args) > 0 {
+		virtualClusterName = args[0]
+	}
+
+	project, virtualClusterInstance, err := instance.SelectVirtualClusterInstance(ctx, baseClient, cmd.Project, virtualClusterName, cmd.Log)
+	if err != nil {
+		return err
+	}
+	cmd.Project = project
+	virtualClusterName = virtualClusterInstance.Name
+
+	contextOptions, err := use.CreateVirtualClusterInstanceOptions(baseClient, cmd.Config, cmd.Project, virtualClusterInstance, cmd.DisableDirectClusterEndpoint, false, cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	handler, err := pproxy.New(cmd.proxyOptions(baseClient, contextOptions, virtualClusterName))
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(cmd.Address, strconv.Itoa(cmd.Port)))
+	if err != nil {
+		return fmt.Errorf("listen on %s:%d: %w", cmd.Address, cmd.Port, err)
+	}
+	defer listener.Close()
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	proxyConfig := &rest.Config{Host: "http://" + listener.Addr().String()}
+	if cmd.TLS {
+		certificate, caData, err := pproxy.SelfSignedCertificate([]string{cmd.Address, "localhost"}, 30*24*time.Hour)
+		if err != nil {
+			return fmt.Errorf("create serving certificate: %w", err)
+		}
+
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+		proxyConfig.Host = "https://" + listener.Addr().String()
+		proxyConfig.CAData = caData
+	}
+
+	err = cmd.writeKubeConfig(proxyConfig)
+	if err != nil {
+		return err
+	}
+
+	// serve until interrupted
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	cmd.Log.Donef("Proxying %s to virtual cluster %s in project %s", ansi.Color(proxyConfig.Host, "white+b"), ansi.Color(virtualClusterName, "white+b"), ansi.Color(cmd.Project, "white+b"))
+	if cmd.TLS {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// proxyOptions selects the same credentials for the proxy a kube context for the virtual cluster would use
+func (cmd *VirtualClusterCmd) proxyOptions(baseClient client.Client, contextOptions kubeconfig.ContextOptions, virtualClusterName string) pproxy.Options {
+	options := pproxy.Options{
+		Server:   contextOptions.Server,
+		CAData:   contextOptions.CaData,
+		Insecure: contextOptions.InsecureSkipTLSVerify,
+	}
+
+	switch {
+	case contextOptions.VirtualClusterAccessPointEnabled:
+		options.Certificate = pproxy.CachedCertificate(func(forceRefresh bool) (string, string, error) {
+			return baseClient.VirtualClusterAccessPointCertificate(cmd.Project, virtualClusterName, forceRefresh)
+		})
+	case contextOptions.DirectClusterEndpointEnabled:
+		options.Token = pproxy.CachedToken(time.Minute, func() (string, error) {
+			return baseClient.DirectClusterEndpointToken(false)
+		})
+	default:
+		options.Token = func() (string, error) {
+			return baseClient.Config().AccessKey, nil
+		}
+	}
+
+	return options
+}
+
+func (cmd *VirtualClusterCmd) writeKubeConfig(proxyConfig *rest.Config) error {
+	var out io.Writer = os.Stdout
+	if cmd.KubeConfig != "" {
+		file, err := os.OpenFile(cmd.KubeConfig, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		out = file
+	}
+
+	err := kubeconfig.WriteTokenKubeConfig(proxyConfig, "", out)
+	if err != nil {
+		return fmt.Errorf("write kube config: %w", err)
+	}
+	if cmd.KubeConfig != "" {
+		cmd.Log.Infof("Wrote kube config for the proxy to %s", cmd.KubeConfig)
+	}
+
+	return nil
+}
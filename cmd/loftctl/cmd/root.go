@@ -5,24 +5,34 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/backup"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/certs"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/connect"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/create"
 	cmddefaults "github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/defaults"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/delete"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/devpod"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/exec"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/generate"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/get"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/importcmd"
+	cmdkubeconfig "github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/kubeconfig"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/list"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/portforward"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/proxy"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/reset"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/set"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/share"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/shell"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/sleep"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/upgradetemplate"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/validate"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/vars"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/wakeup"
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
-	"github.com/loft-sh/loftctl/v3/pkg/defaults"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
 	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
 	"github.com/loft-sh/log"
 	"github.com/sirupsen/logrus"
@@ -30,7 +40,7 @@ import (
 )
 
 // NewRootCmd returns a new root command
-func NewRootCmd(streamLogger *log.StreamLogger) *cobra.Command {
+func NewRootCmd(streamLogger *log.StreamLogger, defaults *pdefaults.Defaults) *cobra.Command {
 	return &cobra.Command{
 		Use:           "loft",
 		SilenceUsage:  true,
@@ -43,6 +53,16 @@ func NewRootCmd(streamLogger *log.StreamLogger) *cobra.Command {
 			if globalFlags.Config == "" && os.Getenv("LOFT_CONFIG") != "" {
 				globalFlags.Config = os.Getenv("LOFT_CONFIG")
 			}
+			if globalFlags.KubeConfigTarget == "" && os.Getenv("LOFT_KUBECONFIG_TARGET") != "" {
+				globalFlags.KubeConfigTarget = os.Getenv("LOFT_KUBECONFIG_TARGET")
+			}
+			if globalFlags.KubeConfigTarget == "" && defaults != nil {
+				globalFlags.KubeConfigTarget, _ = defaults.Get(pdefaults.KeyKubeConfigTarget, "")
+			}
+			err := kubeconfig.SetTarget(globalFlags.KubeConfigTarget)
+			if err != nil {
+				return err
+			}
 
 			if globalFlags.LogOutput == "json" {
 				streamLogger.SetFormat(log.JSONFormat)
@@ -82,19 +102,22 @@ func Execute() {
 
 // BuildRoot creates a new root command from the
 func BuildRoot(log *log.StreamLogger) *cobra.Command {
-	rootCmd := NewRootCmd(log)
-	persistentFlags := rootCmd.PersistentFlags()
-	globalFlags = flags.SetGlobalFlags(persistentFlags)
-	defaults, err := defaults.NewFromPath(defaults.ConfigFolder, defaults.ConfigFile)
+	defaults, err := pdefaults.NewFromPath(pdefaults.ConfigFolder, pdefaults.ConfigFile)
 	if err != nil {
 		log.Debugf("Error loading defaults: %v", err)
 	}
 
+	rootCmd := NewRootCmd(log, defaults)
+	persistentFlags := rootCmd.PersistentFlags()
+	globalFlags = flags.SetGlobalFlags(persistentFlags)
+
 	// add top level commands
 	rootCmd.AddCommand(NewStartCmd(globalFlags))
+	rootCmd.AddCommand(NewUninstallCmd(globalFlags))
 	rootCmd.AddCommand(NewLoginCmd(globalFlags))
 	rootCmd.AddCommand(NewTokenCmd(globalFlags))
-	rootCmd.AddCommand(NewBackupCmd(globalFlags))
+	rootCmd.AddCommand(backup.NewBackupCmd(globalFlags))
+	rootCmd.AddCommand(NewOutdatedCmd(globalFlags))
 	rootCmd.AddCommand(NewCompletionCmd(rootCmd, globalFlags))
 	rootCmd.AddCommand(NewUpgradeCmd())
 
@@ -115,6 +138,14 @@ func BuildRoot(log *log.StreamLogger) *cobra.Command {
 	rootCmd.AddCommand(connect.NewConnectCmd(globalFlags))
 	rootCmd.AddCommand(cmddefaults.NewDefaultsCmd(globalFlags, defaults))
 	rootCmd.AddCommand(devpod.NewDevPodCmd(globalFlags))
+	rootCmd.AddCommand(upgradetemplate.NewUpgradeTemplateCmd(globalFlags, defaults))
+	rootCmd.AddCommand(validate.NewValidateCmd(globalFlags, defaults))
+	rootCmd.AddCommand(exec.NewExecCmd(globalFlags, defaults))
+	rootCmd.AddCommand(shell.NewShellCmd(globalFlags, defaults))
+	rootCmd.AddCommand(portforward.NewPortForwardCmd(globalFlags, defaults))
+	rootCmd.AddCommand(proxy.NewProxyCmd(globalFlags, defaults))
+	rootCmd.AddCommand(cmdkubeconfig.NewKubeConfigCmd(globalFlags))
+	rootCmd.AddCommand(certs.NewCertsCmd(globalFlags))
 
 	return rootCmd
 }
@@ -0,0 +1,97 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/command"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/spf13/cobra"
+)
+
+// keepAliveInterval is how often the last activity of the instance is updated while a shell is open
+var keepAliveInterval = time.Minute
+
+// NewShellCmd creates a new cobra command
+func NewShellCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	description := `
+#######################################################
+###################### loft shell #####################
+#######################################################
+Opens a shell that is connected to a space / vcluster
+without changing the current kube config.
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+#################### devspace shell ###################
+#######################################################
+Opens a shell that is connected to a space / vcluster
+without changing the current kube config.
+	`
+	}
+	shellCmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Opens a shell for loft resources",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	shellCmd.AddCommand(NewSpaceCmd(globalFlags, defaults))
+	shellCmd.AddCommand(NewVirtualClusterCmd(globalFlags, defaults))
+	return shellCmd
+}
+
+// runShell starts the user's shell with a private kube config for the given context and the
+// given environment variables. While the shell is open keepAlive is called periodically.
+func runShell(ctx context.Context, contextOptions kubeconfig.ContextOptions, env []string, keepAlive func(ctx context.Context) error, log log.Logger) (int, error) {
+	kubeConfigPath, err := kubeconfig.WriteTempKubeConfig(contextOptions)
+	if err != nil {
+		return 1, fmt.Errorf("write temporary kube config: %w", err)
+	}
+	defer os.Remove(kubeConfigPath)
+
+	keepAliveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(keepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				err := keepAlive(keepAliveCtx)
+				if err != nil && keepAliveCtx.Err() == nil {
+					log.Debugf("Error updating last activity: %v", err)
+				}
+			case <-keepAliveCtx.Done():
+				return
+			}
+		}
+	}()
+
+	env = append(append(os.Environ(), env...), "KUBECONFIG="+kubeConfigPath)
+	return command.Run(ctx, []string{userShell()}, env, os.Stdin, os.Stdout, os.Stderr)
+}
+
+// userShell returns the shell of the current user
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	} else if runtime.GOOS == "windows" {
+		if comSpec := os.Getenv("COMSPEC"); comSpec != "" {
+			return comSpec
+		}
+
+		return "cmd.exe"
+	}
+
+	return "/bin/sh"
+}
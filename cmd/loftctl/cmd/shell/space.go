@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"context"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/constants"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	"github.com/loft-sh/loftctl/v3/pkg/space"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/util"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// SpaceCmd holds the cmd flags
+type SpaceCmd struct {
+	*flags.GlobalFlags
+
+	Project                      string
+	DisableDirectClusterEndpoint bool
+
+	Log log.Logger
+}
+
+// NewSpaceCmd creates a new command
+func NewSpaceCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &SpaceCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################### loft shell space ##################
+#######################################################
+Opens $SHELL with KUBECONFIG pointing to a private kube
+config for the given space. LOFT_PROJECT and LOFT_SPACE
+are set within the shell and the space is kept awake
+until the shell exits.
+
+Example:
+loft shell space myspace --project myproject
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################# devspace shell space ################
+#######################################################
+Opens $SHELL with KUBECONFIG pointing to a private kube
+config for the given space. LOFT_PROJECT and LOFT_SPACE
+are set within the shell and the space is kept awake
+until the shell exits.
+
+Example:
+devspace shell space myspace --project myproject
+#######################################################
+	`
+	}
+	useLine, validator := util.NamedPositionalArgsValidator(false, "SPACE_NAME")
+	c := &cobra.Command{
+		Use:   "space" + useLine,
+		Short: "Opens a shell for the given space",
+		Long:  description,
+		Args:  validator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return instance.ExitWithCode(cmd.Run(cobraCmd.Context(), args))
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the space")
+	return c
+}
+
+// Run executes the command and returns the exit code of the shell
+func (cmd *SpaceCmd) Run(ctx context.Context, args []string) (int, error) {
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return 1, err
+	}
+
+	spaceName := ""
+	if len(args) > 0 {
+		spaceName = args[0]
+	}
+
+	project, spaceInstance, err := instance.SelectSpaceInstance(ctx, baseClient, cmd.Project, spaceName, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+	cmd.Project = project
+	spaceName = spaceInstance.Name
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return 1, err
+	}
+
+	contextOptions, err := use.CreateSpaceInstanceOptions(baseClient, cmd.Config, cmd.Project, spaceInstance, cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+
+	cmd.Log.Infof("Opening shell for space %s in project %s, type exit to leave it", ansi.Color(spaceName, "white+b"), ansi.Color(cmd.Project, "white+b"))
+	env := []string{
+		constants.LoftProjectEnv + "=" + cmd.Project,
+		constants.LoftSpaceEnv + "=" + spaceName,
+	}
+	return runShell(ctx, contextOptions, env, func(ctx context.Context) error {
+		return space.UpdateLastActivity(ctx, managementClient, spaceInstance.Namespace, spaceName)
+	}, cmd.Log)
+}
@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"context"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/cmd/use"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/constants"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/instance"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/util"
+	"github.com/loft-sh/loftctl/v3/pkg/vcluster"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// VirtualClusterCmd holds the cmd flags
+type VirtualClusterCmd struct {
+	*flags.GlobalFlags
+
+	Project                      string
+	DisableDirectClusterEndpoint bool
+
+	Log log.Logger
+}
+
+// NewVirtualClusterCmd creates a new command
+func NewVirtualClusterCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &VirtualClusterCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+################# loft shell vcluster #################
+#######################################################
+Opens $SHELL with KUBECONFIG pointing to a private kube
+config for the given virtual cluster. LOFT_PROJECT and
+LOFT_VCLUSTER are set within the shell and the virtual
+cluster is kept awake until the shell exits.
+
+Example:
+loft shell vcluster myvcluster --project myproject
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############### devspace shell vcluster ###############
+#######################################################
+Opens $SHELL with KUBECONFIG pointing to a private kube
+config for the given virtual cluster. LOFT_PROJECT and
+LOFT_VCLUSTER are set within the shell and the virtual
+cluster is kept awake until the shell exits.
+
+Example:
+devspace shell vcluster myvcluster --project myproject
+#######################################################
+	`
+	}
+	useLine, validator := util.NamedPositionalArgsValidator(false, "VCLUSTER_NAME")
+	c := &cobra.Command{
+		Use:   "vcluster" + useLine,
+		Short: "Opens a shell for the given virtual cluster",
+		Long:  description,
+		Args:  validator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return instance.ExitWithCode(cmd.Run(cobraCmd.Context(), args))
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().BoolVar(&cmd.DisableDirectClusterEndpoint, "disable-direct-cluster-endpoint", false, "When enabled does not use an available direct cluster endpoint to connect to the vcluster")
+	return c
+}
+
+// Run executes the command and returns the exit code of the shell
+func (cmd *VirtualClusterCmd) Run(ctx context.Context, args []string) (int, error) {
+	baseClient, err := instance.NewClient(cmd.Config)
+	if err != nil {
+		return 1, err
+	}
+
+	virtualClusterName := ""
+	if len(args) > 0 {
+		virtualClusterName = args[0]
+	}
+
+	project, virtualClusterInstance, err := instance.SelectVirtualClusterInstance(ctx, baseClient, cmd.Project, virtualClusterName, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+	cmd.Project = project
+	virtualClusterName = virtualClusterInstance.Name
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return 1, err
+	}
+
+	contextOptions, err := use.CreateVirtualClusterInstanceOptions(baseClient, cmd.Config, cmd.Project, virtualClusterInstance, cmd.DisableDirectClusterEndpoint, true, cmd.Log)
+	if err != nil {
+		return 1, err
+	}
+
+	cmd.Log.Infof("Opening shell for virtual cluster %s in project %s, type exit to leave it", ansi.Color(virtualClusterName, "white+b"), ansi.Color(cmd.Project, "white+b"))
+	env := []string{
+		constants.LoftProjectEnv + "=" + cmd.Project,
+		constants.LoftVirtualClusterEnv + "=" + virtualClusterName,
+	}
+	return runShell(ctx, contextOptions, env, func(ctx context.Context) error {
+		return vcluster.UpdateLastActivity(ctx, managementClient, virtualClusterInstance.Namespace, virtualClusterName)
+	}, cmd.Log)
+}
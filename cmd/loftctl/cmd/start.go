@@ -19,6 +19,7 @@ import (
 	"github.com/loft-sh/loftctl/v3/pkg/clihelper"
 	"github.com/loft-sh/loftctl/v3/pkg/config"
 	"github.com/loft-sh/loftctl/v3/pkg/printhelper"
+	"github.com/loft-sh/loftctl/v3/pkg/uninstall"
 	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
 	"github.com/mgutz/ansi"
 	"github.com/pkg/errors"
@@ -88,8 +89,7 @@ Please make sure you meet the following requirements
 before running this command:
 
 1. Current kube-context has admin access to the cluster
-2. Helm v3 must be installed
-3. kubectl must be installed
+2. kubectl must be installed
 
 #######################################################
 	`,
@@ -131,7 +131,7 @@ func (cmd *StartCmd) Run(ctx context.Context) error {
 
 	// Uninstall already existing Loft instance
 	if cmd.Reset {
-		err = clihelper.UninstallLoft(cmd.KubeClient, cmd.RestConfig, cmd.Context, cmd.Namespace, cmd.Log)
+		err = uninstall.Reset(ctx, cmd.KubeClient, cmd.RestConfig, cmd.Context, cmd.Namespace, cmd.Log)
 		if err != nil {
 			return err
 		}
@@ -184,7 +184,7 @@ func (cmd *StartCmd) Run(ctx context.Context) error {
 	}
 
 	// make sure we are ready for installing
-	err = cmd.prepareInstall()
+	err = cmd.prepareInstall(ctx)
 	if err != nil {
 		return err
 	}
@@ -197,9 +197,9 @@ func (cmd *StartCmd) Run(ctx context.Context) error {
 	return cmd.success(ctx)
 }
 
-func (cmd *StartCmd) prepareInstall() error {
+func (cmd *StartCmd) prepareInstall(ctx context.Context) error {
 	// delete admin user & secret
-	return clihelper.UninstallLoft(cmd.KubeClient, cmd.RestConfig, cmd.Context, cmd.Namespace, log.Discard)
+	return uninstall.Reset(ctx, cmd.KubeClient, cmd.RestConfig, cmd.Context, cmd.Namespace, log.Discard)
 }
 
 func (cmd *StartCmd) prepare() error {
@@ -240,23 +240,13 @@ func (cmd *StartCmd) prepare() error {
 	// kube client config
 	kubeClientConfig = clientcmd.NewNonInteractiveClientConfig(kubeConfig, contextToLoad, &clientcmd.ConfigOverrides{}, clientcmd.NewDefaultClientConfigLoadingRules())
 
-	// test for helm and kubectl
-	_, err = exec.LookPath("helm")
-	if err != nil {
-		return fmt.Errorf("seems like helm is not installed. Helm is required for the installation of loft. Please visit https://helm.sh/docs/intro/install/ for install instructions")
-	}
-
-	output, err := exec.Command("helm", "version").CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("seems like there are issues with your helm client: \n\n%s", output)
-	}
-
+	// test for kubectl
 	_, err = exec.LookPath("kubectl")
 	if err != nil {
 		return fmt.Errorf("seems like kubectl is not installed. Kubectl is required for the installation of loft. Please visit https://kubernetes.io/docs/tasks/tools/install-kubectl/ for install instructions")
 	}
 
-	output, err = exec.Command("kubectl", "version", "--context", contextToLoad).CombinedOutput()
+	output, err := exec.Command("kubectl", "version", "--context", contextToLoad).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("seems like kubectl cannot connect to your Kubernetes cluster: \n\n%s", output)
 	}
@@ -375,24 +365,21 @@ func (cmd *StartCmd) handleAlreadyExistingInstallation(ctx context.Context) erro
 }
 
 func (cmd *StartCmd) upgradeLoft(email string) error {
-	extraArgs := []string{}
+	options := clihelper.HelmOptions{
+		Version:     cmd.Version,
+		KubeContext: cmd.Context,
+		Namespace:   cmd.Namespace,
+		// Do not reuse values if --reset flag is provided because this should be a new install
+		ReuseValues: !cmd.Reset && cmd.ReuseValues,
+	}
 	if email != "" {
-		extraArgs = append(extraArgs, "--set", "admin.email="+email)
+		options.Set = append(options.Set, "admin.email="+email)
 	}
-
 	if cmd.Password != "" {
-		extraArgs = append(extraArgs, "--set", "admin.password="+cmd.Password)
+		options.Set = append(options.Set, "admin.password="+cmd.Password)
 	}
 	if cmd.Host != "" {
-		extraArgs = append(extraArgs, "--set", "ingress.enabled=true", "--set", "ingress.host="+cmd.Host)
-	}
-	if cmd.Version != "" {
-		extraArgs = append(extraArgs, "--version", cmd.Version)
-	}
-
-	// Do not use --reuse-values if --reset flag is provided because this should be a new install and it will cause issues with `helm template`
-	if !cmd.Reset && cmd.ReuseValues {
-		extraArgs = append(extraArgs, "--reuse-values")
+		options.Set = append(options.Set, "ingress.enabled=true", "ingress.host="+cmd.Host)
 	}
 
 	if cmd.Values != "" {
@@ -400,17 +387,16 @@ func (cmd *StartCmd) upgradeLoft(email string) error {
 		if err != nil {
 			return err
 		}
-		extraArgs = append(extraArgs, "--values", absValuesPath)
+		options.ValuesFiles = append(options.ValuesFiles, absValuesPath)
 	}
 
-	chartName := cmd.ChartPath
-	chartRepo := ""
-	if chartName == "" {
-		chartName = cmd.ChartName
-		chartRepo = cmd.ChartRepo
+	options.Chart = cmd.ChartPath
+	if options.Chart == "" {
+		options.Chart = cmd.ChartName
+		options.Repo = cmd.ChartRepo
 	}
 
-	err := clihelper.UpgradeLoft(chartName, chartRepo, cmd.Context, cmd.Namespace, extraArgs, cmd.Log)
+	err := clihelper.UpgradeLoft(options, cmd.Log)
 	if err != nil {
 		if !cmd.Reset {
 			return errors.New(err.Error() + fmt.Sprintf("\n\nIf want to purge and reinstall Loft, run: %s\n", ansi.Color("loft start --reset", "green+b")))
@@ -419,7 +405,7 @@ func (cmd *StartCmd) upgradeLoft(email string) error {
 		// Try to purge Loft and retry install
 		cmd.Log.Info("Trying to delete objects blocking Loft installation")
 
-		manifests, err := clihelper.GetLoftManifests(chartName, chartRepo, cmd.Context, cmd.Namespace, extraArgs, cmd.Log)
+		manifests, err := clihelper.GetLoftManifests(options)
 		if err != nil {
 			return err
 		}
@@ -437,7 +423,7 @@ func (cmd *StartCmd) upgradeLoft(email string) error {
 		_ = kubectlDelete.Run()
 
 		// Retry Loft installation
-		err = clihelper.UpgradeLoft(chartName, chartRepo, cmd.Context, cmd.Namespace, extraArgs, cmd.Log)
+		err = clihelper.UpgradeLoft(options, cmd.Log)
 		if err != nil {
 			return errors.New(err.Error() + fmt.Sprintf("\n\nLoft installation failed. Reach out to get help:\n- via Slack: %s (fastest option)\n- via Online Chat: %s\n- via Email: %s\n", ansi.Color("https://slack.loft.sh/", "green+b"), ansi.Color("https://loft.sh/", "green+b"), ansi.Color("support@loft.sh", "green+b")))
 		}
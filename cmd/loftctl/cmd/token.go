@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
 	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/naming"
 	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
 	"github.com/loft-sh/log"
 	"github.com/pkg/errors"
@@ -21,6 +23,8 @@ type TokenCmd struct {
 	DirectClusterEndpoint bool
 	Project               string
 	VirtualCluster        string
+	Space                 string
+	Cluster               string
 	log                   log.Logger
 }
 
@@ -36,10 +40,18 @@ func NewTokenCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
 ###################### loft token #####################
 #######################################################
 Prints an access token to a loft instance. This can
-be used as an ExecAuthenticator for kubernetes
+be used as an ExecAuthenticator for kubernetes. For
+virtual clusters with an access point a client
+certificate is printed instead, which is cached per
+kube context next to the loft config. Spaces, clusters
+and virtual clusters without an access point use the
+access key or the direct cluster endpoint token.
 
 Example:
 loft token
+loft token --project my-project --space my-space
+loft token --project my-project --virtual-cluster my-vcluster
+loft token --cluster my-cluster --direct-cluster-endpoint
 #######################################################
 	`
 	if upgrade.IsPlugin == "true" {
@@ -67,93 +79,126 @@ devspace token
 	}
 
 	tokenCmd.Flags().BoolVar(&cmd.DirectClusterEndpoint, "direct-cluster-endpoint", false, "When enabled prints a direct cluster endpoint token")
-	tokenCmd.Flags().StringVar(&cmd.Project, "project", "", "The project containing the virtual cluster")
-	tokenCmd.Flags().StringVar(&cmd.VirtualCluster, "virtual-cluster", "", "The virtual cluster")
+	tokenCmd.Flags().StringVar(&cmd.Project, "project", "", "The project containing the space or virtual cluster")
+	tokenCmd.Flags().StringVar(&cmd.VirtualCluster, "virtual-cluster", "", "The virtual cluster with an access point to print a certificate for")
+	tokenCmd.Flags().StringVar(&cmd.Space, "space", "", "The space to print a token for")
+	tokenCmd.Flags().StringVar(&cmd.Cluster, "cluster", "", "The cluster to print a token for")
 	return tokenCmd
 }
 
 // Run executes the command
 func (cmd *TokenCmd) Run() error {
-	baseClient, err := client.NewClientFromPath(cmd.Config)
+	err := cmd.validateTarget()
 	if err != nil {
 		return err
 	}
 
-	tokenFunc := getToken
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
 
-	if cmd.Project != "" && cmd.VirtualCluster != "" {
-		cmd.log.Debug("project and virtual cluster set, attempting fetch virtual cluster certificate data")
-		tokenFunc = getCertificate
+	credential, err := cmd.execCredential(baseClient)
+	if err != nil {
+		return err
 	}
 
-	return tokenFunc(cmd, baseClient)
+	return printExecCredential(credential)
 }
 
-func getToken(cmd *TokenCmd, baseClient client.Client) error {
-	// get config
-	config := baseClient.Config()
-	if config == nil {
-		return errors.New("no config loaded")
-	} else if config.Host == "" || config.AccessKey == "" {
-		return errors.New("not logged in, please make sure you have run 'loft login [loft-url]'")
+// validateTarget makes sure at most one target is given and that the project is set for spaces
+// and virtual clusters
+func (cmd *TokenCmd) validateTarget() error {
+	targets := 0
+	for _, target := range []string{cmd.VirtualCluster, cmd.Space, cmd.Cluster} {
+		if target != "" {
+			targets++
+		}
 	}
 
-	// by default we print the access key as token
-	token := config.AccessKey
+	if targets > 1 {
+		return errors.New("only one of --virtual-cluster, --space and --cluster can be used")
+	} else if (cmd.VirtualCluster != "" || cmd.Space != "") && cmd.Project == "" {
+		return errors.New("--project is required for --virtual-cluster and --space")
+	} else if cmd.VirtualCluster != "" && cmd.DirectClusterEndpoint {
+		return errors.New("--direct-cluster-endpoint can't be used with --virtual-cluster")
+	}
 
-	// check if we should print a cluster gateway token instead
-	if cmd.DirectClusterEndpoint {
-		var err error
-		token, err = baseClient.DirectClusterEndpointToken(false)
+	return nil
+}
+
+// execCredential returns the credential for the target. Virtual clusters with an access point
+// get a client certificate, all other targets use the token of the loft instance after checking
+// that the target exists and can be accessed.
+func (cmd *TokenCmd) execCredential(baseClient client.Client) (*v1beta1.ExecCredentialStatus, error) {
+	switch {
+	case cmd.VirtualCluster != "":
+		cmd.log.Debug("project and virtual cluster set, attempting fetch virtual cluster certificate data")
+		certificateData, keyData, err := baseClient.VirtualClusterAccessPointCertificate(cmd.Project, cmd.VirtualCluster, false)
 		if err != nil {
-			return err
+			return nil, err
 		}
-	}
 
-	return printToken(token)
-}
+		return &v1beta1.ExecCredentialStatus{
+			ClientCertificateData: certificateData,
+			ClientKeyData:         keyData,
+		}, nil
+	case cmd.Space != "":
+		managementClient, err := baseClient.Management()
+		if err != nil {
+			return nil, err
+		}
 
-func printToken(token string) error {
-	// Print token to stdout
-	response := &v1beta1.ExecCredential{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "ExecCredential",
-			APIVersion: v1beta1.SchemeGroupVersion.String(),
-		},
-		Status: &v1beta1.ExecCredentialStatus{
-			Token: token,
-		},
+		_, err = managementClient.Loft().ManagementV1().SpaceInstances(naming.ProjectNamespace(cmd.Project)).Get(context.TODO(), cmd.Space, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get space %s in project %s", cmd.Space, cmd.Project)
+		}
+	case cmd.Cluster != "":
+		managementClient, err := baseClient.Management()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = managementClient.Loft().ManagementV1().Clusters().Get(context.TODO(), cmd.Cluster, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get cluster %s", cmd.Cluster)
+		}
 	}
 
-	bytes, err := json.Marshal(response)
+	token, err := cmd.token(baseClient)
 	if err != nil {
-		return errors.Wrap(err, "json marshal")
+		return nil, err
 	}
 
-	_, err = os.Stdout.Write(bytes)
-	return err
+	return &v1beta1.ExecCredentialStatus{Token: token}, nil
 }
 
-func getCertificate(cmd *TokenCmd, baseClient client.Client) error {
-	certificateData, keyData, err := baseClient.VirtualClusterAccessPointCertificate(cmd.Project, cmd.VirtualCluster, false)
-	if err != nil {
-		return err
+func (cmd *TokenCmd) token(baseClient client.Client) (string, error) {
+	// get config
+	config := baseClient.Config()
+	if config == nil {
+		return "", errors.New("no config loaded")
+	} else if config.Host == "" || config.AccessKey == "" {
+		return "", errors.New("not logged in, please make sure you have run 'loft login [loft-url]'")
+	}
+
+	// check if we should print a cluster gateway token instead
+	if cmd.DirectClusterEndpoint {
+		return baseClient.DirectClusterEndpointToken(false)
 	}
 
-	return printCertificate(certificateData, keyData)
+	// by default we print the access key as token
+	return config.AccessKey, nil
 }
 
-func printCertificate(certificateData, keyData string) error {
-	// Print certificate-based exec credential to stdout
+func printExecCredential(status *v1beta1.ExecCredentialStatus) error {
+	// Print exec credential to stdout
 	response := &v1beta1.ExecCredential{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ExecCredential",
 			APIVersion: v1beta1.SchemeGroupVersion.String(),
 		},
-		Status: &v1beta1.ExecCredentialStatus{
-			ClientCertificateData: certificateData,
-			ClientKeyData:         keyData,
-		},
+		Status: status,
 	}
 
 	bytes, err := json.Marshal(response)
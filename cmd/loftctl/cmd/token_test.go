@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"testing"
+
+	agentloftclient "github.com/loft-sh/agentapi/v3/pkg/client/loft/clientset_generated/clientset"
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	loftclient "github.com/loft-sh/api/v3/pkg/client/clientset_generated/clientset"
+	loftfake "github.com/loft-sh/api/v3/pkg/client/clientset_generated/clientset/fake"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/kube"
+	"github.com/loft-sh/log"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+)
+
+type fakeKube struct {
+	*fake.Clientset
+	loftClient *loftfake.Clientset
+}
+
+func (f *fakeKube) Loft() loftclient.Interface {
+	return f.loftClient
+}
+
+func (f *fakeKube) Agent() agentloftclient.Interface {
+	return nil
+}
+
+// fakeTokenClient returns fixed credentials and a management client with the given objects
+type fakeTokenClient struct {
+	client.Client
+
+	config     *client.Config
+	management kube.Interface
+}
+
+func (f *fakeTokenClient) Config() *client.Config {
+	return f.config
+}
+
+func (f *fakeTokenClient) Management() (kube.Interface, error) {
+	return f.management, nil
+}
+
+func (f *fakeTokenClient) DirectClusterEndpointToken(forceRefresh bool) (string, error) {
+	return "direct-token", nil
+}
+
+func (f *fakeTokenClient) VirtualClusterAccessPointCertificate(project, virtualCluster string, forceRefresh bool) (string, string, error) {
+	return "cert-" + project + "-" + virtualCluster, "key-" + project + "-" + virtualCluster, nil
+}
+
+func TestTokenTargets(t *testing.T) {
+	baseClient := &fakeTokenClient{
+		config: &client.Config{Host: "https://loft.example.com", AccessKey: "access-key"},
+		management: &fakeKube{
+			Clientset: fake.NewSimpleClientset(),
+			loftClient: loftfake.NewSimpleClientset(
+				&managementv1.SpaceInstance{ObjectMeta: metav1.ObjectMeta{Name: "my-space", Namespace: "loft-p-my-project"}},
+				&managementv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"}},
+			),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		cmd           TokenCmd
+		expected      *v1beta1.ExecCredentialStatus
+		expectedError string
+	}{
+		{
+			name:     "access key",
+			expected: &v1beta1.ExecCredentialStatus{Token: "access-key"},
+		},
+		{
+			name:     "direct cluster endpoint",
+			cmd:      TokenCmd{DirectClusterEndpoint: true},
+			expected: &v1beta1.ExecCredentialStatus{Token: "direct-token"},
+		},
+		{
+			name:     "virtual cluster",
+			cmd:      TokenCmd{Project: "my-project", VirtualCluster: "my-vcluster"},
+			expected: &v1beta1.ExecCredentialStatus{ClientCertificateData: "cert-my-project-my-vcluster", ClientKeyData: "key-my-project-my-vcluster"},
+		},
+		{
+			name:     "space",
+			cmd:      TokenCmd{Project: "my-project", Space: "my-space"},
+			expected: &v1beta1.ExecCredentialStatus{Token: "access-key"},
+		},
+		{
+			name:     "space with direct cluster endpoint",
+			cmd:      TokenCmd{Project: "my-project", Space: "my-space", DirectClusterEndpoint: true},
+			expected: &v1beta1.ExecCredentialStatus{Token: "direct-token"},
+		},
+		{
+			name:          "missing space",
+			cmd:           TokenCmd{Project: "other-project", Space: "my-space"},
+			expectedError: "get space my-space in project other-project",
+		},
+		{
+			name:     "cluster",
+			cmd:      TokenCmd{Cluster: "my-cluster", DirectClusterEndpoint: true},
+			expected: &v1beta1.ExecCredentialStatus{Token: "direct-token"},
+		},
+		{
+			name:          "missing cluster",
+			cmd:           TokenCmd{Cluster: "other-cluster"},
+			expectedError: "get cluster other-cluster",
+		},
+		{
+			name:          "space without project",
+			cmd:           TokenCmd{Space: "my-space"},
+			expectedError: "--project is required",
+		},
+		{
+			name:          "virtual cluster without project",
+			cmd:           TokenCmd{VirtualCluster: "my-vcluster"},
+			expectedError: "--project is required",
+		},
+		{
+			name:          "multiple targets",
+			cmd:           TokenCmd{Project: "my-project", Space: "my-space", Cluster: "my-cluster"},
+			expectedError: "only one of",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cmd := testCase.cmd
+			cmd.log = log.Discard
+
+			err := cmd.validateTarget()
+			var credential *v1beta1.ExecCredentialStatus
+			if err == nil {
+				credential, err = cmd.execCredential(baseClient)
+			}
+			if testCase.expectedError != "" {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+
+			assert.NilError(t, err)
+			assert.DeepEqual(t, credential, testCase.expected)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/uninstall"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/survey"
+	"github.com/loft-sh/log/table"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// UninstallCmd holds the cmd flags
+type UninstallCmd struct {
+	*flags.GlobalFlags
+
+	Context   string
+	Namespace string
+	DryRun    bool
+	KeepCRDs  bool
+	KeepData  bool
+	Yes       bool
+	Timeout   time.Duration
+
+	Log log.Logger
+}
+
+// NewUninstallCmd creates a new command
+func NewUninstallCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &UninstallCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+#################### loft uninstall ###################
+#######################################################
+Uninstall removes Loft from the cluster of the kube
+context and the Loft agent from all connected clusters.
+
+Before anything is removed, everything that would be
+removed is listed: the helm releases of Loft and its
+agents, the leftovers of Loft and its agent, the Loft
+custom resources and
+their definitions and the loft-p-* project namespaces.
+Afterwards uninstall waits until everything is gone
+and lists what is left. Namespaces and custom resource
+definitions that are still being deleted when the
+--timeout expires are listed separately.
+
+Use --keep-data to keep the custom resources and the
+project namespaces, e.g. to reinstall Loft later.
+
+Example:
+loft uninstall --dry-run
+loft uninstall --keep-data
+#######################################################
+	`
+
+	c := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall loft",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(cobraCmd.Context())
+		},
+	}
+
+	c.Flags().StringVar(&cmd.Context, "context", "", "The kube context loft was installed with")
+	c.Flags().StringVar(&cmd.Namespace, "namespace", "loft", "The namespace loft was installed into")
+	c.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "If enabled, only lists what would be removed")
+	c.Flags().BoolVar(&cmd.KeepCRDs, "keep-crds", false, "If enabled, keeps the custom resource definitions of loft")
+	c.Flags().BoolVar(&cmd.KeepData, "keep-data", false, "If enabled, keeps the custom resources of loft, the project namespaces and the admin secret. Implies --keep-crds")
+	c.Flags().BoolVarP(&cmd.Yes, "yes", "y", false, "If enabled, doesn't ask for confirmation")
+	c.Flags().DurationVar(&cmd.Timeout, "timeout", 2*time.Minute, "How long to wait for the removed objects to be gone")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *UninstallCmd) Run(ctx context.Context) error {
+	// helm needs the context explicitly
+	kubeClientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{CurrentContext: cmd.Context})
+	rawConfig, err := kubeClientConfig.RawConfig()
+	if err != nil {
+		return fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	} else if cmd.Context == "" {
+		cmd.Context = rawConfig.CurrentContext
+	}
+
+	kubeConfig, err := kubeClientConfig.ClientConfig()
+	if err != nil {
+		return fmt.Errorf("there is an error loading your current kube config (%w), please make sure you have access to a kubernetes cluster and the command `kubectl get namespaces` is working", err)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	uninstaller := uninstall.NewUninstaller(kubeClient, dynamicClient, cmd.Context, cmd.Namespace)
+	plan, err := uninstaller.Plan(ctx, uninstall.Options{KeepCRDs: cmd.KeepCRDs, KeepData: cmd.KeepData})
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range plan.Warnings {
+		cmd.Log.Warnf("Skipping the agent leftovers of connected %s", warning)
+	}
+	if plan.Release == "" && len(plan.AgentReleases) == 0 && len(plan.Resources) == 0 {
+		cmd.Log.Donef("Nothing to remove, seems like loft isn't installed into namespace %s", cmd.Namespace)
+		return nil
+	}
+
+	cmd.printPlan(plan)
+	if cmd.DryRun {
+		return nil
+	}
+
+	if !cmd.Yes {
+		answer, err := cmd.Log.Question(&survey.QuestionOptions{
+			Question:     "Do you want to remove all of the above?",
+			DefaultValue: "No",
+			Options:      []string{"Yes", "No"},
+		})
+		if err != nil {
+			return err
+		} else if answer != "Yes" {
+			return errors.New("uninstall canceled")
+		}
+	}
+
+	failed := uninstaller.Apply(ctx, plan, cmd.Log)
+	cmd.Log.Info("Waiting for the objects to be removed...")
+	verification := uninstaller.Verify(ctx, plan, cmd.Timeout)
+	if len(verification.Terminating) > 0 {
+		values := [][]string{}
+		for _, resource := range verification.Terminating {
+			values = append(values, []string{clusterName(resource.Cluster), resource.Kind(), resource.Namespace, resource.Name})
+		}
+
+		cmd.Log.WriteString(logrus.InfoLevel, "\n")
+		cmd.Log.Infof("%d objects are still being deleted:", len(verification.Terminating))
+		table.PrintTable(cmd.Log, []string{"Cluster", "Kind", "Namespace", "Name"}, values)
+	}
+	if verification.Release {
+		cmd.Log.Warnf("The helm release %s in namespace %s still exists", plan.Release, cmd.Namespace)
+	}
+	for _, agentRelease := range verification.AgentReleases {
+		cmd.Log.Warnf("The helm release %s in namespace %s of cluster %s still exists", agentRelease.Release, agentRelease.Namespace, agentRelease.Cluster)
+	}
+	if len(verification.Leftovers) > 0 {
+		values := [][]string{}
+		for _, leftover := range verification.Leftovers {
+			values = append(values, []string{clusterName(leftover.Cluster), leftover.Kind(), leftover.Namespace, leftover.Name, leftover.Reason})
+		}
+
+		cmd.Log.WriteString(logrus.InfoLevel, "\n")
+		cmd.Log.Warnf("%d objects are left:", len(verification.Leftovers))
+		table.PrintTable(cmd.Log, []string{"Cluster", "Kind", "Namespace", "Name", "Reason"}, values)
+	}
+	if verification.LookupError != nil {
+		cmd.Log.Warnf("Error looking for objects of loft that are left: %v", verification.LookupError)
+	}
+	if verification.Release || len(verification.AgentReleases) > 0 || len(verification.Leftovers) > 0 || verification.LookupError != nil {
+		return fmt.Errorf("loft wasn't uninstalled completely, %d objects couldn't be removed", len(verification.Leftovers))
+	} else if failed > 0 {
+		return fmt.Errorf("loft was uninstalled, but %d errors occurred", failed)
+	}
+
+	cmd.Log.Donef("Successfully uninstalled Loft")
+	return nil
+}
+
+func (cmd *UninstallCmd) printPlan(plan *uninstall.Plan) {
+	if plan.Release != "" {
+		cmd.Log.Infof("The helm release %s in namespace %s will be uninstalled", ansi.Color(plan.Release, "white+b"), cmd.Namespace)
+	}
+	for _, agentRelease := range plan.AgentReleases {
+		cmd.Log.Infof("The helm release %s in namespace %s of cluster %s will be uninstalled", ansi.Color(agentRelease.Release, "white+b"), agentRelease.Namespace, agentRelease.Cluster)
+	}
+	if len(plan.Resources) == 0 {
+		return
+	}
+
+	values := [][]string{}
+	data := 0
+	for _, resource := range plan.Resources {
+		if resource.Data {
+			data++
+		}
+
+		values = append(values, []string{clusterName(resource.Cluster), resource.Kind(), resource.Namespace, resource.Name})
+	}
+
+	cmd.Log.Infof("The following %d objects will be removed, %d of them hold loft data:", len(plan.Resources), data)
+	table.PrintTable(cmd.Log, []string{"Cluster", "Kind", "Namespace", "Name"}, values)
+}
+
+// clusterName returns the name of a connected cluster or a placeholder for the cluster loft runs in
+func clusterName(cluster string) string {
+	if cluster == "" {
+		return "(loft)"
+	}
+
+	return cluster
+}
@@ -0,0 +1,35 @@
+package upgradetemplate
+
+import (
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// NewUpgradeTemplateCmd creates a new cobra command
+func NewUpgradeTemplateCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	description := `
+#######################################################
+################ loft upgrade-template ################
+#######################################################
+Moves instances to another version of their template
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############## devspace upgrade-template ##############
+#######################################################
+Moves instances to another version of their template
+	`
+	}
+	cmd := &cobra.Command{
+		Use:   "upgrade-template",
+		Short: "Upgrades the template version of an instance",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewVClusterCmd(globalFlags, defaults))
+	return cmd
+}
@@ -0,0 +1,237 @@
+package upgradetemplate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	"github.com/loft-sh/loftctl/v3/pkg/client/naming"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/parameters"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/loftctl/v3/pkg/util"
+	"github.com/loft-sh/loftctl/v3/pkg/vcluster"
+	"github.com/loft-sh/loftctl/v3/pkg/version"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	client2 "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VClusterCmd holds the cmd flags
+type VClusterCmd struct {
+	*flags.GlobalFlags
+
+	Project    string
+	To         string
+	Parameters parameters.Sources
+	SkipWait   bool
+
+	Log log.Logger
+}
+
+// NewVClusterCmd creates a new command
+func NewVClusterCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &VClusterCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+############ loft upgrade-template vcluster ###########
+#######################################################
+Moves a virtual cluster to another version of its
+template. Parameter values that are still valid in the
+new version are carried over, new required parameters
+can be specified via --set or --parameters or are asked
+interactively.
+
+Example:
+loft upgrade-template vcluster myvcluster
+loft upgrade-template vcluster myvcluster --project myproject --to 1.x.x
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+########## devspace upgrade-template vcluster #########
+#######################################################
+Moves a virtual cluster to another version of its
+template. Parameter values that are still valid in the
+new version are carried over, new required parameters
+can be specified via --set or --parameters or are asked
+interactively.
+
+Example:
+devspace upgrade-template vcluster myvcluster
+devspace upgrade-template vcluster myvcluster --project myproject --to 1.x.x
+#######################################################
+	`
+	}
+
+	c := &cobra.Command{
+		Use:   "vcluster" + util.VClusterNameOnlyUseLine,
+		Short: "Upgrades the template version of a virtual cluster",
+		Long:  description,
+		Args:  util.VClusterNameOnlyValidator,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			// Check for newer version
+			upgrade.PrintNewerVersionWarning()
+
+			return cmd.Run(args)
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project to use")
+	c.Flags().StringVar(&cmd.To, "to", "", "The template version to upgrade to, e.g. 1.2.0 or 1.x.x. Defaults to the latest version")
+	cmd.Parameters.AddFlags(c.Flags())
+	c.Flags().BoolVar(&cmd.SkipWait, "skip-wait", false, "If true, will not wait until the virtual cluster is running")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *VClusterCmd) Run(args []string) error {
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	err = client.VerifyVersion(baseClient)
+	if err != nil {
+		return err
+	}
+
+	vClusterName := args[0]
+	_, cmd.Project, _, vClusterName, err = helper.SelectVirtualClusterInstanceOrVirtualCluster(baseClient, vClusterName, "", cmd.Project, "", cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Project == "" {
+		return fmt.Errorf("couldn't find a vcluster you have access to")
+	}
+
+	return cmd.upgradeVCluster(baseClient, vClusterName)
+}
+
+func (cmd *VClusterCmd) upgradeVCluster(baseClient client.Client, vClusterName string) error {
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return err
+	}
+
+	virtualClusterInstance, err := managementClient.Loft().ManagementV1().VirtualClusterInstances(naming.ProjectNamespace(cmd.Project)).Get(context.TODO(), vClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	} else if virtualClusterInstance.Spec.TemplateRef == nil {
+		return fmt.Errorf("virtual cluster instance doesn't use a template, cannot upgrade virtual cluster")
+	} else if virtualClusterInstance.Spec.TemplateRef.Version == "" {
+		return fmt.Errorf("virtual cluster %s is not pinned to a template version and always uses the latest version of template %s", vClusterName, virtualClusterInstance.Spec.TemplateRef.Name)
+	}
+
+	virtualClusterTemplate, err := helper.SelectVirtualClusterTemplate(baseClient, cmd.Project, virtualClusterInstance.Spec.TemplateRef.Name, cmd.Log)
+	if err != nil {
+		return err
+	} else if len(virtualClusterTemplate.Spec.Versions) == 0 {
+		return fmt.Errorf("template %s doesn't have any versions", virtualClusterTemplate.Name)
+	}
+
+	availableVersions := []string{}
+	for _, v := range virtualClusterTemplate.GetVersions() {
+		availableVersions = append(availableVersions, v.GetVersion())
+	}
+	cmd.Log.Infof("Available versions of template %s: %s", ansi.Color(virtualClusterTemplate.Name, "white+b"), strings.Join(availableVersions, ", "))
+
+	// resolve current and target version
+	_, currentVersion, err := version.GetLatestMatchedVersion(virtualClusterTemplate, virtualClusterInstance.Spec.TemplateRef.Version)
+	if err != nil {
+		return err
+	} else if currentVersion == nil {
+		return fmt.Errorf("couldn't find any matching version to %s", virtualClusterInstance.Spec.TemplateRef.Version)
+	}
+
+	targetPattern := cmd.To
+	if targetPattern == "" {
+		targetPattern = "x.x.x"
+	}
+	_, targetVersion, err := version.GetLatestMatchedVersion(virtualClusterTemplate, targetPattern)
+	if err != nil {
+		return err
+	} else if targetVersion == nil {
+		return fmt.Errorf("couldn't find any matching version to %s", targetPattern)
+	}
+
+	newVersion := cmd.To
+	if newVersion == "" {
+		newVersion = targetVersion.GetVersion()
+	}
+	if currentVersion.GetVersion() == targetVersion.GetVersion() && virtualClusterInstance.Spec.TemplateRef.Version == newVersion && cmd.Parameters.Empty() {
+		cmd.Log.Donef("Virtual cluster %s already uses version %s of template %s", ansi.Color(vClusterName, "white+b"), ansi.Color(currentVersion.GetVersion(), "white+b"), virtualClusterTemplate.Name)
+		return nil
+	}
+
+	// show parameter changes
+	cmd.Log.Infof("Upgrading virtual cluster %s from version %s to %s", ansi.Color(vClusterName, "white+b"), ansi.Color(currentVersion.GetVersion(), "white+b"), ansi.Color(targetVersion.GetVersion(), "white+b"))
+	currentParameters := currentVersion.(*storagev1.VirtualClusterTemplateVersion).Parameters
+	targetParameters := targetVersion.(*storagev1.VirtualClusterTemplateVersion).Parameters
+	printParameterDiff(parameters.DiffParameters(currentParameters, targetParameters), cmd.Log)
+
+	// migrate parameter values
+	resolvedParameters, err := parameters.MigrateParameters(virtualClusterInstance.Spec.Parameters, targetParameters, &cmd.Parameters, parameters.NewProjectSecrets(managementClient, cmd.Project), cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	// update virtual cluster instance
+	oldVirtualCluster := virtualClusterInstance.DeepCopy()
+	virtualClusterInstance.Spec.TemplateRef.Version = newVersion
+	virtualClusterInstance.Spec.Parameters = resolvedParameters
+
+	patch := client2.MergeFrom(oldVirtualCluster)
+	patchData, err := patch.Data(virtualClusterInstance)
+	if err != nil {
+		return errors.Wrap(err, "calculate update patch")
+	}
+	virtualClusterInstance, err = managementClient.Loft().ManagementV1().VirtualClusterInstances(virtualClusterInstance.Namespace).Patch(context.TODO(), virtualClusterInstance.Name, patch.Type(), patchData, metav1.PatchOptions{})
+	if err != nil {
+		return errors.Wrap(err, "patch virtual cluster")
+	}
+
+	// wait until virtual cluster is ready
+	_, err = vcluster.WaitForVirtualClusterInstance(context.TODO(), managementClient, virtualClusterInstance.Namespace, virtualClusterInstance.Name, !cmd.SkipWait, cmd.Log)
+	if err != nil {
+		return err
+	}
+
+	cmd.Log.Donef("Successfully upgraded virtual cluster %s in project %s to template version %s", ansi.Color(vClusterName, "white+b"), ansi.Color(cmd.Project, "white+b"), ansi.Color(targetVersion.GetVersion(), "white+b"))
+	return nil
+}
+
+func printParameterDiff(diff parameters.ParameterDiff, log log.Logger) {
+	if diff.Empty() {
+		log.Info("Template parameters didn't change")
+		return
+	}
+
+	for _, parameter := range diff.Added {
+		if parameter.Required {
+			log.Infof("  + %s (added, required)", parameter.Variable)
+		} else {
+			log.Infof("  + %s (added)", parameter.Variable)
+		}
+	}
+	for _, parameter := range diff.Removed {
+		log.Infof("  - %s (removed)", parameter.Variable)
+	}
+	for _, change := range diff.Changed {
+		log.Infof("  ~ %s (changed)", change.New.Variable)
+	}
+}
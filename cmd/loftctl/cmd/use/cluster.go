@@ -178,6 +178,15 @@ func CreateClusterContextOptions(baseClient client.Client, config string, cluste
 		ConfigPath:       config,
 		CurrentNamespace: spaceName,
 		SetActive:        setActive,
+		Metadata: kubeconfig.ContextMetadata{
+			Host:      baseClient.Config().Host,
+			Kind:      kubeconfig.ContextKindCluster,
+			Cluster:   cluster.Name,
+			Namespace: spaceName,
+		},
+	}
+	if spaceName != "" {
+		contextOptions.Metadata.Kind = kubeconfig.ContextKindSpace
 	}
 	if !disableClusterGateway && cluster.Annotations != nil && cluster.Annotations[LoftDirectClusterEndpoint] != "" {
 		contextOptions = ApplyDirectClusterEndpointOptions(contextOptions, cluster, "/kubernetes/cluster", log)
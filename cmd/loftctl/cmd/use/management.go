@@ -105,6 +105,10 @@ func CreateManagementContextOptions(baseClient client.Client, config string, set
 		Name:       kubeconfig.ManagementContextName(),
 		ConfigPath: config,
 		SetActive:  setActive,
+		Metadata: kubeconfig.ContextMetadata{
+			Host: baseClient.Config().Host,
+			Kind: kubeconfig.ContextKindManagement,
+		},
 	}
 
 	contextOptions.Server = baseClient.Config().Host + "/kubernetes/management"
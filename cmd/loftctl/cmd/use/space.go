@@ -212,6 +212,14 @@ func CreateSpaceInstanceOptions(baseClient client.Client, config string, project
 		ConfigPath:       config,
 		CurrentNamespace: spaceInstance.Spec.ClusterRef.Namespace,
 		SetActive:        setActive,
+		Metadata: kubeconfig.ContextMetadata{
+			Host:      baseClient.Config().Host,
+			Kind:      kubeconfig.ContextKindSpaceInstance,
+			Project:   projectName,
+			Name:      spaceInstance.Name,
+			Cluster:   spaceInstance.Spec.ClusterRef.Cluster,
+			Namespace: spaceInstance.Spec.ClusterRef.Namespace,
+		},
 	}
 	if !disableClusterGateway && cluster.Annotations != nil && cluster.Annotations[LoftDirectClusterEndpoint] != "" {
 		contextOptions = ApplyDirectClusterEndpointOptions(contextOptions, cluster, "/kubernetes/project/"+projectName+"/space/"+spaceInstance.Name, log)
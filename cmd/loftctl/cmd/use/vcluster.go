@@ -179,6 +179,14 @@ func CreateVirtualClusterInstanceOptions(baseClient client.Client, config string
 		Name:       kubeconfig.VirtualClusterInstanceContextName(projectName, virtualClusterInstance.Name),
 		ConfigPath: config,
 		SetActive:  setActive,
+		Metadata: kubeconfig.ContextMetadata{
+			Host:      baseClient.Config().Host,
+			Kind:      kubeconfig.ContextKindVirtualClusterInstance,
+			Project:   projectName,
+			Name:      virtualClusterInstance.Name,
+			Cluster:   virtualClusterInstance.Spec.ClusterRef.Cluster,
+			Namespace: virtualClusterInstance.Spec.ClusterRef.Namespace,
+		},
 	}
 	if virtualClusterInstance.Status.VirtualCluster != nil && virtualClusterInstance.Status.VirtualCluster.AccessPoint.Ingress.Enabled {
 		kubeConfig, err := getVirtualClusterInstanceAccessConfig(baseClient, virtualClusterInstance)
@@ -269,6 +277,13 @@ func CreateVClusterContextOptions(baseClient client.Client, config string, clust
 		Name:       kubeconfig.VirtualClusterContextName(cluster.Name, spaceName, virtualClusterName),
 		ConfigPath: config,
 		SetActive:  setActive,
+		Metadata: kubeconfig.ContextMetadata{
+			Host:      baseClient.Config().Host,
+			Kind:      kubeconfig.ContextKindVirtualCluster,
+			Name:      virtualClusterName,
+			Cluster:   cluster.Name,
+			Namespace: spaceName,
+		},
 	}
 	if !disableClusterGateway && cluster.Annotations != nil && cluster.Annotations[LoftDirectClusterEndpoint] != "" {
 		contextOptions = ApplyDirectClusterEndpointOptions(contextOptions, cluster, "/kubernetes/virtualcluster/"+spaceName+"/"+virtualClusterName, log)
@@ -0,0 +1,135 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/parameters"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/loft-sh/log"
+	"github.com/mgutz/ansi"
+	"github.com/spf13/cobra"
+)
+
+// ParametersCmd holds the cmd flags
+type ParametersCmd struct {
+	*flags.GlobalFlags
+
+	Project  string
+	Template string
+	Version  string
+	Kind     string
+	Sources  parameters.Sources
+
+	Log log.Logger
+}
+
+// NewParametersCmd creates a new command
+func NewParametersCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	cmd := &ParametersCmd{
+		GlobalFlags: globalFlags,
+		Log:         log.GetInstance(),
+	}
+
+	description := `
+#######################################################
+############### loft validate parameters ##############
+#######################################################
+Checks parameter values against the parameters of a
+template version without creating anything. All invalid
+or unknown values are reported at once.
+
+Example:
+loft validate parameters -f params.yaml --template my-template --project myproject
+loft validate parameters -f params.yaml --template my-template --version 1.x.x --project myproject
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+############# devspace validate parameters ############
+#######################################################
+Checks parameter values against the parameters of a
+template version without creating anything. All invalid
+or unknown values are reported at once.
+
+Example:
+devspace validate parameters -f params.yaml --template my-template --project myproject
+devspace validate parameters -f params.yaml --template my-template --version 1.x.x --project myproject
+#######################################################
+	`
+	}
+
+	c := &cobra.Command{
+		Use:   "parameters",
+		Short: "Validates parameter values against a template",
+		Long:  description,
+		Args:  cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run()
+		},
+	}
+
+	p, _ := defaults.Get(pdefaults.KeyProject, "")
+	c.Flags().StringVarP(&cmd.Project, "project", "p", p, "The project the template is allowed in")
+	c.Flags().StringVar(&cmd.Template, "template", "", "The template to validate the parameters against")
+	c.Flags().StringVar(&cmd.Version, "version", "", "The template version to validate against, e.g. 1.2.0 or 1.x.x. Defaults to the latest version")
+	c.Flags().StringVar(&cmd.Kind, "kind", "", "The kind of the template, one of: vcluster, space, devpod. Only needed if the template name is ambiguous")
+	c.Flags().StringArrayVarP(&cmd.Sources.Files, "file", "f", []string{}, parameters.ParametersFileUsage)
+	c.Flags().StringSliceVar(&cmd.Sources.Set, "set", []string{}, parameters.SetUsage)
+	c.Flags().StringArrayVar(&cmd.Sources.SetFile, "set-file", []string{}, parameters.SetFileUsage)
+	c.Flags().StringArrayVar(&cmd.Sources.SetJSON, "set-json", []string{}, parameters.SetJSONUsage)
+	_ = c.MarkFlagRequired("template")
+	return c
+}
+
+// Run executes the functionality
+func (cmd *ParametersCmd) Run() error {
+	if cmd.Project == "" {
+		return fmt.Errorf("please specify a project via --project")
+	}
+
+	baseClient, err := client.NewClientFromPath(cmd.Config)
+	if err != nil {
+		return err
+	}
+
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return err
+	}
+
+	template, err := helper.FindProjectTemplate(context.TODO(), managementClient, cmd.Project, cmd.Kind, cmd.Template)
+	if err != nil {
+		return err
+	}
+
+	templateParameters, resolvedVersion, err := template.GetParameters(cmd.Version)
+	if err != nil {
+		return err
+	}
+
+	validationErrors, err := parameters.ValidateParameters(templateParameters, &cmd.Sources, parameters.NewProjectSecrets(managementClient, cmd.Project))
+	if err != nil {
+		return err
+	}
+
+	name := ansi.Color(template.Name, "white+b")
+	if resolvedVersion != "" {
+		name += " version " + ansi.Color(resolvedVersion, "white+b")
+	}
+	if len(validationErrors) > 0 {
+		for _, validationErr := range validationErrors {
+			cmd.Log.Error(validationErr.Error())
+		}
+
+		return fmt.Errorf("found %d invalid parameter value(s) for template %s", len(validationErrors), template.Name)
+	}
+
+	cmd.Log.Donef("Parameters are valid for %s template %s", template.Kind, name)
+	return nil
+}
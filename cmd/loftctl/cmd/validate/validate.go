@@ -0,0 +1,33 @@
+package validate
+
+import (
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	pdefaults "github.com/loft-sh/loftctl/v3/pkg/defaults"
+	"github.com/loft-sh/loftctl/v3/pkg/upgrade"
+	"github.com/spf13/cobra"
+)
+
+// NewValidateCmd creates a new cobra command
+func NewValidateCmd(globalFlags *flags.GlobalFlags, defaults *pdefaults.Defaults) *cobra.Command {
+	description := `
+#######################################################
+#################### loft validate ####################
+#######################################################
+	`
+	if upgrade.IsPlugin == "true" {
+		description = `
+#######################################################
+################## devspace validate ##################
+#######################################################
+	`
+	}
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validates configuration without applying it",
+		Long:  description,
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewParametersCmd(globalFlags, defaults))
+	return cmd
+}
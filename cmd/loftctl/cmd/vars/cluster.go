@@ -3,11 +3,9 @@ package vars
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 type clusterCmd struct {
@@ -30,29 +28,13 @@ func newClusterCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
 // Run executes the command logic
 func (*clusterCmd) Run(cobraCmd *cobra.Command, args []string) error {
 	retError := fmt.Errorf("Current context is not a loft context, but predefined var LOFT_CLUSTER is used.")
-	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+	metadata, ok, err := currentContextMetadata()
 	if err != nil {
 		return err
-	}
-
-	kubeContext := os.Getenv("DEVSPACE_PLUGIN_KUBE_CONTEXT_FLAG")
-	if kubeContext == "" {
-		kubeContext = kubeConfig.CurrentContext
-	}
-
-	cluster, ok := kubeConfig.Clusters[kubeContext]
-	if !ok {
-		return retError
-	}
-
-	server := strings.TrimSuffix(cluster.Server, "/")
-	splitted := strings.Split(server, "/")
-	if len(splitted) < 3 {
-		return retError
-	} else if splitted[len(splitted)-2] != "cluster" || splitted[len(splitted)-3] != "kubernetes" {
+	} else if !ok || metadata.Cluster == "" {
 		return retError
 	}
 
-	_, err = os.Stdout.Write([]byte(splitted[len(splitted)-1]))
+	_, err = os.Stdout.Write([]byte(metadata.Cluster))
 	return err
 }
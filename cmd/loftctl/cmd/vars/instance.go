@@ -0,0 +1,58 @@
+package vars
+
+import (
+	"os"
+
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// currentContextMetadata returns the metadata of the current kube context or of the context
+// devspace was started with
+func currentContextMetadata() (kubeconfig.ContextMetadata, bool, error) {
+	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(kubeconfig.LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return kubeconfig.ContextMetadata{}, false, err
+	}
+
+	kubeContext := os.Getenv("DEVSPACE_PLUGIN_KUBE_CONTEXT_FLAG")
+	if kubeContext == "" {
+		kubeContext = kubeConfig.CurrentContext
+	}
+
+	metadata, ok := kubeconfig.ParseLoftContext(&kubeConfig, kubeContext)
+	return metadata, ok, nil
+}
+
+// currentInstance returns the project, kind and name of the space or virtual cluster instance
+// the current kube context points to. All values are empty if it isn't a project instance context.
+func currentInstance() (string, string, string, error) {
+	metadata, ok, err := currentContextMetadata()
+	if err != nil || !ok {
+		return "", "", "", err
+	} else if metadata.Kind != kubeconfig.ContextKindSpaceInstance && metadata.Kind != kubeconfig.ContextKindVirtualClusterInstance {
+		return "", "", "", nil
+	}
+
+	return metadata.Project, metadata.Kind, metadata.Name, nil
+}
+
+// printInstanceVar prints the environment variable if it is set or otherwise the value
+// retrieved from the current context
+func printInstanceVar(env string, fromContext func(project, kind, name string) string) (bool, error) {
+	value := os.Getenv(env)
+	if value == "" {
+		project, kind, name, err := currentInstance()
+		if err != nil {
+			return false, err
+		}
+
+		value = fromContext(project, kind, name)
+		if value == "" {
+			return false, nil
+		}
+	}
+
+	_, err := os.Stdout.Write([]byte(value))
+	return true, err
+}
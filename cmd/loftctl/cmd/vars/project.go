@@ -0,0 +1,40 @@
+package vars
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/constants"
+	"github.com/spf13/cobra"
+)
+
+type projectCmd struct {
+	*flags.GlobalFlags
+}
+
+func newProjectCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &projectCmd{
+		GlobalFlags: globalFlags,
+	}
+
+	return &cobra.Command{
+		Use:   "project",
+		Short: "Prints the current project",
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+}
+
+// Run executes the command logic
+func (*projectCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	found, err := printInstanceVar(constants.LoftProjectEnv, func(project, kind, name string) string {
+		return project
+	})
+	if err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("Current context is not a loft project context, but predefined var %s is used.", constants.LoftProjectEnv)
+	}
+
+	return nil
+}
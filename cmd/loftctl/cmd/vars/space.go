@@ -0,0 +1,45 @@
+package vars
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/constants"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+)
+
+type spaceCmd struct {
+	*flags.GlobalFlags
+}
+
+func newSpaceCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &spaceCmd{
+		GlobalFlags: globalFlags,
+	}
+
+	return &cobra.Command{
+		Use:   "space",
+		Short: "Prints the current space",
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+}
+
+// Run executes the command logic
+func (*spaceCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	found, err := printInstanceVar(constants.LoftSpaceEnv, func(project, kind, name string) string {
+		if kind != kubeconfig.ContextKindSpaceInstance {
+			return ""
+		}
+
+		return name
+	})
+	if err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("Current context is not a loft space context, but predefined var %s is used.", constants.LoftSpaceEnv)
+	}
+
+	return nil
+}
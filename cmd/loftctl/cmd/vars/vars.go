@@ -20,5 +20,8 @@ func NewVarsCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
 
 	cmd.AddCommand(newUsernameCmd(globalFlags))
 	cmd.AddCommand(newClusterCmd(globalFlags))
+	cmd.AddCommand(newProjectCmd(globalFlags))
+	cmd.AddCommand(newSpaceCmd(globalFlags))
+	cmd.AddCommand(newVirtualClusterCmd(globalFlags))
 	return cmd
 }
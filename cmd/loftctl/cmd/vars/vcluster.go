@@ -0,0 +1,45 @@
+package vars
+
+import (
+	"fmt"
+
+	"github.com/loft-sh/loftctl/v3/cmd/loftctl/flags"
+	"github.com/loft-sh/loftctl/v3/pkg/constants"
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"github.com/spf13/cobra"
+)
+
+type virtualClusterCmd struct {
+	*flags.GlobalFlags
+}
+
+func newVirtualClusterCmd(globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &virtualClusterCmd{
+		GlobalFlags: globalFlags,
+	}
+
+	return &cobra.Command{
+		Use:   "vcluster",
+		Short: "Prints the current virtual cluster",
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+}
+
+// Run executes the command logic
+func (*virtualClusterCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	found, err := printInstanceVar(constants.LoftVirtualClusterEnv, func(project, kind, name string) string {
+		if kind != kubeconfig.ContextKindVirtualClusterInstance {
+			return ""
+		}
+
+		return name
+	})
+	if err != nil {
+		return err
+	} else if !found {
+		return fmt.Errorf("Current context is not a loft virtual cluster context, but predefined var %s is used.", constants.LoftVirtualClusterEnv)
+	}
+
+	return nil
+}
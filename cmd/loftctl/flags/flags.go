@@ -11,6 +11,8 @@ type GlobalFlags struct {
 	Debug     bool
 	Config    string
 	LogOutput string
+
+	KubeConfigTarget string
 }
 
 // SetGlobalFlags applies the global flags
@@ -20,6 +22,7 @@ func SetGlobalFlags(flags *flag.FlagSet) *GlobalFlags {
 	flags.StringVar(&globalFlags.LogOutput, "log-output", "plain", "The log format to use. Can be either plain, raw or json")
 	flags.StringVar(&globalFlags.Config, "config", client.DefaultCacheConfig, "The loft config to use (will be created if it does not exist)")
 	flags.BoolVar(&globalFlags.Debug, "debug", false, "Prints the stack trace if an error occurs")
+	flags.StringVar(&globalFlags.KubeConfigTarget, "kubeconfig-target", "", "The kubeconfig file or directory loft contexts are written to instead of the default kubeconfig. Can also be set via LOFT_KUBECONFIG_TARGET or the kubeconfig-target default")
 	flags.BoolVar(&globalFlags.Silent, "silent", false, "Run in silent mode and prevents any loft log output except panics & fatals")
 
 	return globalFlags
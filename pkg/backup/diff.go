@@ -0,0 +1,203 @@
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ChangeType describes how an object changed
+type ChangeType string
+
+// The types of changes
+const (
+	Added   ChangeType = "Added"
+	Removed ChangeType = "Removed"
+	Changed ChangeType = "Changed"
+)
+
+// Redacted replaces sensitive values in diffs
+const Redacted = "(redacted)"
+
+// sensitiveFields are the fields per kind that contain secrets
+var sensitiveFields = map[string][]string{
+	"Secret":        {"data", "stringData"},
+	"ProjectSecret": {"spec.data"},
+	"AccessKey":     {"spec.key"},
+}
+
+// ObjectKey identifies an object across backups
+type ObjectKey struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// KeyOf returns the key of the object
+func KeyOf(object *unstructured.Unstructured) ObjectKey {
+	gvk := object.GroupVersionKind()
+	return ObjectKey{Group: gvk.Group, Kind: gvk.Kind, Namespace: object.GetNamespace(), Name: object.GetName()}
+}
+
+func (k ObjectKey) String() string {
+	kind := k.Kind
+	if k.Group != "" {
+		kind += "." + k.Group
+	}
+	if k.Namespace != "" {
+		return kind + " " + k.Namespace + "/" + k.Name
+	}
+
+	return kind + " " + k.Name
+}
+
+// Change is an object that was added, removed or changed
+type Change struct {
+	Key  ObjectKey
+	Type ChangeType
+	// Fields are the changed fields of a changed object
+	Fields []FieldChange
+}
+
+// FieldChange is a changed field. Old or New are nil if the field was added or removed.
+type FieldChange struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+	// Sensitive is true if the field contains secrets
+	Sensitive bool
+}
+
+// Format returns the old and the new value of the field. Sensitive values are redacted unless
+// showSecrets is true.
+func (f FieldChange) Format(showSecrets bool) (string, string) {
+	return formatValue(f.Old, f.Sensitive && !showSecrets), formatValue(f.New, f.Sensitive && !showSecrets)
+}
+
+func formatValue(value interface{}, redact bool) string {
+	if value == nil {
+		return "(none)"
+	} else if redact {
+		return Redacted
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(out)
+}
+
+// Diff compares the objects by group, kind, namespace and name. The status and the metadata
+// that isn't backed up are ignored. Changes are sorted by key.
+func Diff(from, to []*unstructured.Unstructured) []Change {
+	fromObjects := normalizedObjects(from)
+	toObjects := normalizedObjects(to)
+
+	changes := []Change{}
+	for key, fromObject := range fromObjects {
+		toObject, ok := toObjects[key]
+		if !ok {
+			changes = append(changes, Change{Key: key, Type: Removed})
+			continue
+		}
+
+		fields := []FieldChange{}
+		diffValues(fromObject.Object, toObject.Object, "", sensitiveFields[key.Kind], &fields)
+		if len(fields) > 0 {
+			changes = append(changes, Change{Key: key, Type: Changed, Fields: fields})
+		}
+	}
+	for key := range toObjects {
+		if _, ok := fromObjects[key]; !ok {
+			changes = append(changes, Change{Key: key, Type: Added})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key.String() < changes[j].Key.String()
+	})
+	return changes
+}
+
+func normalizedObjects(objects []*unstructured.Unstructured) map[ObjectKey]*unstructured.Unstructured {
+	normalized := map[ObjectKey]*unstructured.Unstructured{}
+	for _, object := range objects {
+		object = object.DeepCopy()
+		unstructured.RemoveNestedField(object.Object, "status")
+		StripMetadata(object)
+		normalized[KeyOf(object)] = object
+	}
+
+	return normalized
+}
+
+// diffValues appends the changed fields below the path sorted by path
+func diffValues(from, to interface{}, path string, sensitive []string, fields *[]FieldChange) {
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := []string{}
+		for key := range fromMap {
+			keys = append(keys, key)
+		}
+		for key := range toMap {
+			if _, ok := fromMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			diffValues(fromMap[key], toMap[key], joinPath(path, key), sensitive, fields)
+		}
+		return
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice {
+		for i := 0; i < len(fromSlice) || i < len(toSlice); i++ {
+			var fromValue, toValue interface{}
+			if i < len(fromSlice) {
+				fromValue = fromSlice[i]
+			}
+			if i < len(toSlice) {
+				toValue = toSlice[i]
+			}
+
+			diffValues(fromValue, toValue, fmt.Sprintf("%s[%d]", path, i), sensitive, fields)
+		}
+		return
+	}
+
+	*fields = append(*fields, FieldChange{Path: path, Old: from, New: to, Sensitive: isSensitive(path, sensitive)})
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func isSensitive(path string, sensitive []string) bool {
+	for _, prefix := range sensitive {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+
+	return false
+}
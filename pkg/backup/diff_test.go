@@ -0,0 +1,63 @@
+package backup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiff(t *testing.T) {
+	user := func(name string, teams ...interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "storage.loft.sh/v1",
+			"kind":       "User",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"username": name, "teams": teams},
+		}}
+	}
+	secret := func(password string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "loft-user-secret-admin", "namespace": "loft"},
+			"data":       map[string]interface{}{"password": password},
+		}}
+	}
+
+	// live objects differ in metadata and status that aren't backed up
+	live := user("admin", "dev", "ops")
+	live.SetResourceVersion("12")
+	live.SetUID("1234")
+	live.Object["status"] = map[string]interface{}{"clusters": []interface{}{"a"}}
+
+	changes := Diff(
+		[]*unstructured.Unstructured{user("admin", "dev"), user("removed"), secret("b2xk")},
+		[]*unstructured.Unstructured{live, user("added"), secret("bmV3")},
+	)
+	assert.Equal(t, len(changes), 4)
+
+	assert.Equal(t, changes[0].Key.String(), "Secret loft/loft-user-secret-admin")
+	assert.Equal(t, changes[0].Type, Changed)
+	assert.Equal(t, changes[0].Fields[0].Path, "data.password")
+	from, to := changes[0].Fields[0].Format(false)
+	assert.Equal(t, from, Redacted)
+	assert.Equal(t, to, Redacted)
+	from, to = changes[0].Fields[0].Format(true)
+	assert.Equal(t, from, `"b2xk"`)
+	assert.Equal(t, to, `"bmV3"`)
+
+	assert.Equal(t, changes[1].Key.String(), "User.storage.loft.sh added")
+	assert.Equal(t, changes[1].Type, Added)
+
+	assert.Equal(t, changes[2].Key.String(), "User.storage.loft.sh admin")
+	assert.Equal(t, changes[2].Type, Changed)
+	assert.Equal(t, len(changes[2].Fields), 1)
+	assert.Equal(t, changes[2].Fields[0].Path, "spec.teams[1]")
+	from, to = changes[2].Fields[0].Format(false)
+	assert.Equal(t, from, "(none)")
+	assert.Equal(t, to, `"ops"`)
+
+	assert.Equal(t, changes[3].Key.String(), "User.storage.loft.sh removed")
+	assert.Equal(t, changes[3].Type, Removed)
+}
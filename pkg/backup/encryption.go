@@ -0,0 +1,529 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Magic is the first line of every encrypted backup
+const Magic = "loft-backup-encrypted/v1"
+
+// The prefixes of encoded backup keys
+const (
+	PublicKeyPrefix = "loft-backup-pk-"
+	SecretKeyPrefix = "LOFT-BACKUP-SK-"
+)
+
+// The ways the key of an encrypted backup is derived
+const (
+	ModePassphrase = "passphrase"
+	ModeX25519     = "x25519"
+)
+
+const (
+	keySize         = 32
+	noncePrefixSize = 7
+	chunkSize       = 64 * 1024
+	scryptN         = 1 << 15
+	hkdfInfo        = "loft backup x25519"
+)
+
+var (
+	// ErrDecrypt is returned if a backup can't be decrypted because the key is wrong or the
+	// file was modified
+	ErrDecrypt = errors.New("backup can't be decrypted, either the key is wrong or the file was modified")
+
+	// ErrTruncated is returned if an encrypted backup ends before its last chunk
+	ErrTruncated = errors.New("encrypted backup is truncated")
+)
+
+// header is the second line of an encrypted backup. It holds everything needed besides the
+// passphrase or secret key to derive the key of the backup.
+type header struct {
+	Mode               string `json:"mode"`
+	Salt               []byte `json:"salt,omitempty"`
+	ScryptN            int    `json:"scryptN,omitempty"`
+	EphemeralPublicKey []byte `json:"ephemeralPublicKey,omitempty"`
+	Recipient          []byte `json:"recipient,omitempty"`
+	NoncePrefix        []byte `json:"noncePrefix"`
+}
+
+// Recipient encrypts new backups
+type Recipient interface {
+	// newHeader returns the header of a new backup and the key its content is encrypted with
+	newHeader() (*header, []byte, error)
+}
+
+// Identity decrypts backups
+type Identity interface {
+	// key derives the key of the backup with the given header. It returns errWrongMode if the
+	// backup wasn't encrypted for this kind of identity.
+	key(h *header) ([]byte, error)
+}
+
+var errWrongMode = errors.New("wrong mode")
+
+type passphraseRecipient struct {
+	passphrase string
+}
+
+// PassphraseRecipient encrypts backups with a key derived from the passphrase
+func PassphraseRecipient(passphrase string) Recipient {
+	return &passphraseRecipient{passphrase: passphrase}
+}
+
+func (p *passphraseRecipient) newHeader() (*header, []byte, error) {
+	h := &header{Mode: ModePassphrase, Salt: make([]byte, 16), ScryptN: scryptN}
+	_, err := rand.Read(h.Salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := scrypt.Key([]byte(p.passphrase), h.Salt, h.ScryptN, 8, 1, keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h, key, nil
+}
+
+type passphraseIdentity struct {
+	passphrase func() (string, error)
+}
+
+// PassphraseIdentity decrypts backups that were encrypted with a passphrase. The passphrase is
+// only requested if the backup needs one.
+func PassphraseIdentity(passphrase func() (string, error)) Identity {
+	return &passphraseIdentity{passphrase: passphrase}
+}
+
+func (p *passphraseIdentity) key(h *header) ([]byte, error) {
+	if h.Mode != ModePassphrase {
+		return nil, errWrongMode
+	} else if h.ScryptN <= 1 || h.ScryptN > 1<<20 {
+		return nil, fmt.Errorf("invalid scrypt cost %d", h.ScryptN)
+	}
+
+	passphrase, err := p.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	return scrypt.Key([]byte(passphrase), h.Salt, h.ScryptN, 8, 1, keySize)
+}
+
+type publicKeyRecipient struct {
+	publicKey *ecdh.PublicKey
+}
+
+// ParseRecipient parses a public key created by GenerateKey
+func ParseRecipient(publicKey string) (Recipient, error) {
+	raw, err := decodeKey(publicKey, PublicKeyPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse public key")
+	}
+
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse public key")
+	}
+
+	return &publicKeyRecipient{publicKey: key}, nil
+}
+
+func (p *publicKeyRecipient) newHeader() (*header, []byte, error) {
+	ephemeralKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sharedSecret, err := ephemeralKey.ECDH(p.publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := &header{
+		Mode:               ModeX25519,
+		EphemeralPublicKey: ephemeralKey.PublicKey().Bytes(),
+		Recipient:          p.publicKey.Bytes(),
+	}
+	key, err := deriveKey(sharedSecret, h)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return h, key, nil
+}
+
+type secretKeyIdentity struct {
+	secretKey *ecdh.PrivateKey
+}
+
+// ParseIdentity parses a secret key created by GenerateKey
+func ParseIdentity(secretKey string) (Identity, error) {
+	raw, err := decodeKey(secretKey, SecretKeyPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse secret key")
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse secret key")
+	}
+
+	return &secretKeyIdentity{secretKey: key}, nil
+}
+
+func (s *secretKeyIdentity) key(h *header) ([]byte, error) {
+	if h.Mode != ModeX25519 {
+		return nil, errWrongMode
+	}
+
+	ephemeralPublicKey, err := ecdh.X25519().NewPublicKey(h.EphemeralPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse ephemeral public key")
+	}
+
+	sharedSecret, err := s.secretKey.ECDH(ephemeralPublicKey)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return deriveKey(sharedSecret, h)
+}
+
+// deriveKey derives the key of the backup from the shared secret. Both public keys are mixed in,
+// so the key is bound to the recipient.
+func deriveKey(sharedSecret []byte, h *header) ([]byte, error) {
+	salt := append(append([]byte{}, h.EphemeralPublicKey...), h.Recipient...)
+	key := make([]byte, keySize)
+	_, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, salt, []byte(hkdfInfo)), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// GenerateKey creates a new key pair to encrypt backups with. The public key can be given to
+// everyone creating backups, the secret key is needed to decrypt them.
+func GenerateKey() (string, string, error) {
+	secretKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return PublicKeyPrefix + base64.RawURLEncoding.EncodeToString(secretKey.PublicKey().Bytes()),
+		SecretKeyPrefix + base64.RawURLEncoding.EncodeToString(secretKey.Bytes()),
+		nil
+}
+
+func decodeKey(key, prefix string) ([]byte, error) {
+	key = strings.TrimSpace(key)
+	if !strings.HasPrefix(key, prefix) {
+		return nil, fmt.Errorf("key doesn't start with %s", prefix)
+	}
+
+	return base64.RawURLEncoding.DecodeString(strings.TrimPrefix(key, prefix))
+}
+
+// IsEncrypted checks if the backup read by the reader is encrypted without consuming it
+func IsEncrypted(r *bufio.Reader) bool {
+	start, _ := r.Peek(len(Magic) + 1)
+	return string(start) == Magic+"\n"
+}
+
+// Mode returns how the key of the encrypted backup read by the reader is derived without
+// consuming it
+func Mode(r *bufio.Reader) (string, error) {
+	start, _ := r.Peek(4096)
+	_, after, found := strings.Cut(string(start), "\n")
+	if !found || !IsEncrypted(r) {
+		return "", fmt.Errorf("backup is not encrypted")
+	}
+
+	line, _, _ := strings.Cut(after, "\n")
+	h := &header{}
+	err := json.Unmarshal([]byte(line), h)
+	if err != nil {
+		return "", errors.Wrap(err, "parse encryption header")
+	}
+
+	return h.Mode, nil
+}
+
+// NewWriter returns a writer that encrypts everything written to it for the recipient. The
+// content is split into chunks that are authenticated on their own, so the backup doesn't need
+// to be held in memory. Close needs to be called to write the last chunk.
+func NewWriter(w io.Writer, recipient Recipient) (io.WriteCloser, error) {
+	h, key, err := recipient.newHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	h.NoncePrefix = make([]byte, noncePrefixSize)
+	_, err = rand.Read(h.NoncePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	rawHeader, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// the header is authenticated as part of every chunk
+	additionalData := []byte(Magic + "\n" + string(rawHeader) + "\n")
+	_, err = w.Write(additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		w:              w,
+		stream:         newStream(aead, h.NoncePrefix),
+		additionalData: additionalData,
+		buffer:         make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// NewReader returns a reader that decrypts the encrypted backup read by r. The first identity
+// that matches the mode of the backup is used.
+func NewReader(r io.Reader, identities ...Identity) (io.Reader, error) {
+	bufferedReader := bufio.NewReader(r)
+	magic, err := bufferedReader.ReadString('\n')
+	if err != nil || magic != Magic+"\n" {
+		return nil, fmt.Errorf("backup is not encrypted")
+	}
+
+	rawHeader, err := bufferedReader.ReadString('\n')
+	if err != nil {
+		return nil, ErrTruncated
+	}
+
+	h := &header{}
+	err = json.Unmarshal([]byte(rawHeader), h)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse encryption header")
+	} else if len(h.NoncePrefix) != noncePrefixSize {
+		return nil, fmt.Errorf("invalid nonce in encryption header")
+	}
+
+	var key []byte
+	for _, identity := range identities {
+		key, err = identity.key(h)
+		if errors.Is(err, errWrongMode) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		break
+	}
+	if key == nil {
+		if h.Mode == ModeX25519 {
+			return nil, fmt.Errorf("backup is encrypted with a public key, please specify the secret key to decrypt it")
+		}
+
+		return nil, fmt.Errorf("backup is encrypted with a %s, but none was given", h.Mode)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{
+		r:              bufferedReader,
+		stream:         newStream(aead, h.NoncePrefix),
+		additionalData: []byte(magic + rawHeader),
+	}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// stream derives a unique nonce for every chunk from the random prefix, the chunk counter and
+// whether it is the last chunk. Reordered, dropped or appended chunks fail to authenticate.
+type stream struct {
+	aead    cipher.AEAD
+	nonce   []byte
+	counter uint32
+}
+
+func newStream(aead cipher.AEAD, noncePrefix []byte) *stream {
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, noncePrefix)
+	return &stream{aead: aead, nonce: nonce}
+}
+
+func (s *stream) next(last bool) ([]byte, error) {
+	if s.counter == ^uint32(0) {
+		return nil, fmt.Errorf("backup is too large to encrypt")
+	}
+
+	binary.BigEndian.PutUint32(s.nonce[noncePrefixSize:], s.counter)
+	s.nonce[len(s.nonce)-1] = 0
+	if last {
+		s.nonce[len(s.nonce)-1] = 1
+	}
+
+	s.counter++
+	return s.nonce, nil
+}
+
+type writer struct {
+	w              io.Writer
+	stream         *stream
+	additionalData []byte
+	buffer         []byte
+	closed         bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed backup")
+	}
+
+	written := len(p)
+	for len(p) > 0 {
+		n := copy(w.buffer[len(w.buffer):cap(w.buffer)], p)
+		w.buffer = w.buffer[:len(w.buffer)+n]
+		p = p[n:]
+
+		// only write full chunks once more data follows, so the last chunk is never empty
+		if len(w.buffer) == chunkSize && len(p) > 0 {
+			err := w.writeChunk(false)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	return w.writeChunk(true)
+}
+
+// writeChunk writes the buffer as chunk. A chunk is a flag byte that marks the last chunk, the
+// length of the sealed chunk and the sealed chunk itself.
+func (w *writer) writeChunk(last bool) error {
+	nonce, err := w.stream.next(last)
+	if err != nil {
+		return err
+	}
+
+	sealed := w.stream.aead.Seal(nil, nonce, w.buffer, w.additionalData)
+	frame := make([]byte, 5, 5+len(sealed))
+	if last {
+		frame[0] = 1
+	}
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(sealed)))
+
+	_, err = w.w.Write(append(frame, sealed...))
+	if err != nil {
+		return err
+	}
+
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+type reader struct {
+	r              *bufio.Reader
+	stream         *stream
+	additionalData []byte
+	buffer         []byte
+	done           bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.buffer) == 0 {
+		if r.done {
+			_, err := r.r.ReadByte()
+			if err == io.EOF {
+				return 0, io.EOF
+			}
+
+			return 0, ErrDecrypt
+		}
+
+		err := r.readChunk()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.buffer)
+	r.buffer = r.buffer[n:]
+	return n, nil
+}
+
+func (r *reader) readChunk() error {
+	frame := make([]byte, 5)
+	_, err := io.ReadFull(r.r, frame)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	} else if err != nil {
+		return err
+	}
+
+	last := frame[0] == 1
+	length := binary.BigEndian.Uint32(frame[1:])
+	if frame[0] > 1 || length > uint32(chunkSize+r.stream.aead.Overhead()) {
+		return ErrDecrypt
+	}
+
+	sealed := make([]byte, length)
+	_, err = io.ReadFull(r.r, sealed)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	} else if err != nil {
+		return err
+	}
+
+	nonce, err := r.stream.next(last)
+	if err != nil {
+		return err
+	}
+
+	r.buffer, err = r.stream.aead.Open(sealed[:0], nonce, sealed, r.additionalData)
+	if err != nil {
+		return ErrDecrypt
+	}
+
+	r.done = last
+	return nil
+}
@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func encrypt(t *testing.T, content []byte, recipient Recipient) []byte {
+	buffer := &bytes.Buffer{}
+	w, err := NewWriter(buffer, recipient)
+	assert.NilError(t, err)
+	_, err = w.Write(content)
+	assert.NilError(t, err)
+	assert.NilError(t, w.Close())
+	return buffer.Bytes()
+}
+
+func decrypt(encrypted []byte, identities ...Identity) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(encrypted), identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func staticPassphrase(passphrase string) Identity {
+	return PassphraseIdentity(func() (string, error) { return passphrase, nil })
+}
+
+func TestRoundTrip(t *testing.T) {
+	publicKey, secretKey, err := GenerateKey()
+	assert.NilError(t, err)
+	recipient, err := ParseRecipient(publicKey)
+	assert.NilError(t, err)
+	identity, err := ParseIdentity(secretKey)
+	assert.NilError(t, err)
+
+	large := make([]byte, 3*chunkSize+100)
+	_, err = rand.Read(large)
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "empty"},
+		{name: "small", content: []byte("apiVersion: v1\nkind: Secret\n")},
+		{name: "exactly one chunk", content: large[:chunkSize]},
+		{name: "multiple chunks", content: large},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			encrypted := encrypt(t, testCase.content, PassphraseRecipient("secret"))
+			assert.Assert(t, IsEncrypted(bufio.NewReader(bytes.NewReader(encrypted))))
+			decrypted, err := decrypt(encrypted, identity, staticPassphrase("secret"))
+			assert.NilError(t, err)
+			assert.Assert(t, bytes.Equal(decrypted, testCase.content))
+
+			encrypted = encrypt(t, testCase.content, recipient)
+			mode, err := Mode(bufio.NewReader(bytes.NewReader(encrypted)))
+			assert.NilError(t, err)
+			assert.Equal(t, mode, ModeX25519)
+			decrypted, err = decrypt(encrypted, staticPassphrase("secret"), identity)
+			assert.NilError(t, err)
+			assert.Assert(t, bytes.Equal(decrypted, testCase.content))
+		})
+	}
+}
+
+func TestPassphraseOnlyRequestedIfNeeded(t *testing.T) {
+	publicKey, secretKey, err := GenerateKey()
+	assert.NilError(t, err)
+	recipient, err := ParseRecipient(publicKey)
+	assert.NilError(t, err)
+	identity, err := ParseIdentity(secretKey)
+	assert.NilError(t, err)
+
+	asked := false
+	passphrase := PassphraseIdentity(func() (string, error) {
+		asked = true
+		return "", nil
+	})
+	_, err = decrypt(encrypt(t, []byte("content"), recipient), passphrase, identity)
+	assert.NilError(t, err)
+	assert.Assert(t, !asked)
+
+	_, err = decrypt(encrypt(t, []byte("content"), recipient), passphrase)
+	assert.ErrorContains(t, err, "encrypted with a public key")
+}
+
+func TestRejectTampered(t *testing.T) {
+	content := make([]byte, 2*chunkSize+10)
+	_, err := rand.Read(content)
+	assert.NilError(t, err)
+
+	encrypted := encrypt(t, content, PassphraseRecipient("secret"))
+	headerLength := bytes.Index(encrypted[len(Magic)+1:], []byte("\n")) + len(Magic) + 2
+	firstChunkLength := 5 + chunkSize + 16
+
+	testCases := []struct {
+		name     string
+		modify   func(encrypted []byte) []byte
+		expected error
+	}{
+		{
+			name: "flipped bit in content",
+			modify: func(encrypted []byte) []byte {
+				encrypted[headerLength+100] ^= 1
+				return encrypted
+			},
+			expected: ErrDecrypt,
+		},
+		{
+			name: "modified header",
+			modify: func(encrypted []byte) []byte {
+				return bytes.Replace(encrypted, []byte(`"noncePrefix":"`), []byte(`"noncePrefix" :"`), 1)
+			},
+			expected: ErrDecrypt,
+		},
+		{
+			name: "truncated",
+			modify: func(encrypted []byte) []byte {
+				return encrypted[:headerLength+firstChunkLength]
+			},
+			expected: ErrTruncated,
+		},
+		{
+			name: "last chunk removed and previous marked as last",
+			modify: func(encrypted []byte) []byte {
+				encrypted = encrypted[:headerLength+2*firstChunkLength]
+				encrypted[headerLength+firstChunkLength] = 1
+				return encrypted
+			},
+			expected: ErrDecrypt,
+		},
+		{
+			name: "chunks swapped",
+			modify: func(encrypted []byte) []byte {
+				swapped := append([]byte{}, encrypted[:headerLength]...)
+				swapped = append(swapped, encrypted[headerLength+firstChunkLength:headerLength+2*firstChunkLength]...)
+				swapped = append(swapped, encrypted[headerLength:headerLength+firstChunkLength]...)
+				return append(swapped, encrypted[headerLength+2*firstChunkLength:]...)
+			},
+			expected: ErrDecrypt,
+		},
+		{
+			name: "appended data",
+			modify: func(encrypted []byte) []byte {
+				return append(encrypted, []byte("---\nkind: Secret")...)
+			},
+			expected: ErrDecrypt,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := decrypt(testCase.modify(append([]byte{}, encrypted...)), staticPassphrase("secret"))
+			assert.Assert(t, errors.Is(err, testCase.expected), "expected %v, got %v", testCase.expected, err)
+		})
+	}
+}
+
+func TestRejectWrongKey(t *testing.T) {
+	_, err := decrypt(encrypt(t, []byte("content"), PassphraseRecipient("secret")), staticPassphrase("wrong"))
+	assert.Assert(t, errors.Is(err, ErrDecrypt), "got %v", err)
+
+	publicKey, _, err := GenerateKey()
+	assert.NilError(t, err)
+	recipient, err := ParseRecipient(publicKey)
+	assert.NilError(t, err)
+	_, otherSecretKey, err := GenerateKey()
+	assert.NilError(t, err)
+	otherIdentity, err := ParseIdentity(otherSecretKey)
+	assert.NilError(t, err)
+	_, err = decrypt(encrypt(t, []byte("content"), recipient), otherIdentity)
+	assert.Assert(t, errors.Is(err, ErrDecrypt), "got %v", err)
+
+	_, err = ParseRecipient(otherSecretKey)
+	assert.ErrorContains(t, err, "parse public key")
+}
@@ -0,0 +1,89 @@
+package backup
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ManagementGroupVersion is the api group version of the loft resources served by the loft api
+const ManagementGroupVersion = "management.loft.sh/v1"
+
+// ProjectSecretLabel marks the secrets in project namespaces that are project secrets
+const ProjectSecretLabel = "loft.sh/project-secret"
+
+// ManagementResources returns the served management resources that have a storage.loft.sh
+// equivalent. The loft api serves more resources, e.g. for licenses or access reviews, that are
+// not part of a backup.
+func ManagementResources(served []metav1.APIResource) []metav1.APIResource {
+	resources := []metav1.APIResource{}
+	for _, resource := range served {
+		if resource.Name == ProjectSecrets || contains(order, resource.Name) {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources
+}
+
+// ToManagement converts an object of a backup to the object the loft api accepts. Project
+// secrets are converted to management project secrets, other secrets such as the passwords of
+// users can't be restored through the loft api, so false is returned for them.
+func ToManagement(object *unstructured.Unstructured) (*unstructured.Unstructured, bool) {
+	gvk := object.GroupVersionKind()
+	switch {
+	case gvk.GroupVersion().String() == ManagementGroupVersion:
+		return object, true
+	case gvk.GroupVersion().String() == GroupVersion:
+		converted := object.DeepCopy()
+		converted.SetAPIVersion(ManagementGroupVersion)
+		return converted, true
+	case gvk == schema.GroupVersionKind{Version: "v1", Kind: "Secret"} && object.GetLabels()[ProjectSecretLabel] == "true":
+		converted := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		converted.SetAPIVersion(ManagementGroupVersion)
+		converted.SetKind("ProjectSecret")
+		converted.SetName(object.GetName())
+		converted.SetNamespace(object.GetNamespace())
+		converted.SetAnnotations(object.GetAnnotations())
+		labels := object.GetLabels()
+		delete(labels, ProjectSecretLabel)
+		if len(labels) > 0 {
+			converted.SetLabels(labels)
+		}
+		if data, found, _ := unstructured.NestedMap(object.Object, "data"); found {
+			_ = unstructured.SetNestedMap(converted.Object, data, "spec", "data")
+		}
+		return converted, true
+	}
+
+	return nil, false
+}
+
+// ToStorage converts an object backed up through the loft api to the object stored in the
+// cluster loft runs in
+func ToStorage(object *unstructured.Unstructured) *unstructured.Unstructured {
+	if object.GetAPIVersion() != ManagementGroupVersion {
+		return object
+	} else if object.GetKind() != "ProjectSecret" {
+		converted := object.DeepCopy()
+		converted.SetAPIVersion(GroupVersion)
+		return converted
+	}
+
+	converted := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	converted.SetAPIVersion("v1")
+	converted.SetKind("Secret")
+	converted.SetName(object.GetName())
+	converted.SetNamespace(object.GetNamespace())
+	converted.SetAnnotations(object.GetAnnotations())
+	labels := object.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[ProjectSecretLabel] = "true"
+	converted.SetLabels(labels)
+	if data, found, _ := unstructured.NestedMap(object.Object, "spec", "data"); found {
+		_ = unstructured.SetNestedMap(converted.Object, data, "data")
+	}
+	return converted
+}
@@ -0,0 +1,49 @@
+package backup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConvertManagement(t *testing.T) {
+	user := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "storage.loft.sh/v1",
+		"kind":       "User",
+		"metadata":   map[string]interface{}{"name": "admin"},
+		"spec":       map[string]interface{}{"username": "admin"},
+	}}
+	projectSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      "registry",
+			"namespace": "p-default",
+			"labels":    map[string]interface{}{ProjectSecretLabel: "true", "team": "a"},
+		},
+		"data": map[string]interface{}{"password": "c2VjcmV0"},
+	}}
+	userSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "loft-user-secret-admin", "namespace": "loft"},
+	}}
+
+	managementUser, ok := ToManagement(user)
+	assert.Assert(t, ok)
+	assert.Equal(t, managementUser.GetAPIVersion(), ManagementGroupVersion)
+	assert.Equal(t, user.GetAPIVersion(), GroupVersion)
+	assert.DeepEqual(t, ToStorage(managementUser).Object, user.Object)
+
+	managementSecret, ok := ToManagement(projectSecret)
+	assert.Assert(t, ok)
+	assert.Equal(t, managementSecret.GetKind(), "ProjectSecret")
+	assert.DeepEqual(t, managementSecret.GetLabels(), map[string]string{"team": "a"})
+	data, _, _ := unstructured.NestedStringMap(managementSecret.Object, "spec", "data")
+	assert.DeepEqual(t, data, map[string]string{"password": "c2VjcmV0"})
+	assert.DeepEqual(t, ToStorage(managementSecret).Object, projectSecret.Object)
+
+	_, ok = ToManagement(userSecret)
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,244 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+// Separator separates the yaml documents of a backup
+const Separator = "\n---\n"
+
+// ManifestFilename is the name of the manifest in backups written as directory
+const ManifestFilename = "manifest.yaml"
+
+// The type of the manifest document
+const (
+	ManifestAPIVersion = "backup.loft.sh/v1"
+	ManifestKind       = "Manifest"
+)
+
+// ErrChecksumMismatch is returned if the content of a backup doesn't match the checksum of its manifest
+var ErrChecksumMismatch = errors.New("backup content doesn't match the checksum of its manifest")
+
+// Manifest is the first document of a backup. It describes where the backup comes from and what
+// it contains, so incomplete or modified backups can be detected.
+type Manifest struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// ServerVersion is the version of loft the backup was created from
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// CLIVersion is the version of loft the backup was created with
+	CLIVersion        string    `json:"cliVersion,omitempty"`
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+	// ViaLoft is true if the backup was created through the loft api, so it doesn't contain the
+	// secrets referenced by users and clusters
+	ViaLoft bool `json:"viaLoft,omitempty"`
+
+	// Objects are the number of objects per apiVersion/kind
+	Objects map[string]int `json:"objects"`
+	// Failed are the resources that couldn't be backed up with their error
+	Failed map[string]string `json:"failed,omitempty"`
+	// NotServed are the included resources the server doesn't serve
+	NotServed []string `json:"notServed,omitempty"`
+
+	// Checksum is the sha256 of everything after the manifest document
+	Checksum string `json:"checksum"`
+	// Files are the files of a backup written as directory in the order they are restored in
+	Files []ManifestFile `json:"files,omitempty"`
+}
+
+// ManifestFile is a file of a backup written as directory
+type ManifestFile struct {
+	Name     string `json:"name"`
+	Resource string `json:"resource"`
+	// Checksum is the sha256 of the decrypted file
+	Checksum string `json:"checksum"`
+}
+
+// NewManifest creates a manifest for a new backup
+func NewManifest(serverVersion, cliVersion string) *Manifest {
+	return &Manifest{
+		APIVersion:        ManifestAPIVersion,
+		Kind:              ManifestKind,
+		ServerVersion:     serverVersion,
+		CLIVersion:        cliVersion,
+		CreationTimestamp: time.Now().UTC().Truncate(time.Second),
+		Objects:           map[string]int{},
+		Failed:            map[string]string{},
+	}
+}
+
+// Complete checks if every selected resource was backed up
+func (m *Manifest) Complete() bool {
+	return len(m.Failed) == 0 && len(m.NotServed) == 0
+}
+
+// Write sets the checksum of the manifest and writes it followed by the parts. Empty parts are
+// skipped, the others are separated like the documents within them. The parts are read twice, to
+// calculate the checksum before the manifest is written.
+func Write(w io.Writer, manifest *Manifest, parts ...io.ReadSeeker) error {
+	hash := sha256.New()
+	nonEmpty := []io.ReadSeeker{}
+	for _, part := range parts {
+		size, err := part.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		} else if size == 0 {
+			continue
+		}
+
+		if len(nonEmpty) > 0 {
+			_, _ = hash.Write([]byte(Separator))
+		}
+		_, err = part.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(hash, part)
+		if err != nil {
+			return err
+		}
+
+		_, err = part.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		nonEmpty = append(nonEmpty, part)
+	}
+
+	manifest.Checksum = checksum(hash.Sum(nil))
+	out, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range nonEmpty {
+		_, err = w.Write([]byte(Separator))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(w, part)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Read splits the manifest from the body of a decrypted backup. The body is verified against the
+// checksum of the manifest while it is read, so it never has to be held in memory. Its reader
+// returns ErrChecksumMismatch instead of io.EOF if the content doesn't match. Backups that were
+// created without a manifest return nil as manifest and the whole content as body.
+func Read(r io.Reader) (*Manifest, io.Reader, error) {
+	reader := bufio.NewReader(r)
+	document := &bytes.Buffer{}
+	found := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if document.Len() > 0 && string(line) == strings.TrimPrefix(Separator, "\n") {
+			found = true
+			break
+		}
+
+		document.Write(line)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	manifest := &Manifest{}
+	err := yaml.Unmarshal(document.Bytes(), manifest)
+	if err != nil || manifest.APIVersion != ManifestAPIVersion || manifest.Kind != ManifestKind {
+		if found {
+			document.WriteString(strings.TrimPrefix(Separator, "\n"))
+		}
+
+		return nil, io.MultiReader(document, reader), nil
+	}
+
+	return manifest, NewVerifyingReader(reader, manifest.Checksum), nil
+}
+
+// Verify compares the objects the manifest lists with the objects of the backup
+func (m *Manifest) Verify(objectCounts map[string]int) error {
+	for kind, count := range m.Objects {
+		if objectCounts[kind] != count {
+			return fmt.Errorf("manifest lists %d objects of %s, but the backup contains %d", count, kind, objectCounts[kind])
+		}
+	}
+	for kind, count := range objectCounts {
+		if _, ok := m.Objects[kind]; !ok {
+			return fmt.Errorf("backup contains %d objects of %s that the manifest doesn't list", count, kind)
+		}
+	}
+
+	return nil
+}
+
+// ChecksumWriter computes the checksum of everything written to it
+type ChecksumWriter struct {
+	hash hash.Hash
+}
+
+// NewChecksumWriter creates a writer that computes the checksum as the manifest lists it
+func NewChecksumWriter() *ChecksumWriter {
+	return &ChecksumWriter{hash: sha256.New()}
+}
+
+func (w *ChecksumWriter) Write(p []byte) (int, error) {
+	return w.hash.Write(p)
+}
+
+// Checksum returns the checksum of the content written so far
+func (w *ChecksumWriter) Checksum() string {
+	return checksum(w.hash.Sum(nil))
+}
+
+// verifyingReader computes the checksum of everything read through it and compares it at the end
+type verifyingReader struct {
+	r        io.Reader
+	hash     hash.Hash
+	checksum string
+}
+
+// NewVerifyingReader returns a reader that returns ErrChecksumMismatch instead of io.EOF if the
+// content read doesn't match the given checksum
+func NewVerifyingReader(r io.Reader, checksum string) io.Reader {
+	return &verifyingReader{r: r, hash: sha256.New(), checksum: checksum}
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	_, _ = v.hash.Write(p[:n])
+	if err == io.EOF && checksum(v.hash.Sum(nil)) != v.checksum {
+		return n, ErrChecksumMismatch
+	}
+
+	return n, err
+}
+
+func checksum(sum []byte) string {
+	return "sha256:" + hex.EncodeToString(sum)
+}
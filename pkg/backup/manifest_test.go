@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestManifest(t *testing.T) {
+	manifest := NewManifest("v3.3.0", "v3.3.1")
+	manifest.Objects["storage.loft.sh/v1/User"] = 2
+	body := "kind: User\n" + Separator + "kind: User\n"
+
+	// empty parts are skipped and the others are separated like documents
+	buffer := &bytes.Buffer{}
+	assert.NilError(t, Write(buffer, manifest, bytes.NewReader(nil), strings.NewReader("kind: User\n"), bytes.NewReader(nil), strings.NewReader("kind: User\n")))
+
+	read, readBody, err := Read(bytes.NewReader(buffer.Bytes()))
+	assert.NilError(t, err)
+	out, err := io.ReadAll(readBody)
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), body)
+	assert.Equal(t, read.Checksum, manifest.Checksum)
+	assert.NilError(t, read.Verify(map[string]int{"storage.loft.sh/v1/User": 2}))
+	assert.ErrorContains(t, read.Verify(map[string]int{"storage.loft.sh/v1/User": 1}), "manifest lists 2 objects")
+	assert.ErrorContains(t, read.Verify(map[string]int{"storage.loft.sh/v1/User": 2, "v1/Secret": 1}), "doesn't list")
+
+	// the checksum is verified once the body is read
+	_, readBody, err = Read(bytes.NewReader(append(buffer.Bytes(), []byte(Separator+"kind: Secret\n")...)))
+	assert.NilError(t, err)
+	_, err = io.ReadAll(readBody)
+	assert.Assert(t, errors.Is(err, ErrChecksumMismatch))
+
+	// backups without manifest are returned as a whole
+	for _, content := range []string{"kind: User\n", "kind: User\n" + Separator + "kind: User\n", "---\nkind: User\n"} {
+		read, readBody, err = Read(strings.NewReader(content))
+		assert.NilError(t, err)
+		assert.Assert(t, read == nil)
+		out, err = io.ReadAll(readBody)
+		assert.NilError(t, err)
+		assert.Equal(t, string(out), content)
+	}
+}
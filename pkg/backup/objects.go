@@ -0,0 +1,109 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ReadObjects parses the yaml documents of a decrypted backup
+func ReadObjects(r io.Reader) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+	yamlReader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		document, err := yamlReader.Read()
+		if err == io.EOF {
+			return objects, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "read backup")
+		} else if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+
+		raw, err := yaml.YAMLToJSON(document)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse object %d", len(objects)+1)
+		} else if string(raw) == "null" {
+			continue
+		}
+
+		object := &unstructured.Unstructured{}
+		err = object.UnmarshalJSON(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse object %d", len(objects)+1)
+		}
+
+		objects = append(objects, object)
+	}
+}
+
+// CountObjects returns the number of objects per apiVersion/kind like the manifest lists them
+func CountObjects(objects []*unstructured.Unstructured) map[string]int {
+	counts := map[string]int{}
+	for _, object := range objects {
+		counts[object.GetAPIVersion()+"/"+object.GetKind()]++
+	}
+
+	return counts
+}
+
+// StripMetadata removes the metadata that is set by the server and can't be restored
+func StripMetadata(object metav1.Object) {
+	object.SetGenerateName("")
+	object.SetSelfLink("")
+	object.SetCreationTimestamp(metav1.Time{})
+	object.SetFinalizers(nil)
+	object.SetGeneration(0)
+	object.SetManagedFields(nil)
+	object.SetOwnerReferences(nil)
+	object.SetResourceVersion("")
+	object.SetUID("")
+	object.SetDeletionTimestamp(nil)
+}
+
+// DocumentWriter writes objects as yaml documents and counts them
+type DocumentWriter struct {
+	w       io.Writer
+	written int
+
+	// Objects are the number of written objects per apiVersion/kind
+	Objects map[string]int
+}
+
+// NewDocumentWriter creates a new document writer
+func NewDocumentWriter(w io.Writer) *DocumentWriter {
+	return &DocumentWriter{w: w, Objects: map[string]int{}}
+}
+
+// Write marshals the object and writes it as next document
+func (d *DocumentWriter) Write(object runtime.Object) error {
+	out, err := yaml.Marshal(object)
+	if err != nil {
+		return errors.Wrap(err, "marshal object")
+	}
+
+	typeAccessor, err := meta.TypeAccessor(object)
+	if err != nil {
+		return err
+	}
+
+	if d.written > 0 {
+		out = append([]byte(Separator), out...)
+	}
+	_, err = d.w.Write(out)
+	if err != nil {
+		return err
+	}
+
+	d.Objects[typeAccessor.GetAPIVersion()+"/"+typeAccessor.GetKind()]++
+	d.written++
+	return nil
+}
@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GroupVersion is the api group version of the loft resources that are backed up
+const GroupVersion = "storage.loft.sh/v1"
+
+// ProjectSecrets is the name of the secrets labeled as project secrets. They aren't served by the
+// storage api group, but are selected the same way as the loft resources. The loft api serves
+// them as management resource.
+const ProjectSecrets = "projectsecrets"
+
+// DefaultExcluded are the resources that are only backed up if they are included explicitly.
+// Tasks only describe work that is already done or in progress.
+var DefaultExcluded = []string{"tasks"}
+
+// order is the order known resources are backed up and restored in, so objects are restored
+// after the objects they reference. Unknown resources follow in alphabetical order.
+var order = []string{
+	"clusterroletemplates",
+	"clusteraccesses",
+	"spaceconstraints",
+	"users",
+	"teams",
+	"sharedsecrets",
+	"accesskeys",
+	"apps",
+	"spacetemplates",
+	"virtualclustertemplates",
+	"devpodworkspacetemplates",
+	"clusters",
+	"runners",
+	"projects",
+	"virtualclusterinstances",
+	"spaceinstances",
+	"devpodworkspaceinstances",
+}
+
+// SelectResources returns the resources to back up from the served storage resources. If include
+// is not empty only these resources are selected, otherwise all resources except the excluded
+// ones and DefaultExcluded. Resources can be referenced by their plural, singular or kind name.
+// The included resources the server doesn't serve are returned as well.
+func SelectResources(served []metav1.APIResource, include, exclude []string) ([]metav1.APIResource, []string) {
+	if !containsResource(served, ProjectSecrets) {
+		served = append(served, metav1.APIResource{Name: ProjectSecrets, SingularName: "projectsecret", Kind: "ProjectSecret", Namespaced: true, Verbs: metav1.Verbs{"list"}})
+	}
+
+	selected := []metav1.APIResource{}
+	found := map[string]bool{}
+	for _, resource := range served {
+		if strings.Contains(resource.Name, "/") || !contains(resource.Verbs, "list") {
+			continue
+		}
+
+		if len(include) > 0 {
+			names := matches(resource, include)
+			if len(names) == 0 {
+				continue
+			}
+
+			for _, name := range names {
+				found[name] = true
+			}
+		} else if len(matches(resource, exclude)) > 0 || len(matches(resource, DefaultExcluded)) > 0 {
+			continue
+		}
+
+		selected = append(selected, resource)
+	}
+
+	notServed := []string{}
+	for _, name := range include {
+		if !found[name] {
+			notServed = append(notServed, name)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return less(selected[i], selected[j])
+	})
+	return selected, notServed
+}
+
+// matches returns the names that reference the resource
+func matches(resource metav1.APIResource, names []string) []string {
+	matched := []string{}
+	for _, name := range names {
+		lowerName := strings.ToLower(name)
+		if lowerName == resource.Name || lowerName == resource.SingularName || lowerName == strings.ToLower(resource.Kind) {
+			matched = append(matched, name)
+		}
+	}
+
+	return matched
+}
+
+func less(a, b metav1.APIResource) bool {
+	indexA, indexB := orderIndex(a), orderIndex(b)
+	if indexA != indexB {
+		return indexA < indexB
+	}
+
+	return a.Name < b.Name
+}
+
+// orderIndex sorts unknown cluster scoped resources before projects, as projects might reference
+// them, and unknown namespaced resources after the known ones. Project secrets come last as their
+// namespaces are created for the projects.
+func orderIndex(resource metav1.APIResource) int {
+	for i, name := range order {
+		if name == resource.Name {
+			return 2 * i
+		}
+	}
+
+	switch {
+	case resource.Name == ProjectSecrets:
+		return 2*len(order) + 1
+	case resource.Namespaced:
+		return 2 * len(order)
+	}
+
+	for i, name := range order {
+		if name == "projects" {
+			return 2*i - 1
+		}
+	}
+
+	return 2 * len(order)
+}
+
+func contains(arr []string, s string) bool {
+	for _, t := range arr {
+		if t == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsResource(resources []metav1.APIResource, name string) bool {
+	for _, resource := range resources {
+		if resource.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
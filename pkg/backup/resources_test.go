@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectResources(t *testing.T) {
+	listable := metav1.Verbs{"get", "list", "create"}
+	served := []metav1.APIResource{
+		{Name: "virtualclusterinstances", SingularName: "virtualclusterinstance", Kind: "VirtualClusterInstance", Namespaced: true, Verbs: listable},
+		{Name: "projects", SingularName: "project", Kind: "Project", Verbs: listable},
+		{Name: "projects/status", Kind: "Project", Verbs: listable},
+		{Name: "users", SingularName: "user", Kind: "User", Verbs: listable},
+		{Name: "tasks", SingularName: "task", Kind: "Task", Verbs: listable},
+		{Name: "projectroles", SingularName: "projectrole", Kind: "ProjectRole", Verbs: listable},
+		{Name: "devpodworkspaceinstances", SingularName: "devpodworkspaceinstance", Kind: "DevPodWorkspaceInstance", Namespaced: true, Verbs: listable},
+		{Name: "newinstances", SingularName: "newinstance", Kind: "NewInstance", Namespaced: true, Verbs: listable},
+		{Name: "watchonly", Kind: "WatchOnly", Verbs: metav1.Verbs{"watch"}},
+	}
+
+	names := func(resources []metav1.APIResource) []string {
+		ret := []string{}
+		for _, resource := range resources {
+			ret = append(ret, resource.Name)
+		}
+		return ret
+	}
+
+	testCases := []struct {
+		name              string
+		include           []string
+		exclude           []string
+		expected          []string
+		expectedNotServed []string
+	}{
+		{
+			name:              "all",
+			expected:          []string{"users", "projectroles", "projects", "virtualclusterinstances", "devpodworkspaceinstances", "newinstances", ProjectSecrets},
+			expectedNotServed: []string{},
+		},
+		{
+			name:              "exclude by kind and plural",
+			exclude:           []string{"User", "projectsecrets", "newinstance"},
+			expected:          []string{"projectroles", "projects", "virtualclusterinstances", "devpodworkspaceinstances"},
+			expectedNotServed: []string{},
+		},
+		{
+			name:              "include",
+			include:           []string{"tasks", "projects", "runners"},
+			expected:          []string{"tasks", "projects"},
+			expectedNotServed: []string{"runners"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			selected, notServed := SelectResources(served, testCase.include, testCase.exclude)
+			assert.DeepEqual(t, names(selected), testCase.expected)
+			assert.DeepEqual(t, notServed, testCase.expectedNotServed)
+		})
+	}
+}
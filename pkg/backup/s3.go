@@ -0,0 +1,251 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+)
+
+// DefaultS3Region is the region requests are signed for if none is configured
+const DefaultS3Region = "us-east-1"
+
+// S3Target is a bucket and a key prefix backups are stored under, e.g. s3://bucket/loft
+type S3Target struct {
+	Bucket string
+	Prefix string
+}
+
+// ParseS3Target parses a target in the form s3://bucket/prefix
+func ParseS3Target(target string) (*S3Target, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse target %s", target)
+	} else if parsed.Scheme != "s3" || parsed.Host == "" {
+		return nil, fmt.Errorf("target %s is not in the form s3://bucket/prefix", target)
+	}
+
+	return &S3Target{Bucket: parsed.Host, Prefix: strings.Trim(parsed.Path, "/")}, nil
+}
+
+func (t *S3Target) String() string {
+	return strings.TrimSuffix(t.URL(t.Prefix), "/")
+}
+
+// URL returns the url of the object with the key in the bucket of the target
+func (t *S3Target) URL(key string) string {
+	return "s3://" + t.Bucket + "/" + key
+}
+
+// Key returns the key of the object with the name under the prefix of the target
+func (t *S3Target) Key(name string) string {
+	if t.Prefix == "" {
+		return name
+	}
+
+	return t.Prefix + "/" + name
+}
+
+// S3Object is an object listed in a bucket
+type S3Object struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
+// S3Client is a client for S3 compatible object storage, e.g. AWS S3 or MinIO. Large objects are
+// uploaded in multiple parts.
+type S3Client struct {
+	// Endpoint is the url of the object storage. If empty, AWS S3 of the region is used with
+	// virtual hosted buckets, otherwise buckets are addressed by path.
+	Endpoint string
+	Region   string
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Transport is used for the requests. If nil, a transport with dial, handshake and response
+	// header timeouts is used.
+	Transport http.RoundTripper
+}
+
+// NewS3ClientFromEnv creates a client with the endpoint, region and credentials from the
+// environment variables the AWS cli uses
+func NewS3ClientFromEnv() *S3Client {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	return &S3Client{
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Put uploads size bytes of content as object. If size is -1, the content is uploaded in parts
+// until it ends.
+func (c *S3Client) Put(ctx context.Context, bucket, key string, content io.Reader, size int64) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PutObject(ctx, bucket, key, content, size, minio.PutObjectOptions{ContentType: "application/octet-stream"})
+	if err != nil {
+		return errors.Wrapf(s3Error(err), "upload %s", key)
+	}
+
+	return nil
+}
+
+// Get downloads the object. The caller has to close the returned reader.
+func (c *S3Client) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(s3Error(err), "download %s", key)
+	}
+
+	// the object is only requested once it is read or its info is requested
+	_, err = object.Stat()
+	if err != nil {
+		_ = object.Close()
+		return nil, errors.Wrapf(s3Error(err), "download %s", key)
+	}
+
+	return object, nil
+}
+
+// Delete removes the object
+func (c *S3Client) Delete(ctx context.Context, bucket, key string) error {
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	err = client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return errors.Wrapf(s3Error(err), "delete %s", key)
+	}
+
+	return nil
+}
+
+// List returns all objects with keys starting with the prefix sorted by key
+func (c *S3Client) List(ctx context.Context, bucket, prefix string) ([]S3Object, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objects := []S3Object{}
+	for object := range client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, errors.Wrapf(s3Error(object.Err), "list %s", bucket)
+		}
+
+		objects = append(objects, S3Object{Key: object.Key, LastModified: object.LastModified, Size: object.Size})
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+	return objects, nil
+}
+
+func (c *S3Client) client() (*minio.Client, error) {
+	region := c.Region
+	if region == "" {
+		region = DefaultS3Region
+	}
+
+	options := &minio.Options{
+		Creds:     credentials.NewStaticV4(c.AccessKeyID, c.SecretAccessKey, c.SessionToken),
+		Secure:    true,
+		Transport: c.Transport,
+		Region:    region,
+	}
+	if c.Endpoint == "" {
+		return minio.New("s3."+region+".amazonaws.com", options)
+	}
+
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse endpoint")
+	} else if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("endpoint %s is not in the form http(s)://host:port", c.Endpoint)
+	} else if strings.Trim(endpoint.Path, "/") != "" {
+		return nil, fmt.Errorf("endpoint %s must not have a path", c.Endpoint)
+	}
+
+	options.Secure = endpoint.Scheme == "https"
+	options.BucketLookup = minio.BucketLookupPath
+	return minio.New(endpoint.Host, options)
+}
+
+// s3Error returns the code and message of errors returned by the object storage
+func s3Error(err error) error {
+	response := minio.ToErrorResponse(err)
+	if response.Code == "" {
+		return err
+	}
+
+	return fmt.Errorf("%s: %s", response.Code, response.Message)
+}
+
+// The name of a backup uploaded to a target is its timestamp enclosed by a prefix and a suffix
+const (
+	backupNamePrefix = "loft-backup-"
+	backupNameSuffix = ".yaml"
+	backupTimeFormat = "20060102T150405Z"
+)
+
+// BackupName returns the name of a backup uploaded at the time. Names sort by time.
+func BackupName(now time.Time) string {
+	return backupNamePrefix + now.UTC().Format(backupTimeFormat) + backupNameSuffix
+}
+
+// Backups returns the backups directly under the prefix of the target from newest to oldest.
+// Other objects under the prefix are ignored.
+func (t *S3Target) Backups(objects []S3Object) []S3Object {
+	backups := []S3Object{}
+	for _, object := range objects {
+		name, found := strings.CutPrefix(object.Key, t.Key(""))
+		if !found || !strings.HasPrefix(name, backupNamePrefix) || !strings.HasSuffix(name, backupNameSuffix) {
+			continue
+		}
+
+		_, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, backupNamePrefix), backupNameSuffix))
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, object)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Key > backups[j].Key
+	})
+	return backups
+}
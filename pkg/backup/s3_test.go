@@ -0,0 +1,237 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"gotest.tools/v3/assert"
+)
+
+// fakeS3 is a minimal in memory stand-in for S3 compatible object storage with path style
+// buckets. It lists at most one object per page, so pagination is exercised.
+type fakeS3 struct {
+	m       sync.Mutex
+	objects map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>"))
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+	switch {
+	case req.Method == http.MethodPut:
+		out, err := readPayload(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.objects[bucket+"/"+key] = string(out)
+		w.Header().Set("ETag", `"etag"`)
+	case req.Method == http.MethodDelete:
+		delete(f.objects, bucket+"/"+key)
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == http.MethodGet && key == "":
+		keys := []string{}
+		for name := range f.objects {
+			objectKey := strings.TrimPrefix(name, bucket+"/")
+			if strings.HasPrefix(name, bucket+"/") && strings.HasPrefix(objectKey, req.URL.Query().Get("prefix")) && objectKey > req.URL.Query().Get("continuation-token") {
+				keys = append(keys, objectKey)
+			}
+		}
+		sort.Strings(keys)
+
+		type object struct {
+			Key  string `xml:"Key"`
+			Size int64  `xml:"Size"`
+		}
+		result := struct {
+			XMLName               xml.Name `xml:"ListBucketResult"`
+			Contents              []object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken,omitempty"`
+		}{}
+		if len(keys) > 0 {
+			result.Contents = []object{{Key: keys[0], Size: int64(len(f.objects[bucket+"/"+keys[0]]))}}
+			result.IsTruncated = len(keys) > 1
+			result.NextContinuationToken = keys[0]
+		}
+		_ = xml.NewEncoder(w).Encode(result)
+	case req.Method == http.MethodGet || req.Method == http.MethodHead:
+		content, ok := f.objects[bucket+"/"+key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>"))
+			return
+		}
+
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if req.Method == http.MethodGet {
+			_, _ = w.Write([]byte(content))
+		}
+	}
+}
+
+// readPayload reads the body of an upload, which is sent in signed chunks over http
+func readPayload(req *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(req.Header.Get("x-amz-content-sha256"), "STREAMING-") {
+		return io.ReadAll(req.Body)
+	}
+
+	payload := &bytes.Buffer{}
+	reader := bufio.NewReader(req.Body)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		} else if size == 0 {
+			return payload.Bytes(), nil
+		}
+
+		_, err = io.CopyN(payload, reader, size)
+		if err != nil {
+			return nil, err
+		}
+		_, err = reader.Discard(2)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// testS3Client uploads, lists, downloads and deletes backups in the bucket
+func testS3Client(t *testing.T, client *S3Client, bucket string) {
+	ctx := context.Background()
+	target, err := ParseS3Target("s3://" + bucket + "/loft/prod/")
+	assert.NilError(t, err)
+	assert.Equal(t, target.Bucket, bucket)
+	assert.Equal(t, target.Key("backup.yaml"), "loft/prod/backup.yaml")
+
+	put := func(key, content string) {
+		assert.NilError(t, client.Put(ctx, target.Bucket, key, strings.NewReader(content), int64(len(content))))
+	}
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		put(target.Key(BackupName(now.Add(time.Duration(i)*time.Hour))), "backup "+string(rune('a'+i)))
+	}
+	put(target.Key("notes.txt"), "notes")
+	put("loft/other/"+BackupName(now), "other")
+
+	objects, err := client.List(ctx, target.Bucket, target.Key(""))
+	assert.NilError(t, err)
+	assert.Equal(t, len(objects), 4)
+
+	backups := target.Backups(objects)
+	assert.DeepEqual(t, []string{backups[0].Key, backups[1].Key, backups[2].Key}, []string{
+		"loft/prod/loft-backup-20230601T140000Z.yaml",
+		"loft/prod/loft-backup-20230601T130000Z.yaml",
+		"loft/prod/loft-backup-20230601T120000Z.yaml",
+	})
+
+	reader, err := client.Get(ctx, target.Bucket, backups[0].Key)
+	assert.NilError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Close())
+	assert.Equal(t, string(content), "backup c")
+
+	assert.NilError(t, client.Delete(ctx, target.Bucket, backups[2].Key))
+	_, err = client.Get(ctx, target.Bucket, backups[2].Key)
+	assert.ErrorContains(t, err, "NoSuchKey")
+
+	wrongClient := *client
+	wrongClient.AccessKeyID = "wrong"
+	_, err = wrongClient.List(ctx, target.Bucket, target.Key(""))
+	assert.ErrorContains(t, err, "list "+bucket)
+}
+
+func TestS3Client(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: map[string]string{}})
+	defer server.Close()
+
+	testS3Client(t, &S3Client{Endpoint: server.URL, AccessKeyID: "access", SecretAccessKey: "secret"}, "backups")
+
+	_, err := ParseS3Target("backups/loft")
+	assert.ErrorContains(t, err, "s3://bucket/prefix")
+
+	client := &S3Client{Endpoint: server.URL + "/storage", AccessKeyID: "access", SecretAccessKey: "secret"}
+	_, err = client.List(context.Background(), "backups", "")
+	assert.ErrorContains(t, err, "must not have a path")
+}
+
+// TestS3ClientMinIO runs against a MinIO server, which is started if the minio binary is found
+func TestS3ClientMinIO(t *testing.T) {
+	minioPath, err := exec.LookPath("minio")
+	if err != nil {
+		t.Skip("minio not found")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	address := listener.Addr().String()
+	assert.NilError(t, listener.Close())
+
+	server := exec.Command(minioPath, "server", t.TempDir(), "--address", address, "--console-address", "127.0.0.1:0", "--quiet")
+	server.Env = append(os.Environ(), "MINIO_ROOT_USER=loft-access", "MINIO_ROOT_PASSWORD=loft-secret")
+	assert.NilError(t, server.Start())
+	defer func() {
+		_ = server.Process.Kill()
+		_ = server.Wait()
+	}()
+
+	adminClient, err := minio.New(address, &minio.Options{Creds: credentials.NewStaticV4("loft-access", "loft-secret", ""), Region: DefaultS3Region})
+	assert.NilError(t, err)
+	ctx := context.Background()
+	for start := time.Now(); ; time.Sleep(200 * time.Millisecond) {
+		err = adminClient.MakeBucket(ctx, "backups", minio.MakeBucketOptions{Region: DefaultS3Region})
+		if err == nil {
+			break
+		}
+		assert.Assert(t, time.Since(start) < 30*time.Second, "minio didn't start: %v", err)
+	}
+
+	client := &S3Client{Endpoint: "http://" + address, AccessKeyID: "loft-access", SecretAccessKey: "loft-secret"}
+	testS3Client(t, client, "backups")
+
+	// backups of unknown size are uploaded in parts
+	content := bytes.Repeat([]byte("loft"), 5<<20)
+	assert.NilError(t, client.Put(ctx, "backups", "large.yaml", bytes.NewReader(content), -1))
+	reader, err := client.Get(ctx, "backups", "large.yaml")
+	assert.NilError(t, err)
+	defer reader.Close()
+	downloaded, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Assert(t, bytes.Equal(downloaded, content), fmt.Sprintf("downloaded %d of %d bytes", len(downloaded), len(content)))
+}
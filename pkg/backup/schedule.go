@@ -0,0 +1,124 @@
+package backup
+
+import (
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ScheduleLabel is set on all objects of a backup schedule and the jobs it runs
+const ScheduleLabel = "loft.sh/backup-schedule"
+
+// DefaultScheduleImage is the image with the loft cli that runs scheduled backups
+const DefaultScheduleImage = "ghcr.io/loft-sh/loftctl"
+
+// ScheduleOptions describe a backup that runs periodically in the cluster loft runs in
+type ScheduleOptions struct {
+	Name      string
+	Namespace string
+	Cron      string
+	Image     string
+
+	// Args are the flags passed to loft backup
+	Args []string
+	// Env are the environment variables stored in the secret of the schedule, e.g. credentials
+	Env map[string]string
+}
+
+// ClusterName returns the name of the cluster scoped objects of the schedule. It contains the
+// namespace, so schedules in different namespaces don't conflict.
+func (o *ScheduleOptions) ClusterName() string {
+	return o.Namespace + "-" + o.Name
+}
+
+// ScheduleObjects returns the objects to run the backup periodically: a service account that can
+// only read the backed up resources, a secret with the environment and a cron job.
+func ScheduleObjects(options ScheduleOptions) []runtime.Object {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "loft-backup",
+		"app.kubernetes.io/managed-by": "loft",
+		ScheduleLabel:                  options.Name,
+	}
+	objectMeta := func(name, namespace string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}
+	}
+
+	serviceAccount := &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: objectMeta(options.Name, options.Namespace),
+	}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: options.Name, Namespace: options.Namespace}}
+	clusterRole := &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+		ObjectMeta: objectMeta(options.ClusterName(), ""),
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{storagev1.SchemeGroupVersion.Group}, Resources: []string{"*"}, Verbs: []string{"get", "list"}},
+			// referenced secrets and project secrets are stored in different namespaces
+			{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}},
+		},
+	}
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+		ObjectMeta: objectMeta(options.ClusterName(), ""),
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: options.ClusterName()},
+		Subjects:   subjects,
+	}
+	// loft backup checks if loft is installed in the namespace
+	role := &rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+		ObjectMeta: objectMeta(options.Name, options.Namespace),
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"loft"}, Verbs: []string{"get"}},
+		},
+	}
+	roleBinding := &rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+		ObjectMeta: objectMeta(options.Name, options.Namespace),
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: options.Name},
+		Subjects:   subjects,
+	}
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: objectMeta(options.Name, options.Namespace),
+		StringData: options.Env,
+	}
+
+	backoffLimit := int32(0)
+	historyLimit := int32(3)
+	cronJob := &batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+		ObjectMeta: objectMeta(options.Name, options.Namespace),
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   options.Cron,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							ServiceAccountName: options.Name,
+							RestartPolicy:      corev1.RestartPolicyNever,
+							Containers: []corev1.Container{{
+								Name:    "backup",
+								Image:   options.Image,
+								Command: append([]string{"loft", "backup"}, options.Args...),
+								EnvFrom: []corev1.EnvFromSource{{
+									SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: options.Name}},
+								}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return []runtime.Object{serviceAccount, clusterRole, clusterRoleBinding, role, roleBinding, secret, cronJob}
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestScheduleObjects(t *testing.T) {
+	objects := ScheduleObjects(ScheduleOptions{
+		Name:      "loft-backup",
+		Namespace: "loft",
+		Cron:      "0 3 * * *",
+		Image:     DefaultScheduleImage + ":3.3.0",
+		Args:      []string{"--target=s3://backups/loft", "--keep=7"},
+		Env:       map[string]string{"AWS_ACCESS_KEY_ID": "access", "AWS_SECRET_ACCESS_KEY": "secret"},
+	})
+	assert.Equal(t, len(objects), 7)
+
+	// the service account can only read
+	for _, object := range objects {
+		rules := []rbacv1.PolicyRule{}
+		switch role := object.(type) {
+		case *rbacv1.ClusterRole:
+			assert.Equal(t, role.Name, "loft-loft-backup")
+			rules = role.Rules
+		case *rbacv1.Role:
+			rules = role.Rules
+		}
+
+		for _, rule := range rules {
+			for _, verb := range rule.Verbs {
+				assert.Assert(t, verb == "get" || verb == "list", "unexpected verb %s", verb)
+			}
+		}
+	}
+
+	secret := objects[5].(*corev1.Secret)
+	assert.Equal(t, secret.StringData["AWS_SECRET_ACCESS_KEY"], "secret")
+
+	cronJob := objects[6].(*batchv1.CronJob)
+	assert.Equal(t, cronJob.Spec.Schedule, "0 3 * * *")
+	assert.Equal(t, cronJob.Spec.JobTemplate.Labels[ScheduleLabel], "loft-backup")
+	podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, podSpec.ServiceAccountName, "loft-backup")
+	assert.DeepEqual(t, podSpec.Containers[0].Command, []string{"loft", "backup", "--target=s3://backups/loft", "--keep=7"})
+	assert.Equal(t, podSpec.Containers[0].EnvFrom[0].SecretRef.Name, "loft-backup")
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	perrors "github.com/pkg/errors"
+)
+
+// CacheDir returns the directory credentials of the given loft config are cached in. Every
+// config has its own directory, so credentials of different logins are never mixed up.
+func CacheDir(configPath string) (string, error) {
+	absConfigPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(absConfigPath), filepath.Ext(absConfigPath))
+	return filepath.Join(filepath.Dir(absConfigPath), "cache", name), nil
+}
+
+// CachedCertificate describes an access point certificate in the cache
+type CachedCertificate struct {
+	Context        string
+	Project        string
+	VirtualCluster string
+
+	// Subject is the common name and organizations of the certificate
+	Subject       string
+	TTL           int32
+	LastRequested time.Time
+	NotAfter      time.Time
+}
+
+// CachedCertificates returns the certificates that are cached for the given loft config sorted
+// by context. Expired certificates are removed.
+func CachedCertificates(configPath string) ([]CachedCertificate, error) {
+	cache, err := newCredentialCache(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cache.gc()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(cache.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := []CachedCertificate{}
+	for _, file := range files {
+		contextName, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue
+		}
+
+		entry, err := cache.get(contextName)
+		if err != nil {
+			return nil, err
+		} else if entry == nil {
+			continue
+		}
+
+		certificate := CachedCertificate{
+			Context:        contextName,
+			Project:        entry.Project,
+			VirtualCluster: entry.VirtualCluster,
+			TTL:            entry.TTL,
+			LastRequested:  entry.LastRequested.Time,
+			NotAfter:       entry.ExpirationTime,
+		}
+		if parsed, err := parseCertificate(entry.CertificateData); err == nil {
+			certificate.Subject = "CN=" + parsed.Subject.CommonName
+			if len(parsed.Subject.Organization) > 0 {
+				certificate.Subject += ",O=" + strings.Join(parsed.Subject.Organization, "+")
+			}
+			certificate.NotAfter = parsed.NotAfter
+		}
+
+		certificates = append(certificates, certificate)
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].Context < certificates[j].Context
+	})
+	return certificates, nil
+}
+
+// RemoveCachedCertificate removes the cached certificate of the context. It returns false if no
+// certificate was cached.
+func RemoveCachedCertificate(configPath, contextName string) (bool, error) {
+	cache, err := newCredentialCache(configPath)
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Remove(cache.path(contextName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func parseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, perrors.New("no pem encoded certificate found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// credentialCache stores one file per kube context in a directory
+type credentialCache struct {
+	dir string
+}
+
+func newCredentialCache(configPath string) (*credentialCache, error) {
+	dir, err := CacheDir(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialCache{dir: dir}, nil
+}
+
+// path returns the file of the context. The name is escaped, so names with path separators or
+// ".." can't point outside of the cache directory.
+func (c *credentialCache) path(contextName string) string {
+	return filepath.Join(c.dir, url.PathEscape(contextName)+".json")
+}
+
+// get returns the cached entry of the context if it exists and isn't expired
+func (c *credentialCache) get(contextName string) (*VirtualClusterCertificatesEntry, error) {
+	content, err := os.ReadFile(c.path(contextName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// entries that can't be parsed are treated as missing and replaced by the next set
+	entry := &VirtualClusterCertificatesEntry{}
+	err = json.Unmarshal(content, entry)
+	if err != nil || !entry.ExpirationTime.After(time.Now()) {
+		return nil, nil
+	}
+
+	return entry, nil
+}
+
+// set writes the entry of the context and removes all expired entries. The entry is written to a
+// temporary file that is renamed, so concurrent token commands never read a partially written entry.
+func (c *credentialCache) set(contextName string, entry VirtualClusterCertificatesEntry) error {
+	err := os.MkdirAll(c.dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(c.dir, ".credentials-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(out)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(file.Name(), c.path(contextName))
+	if err != nil {
+		return err
+	}
+
+	return c.gc()
+}
+
+// gc removes all expired entries. Entries that can't be parsed are kept until the next set of
+// their context replaces them.
+func (c *credentialCache) gc() error {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, file := range files {
+		entry := &VirtualClusterCertificatesEntry{}
+		content, err := os.ReadFile(file)
+		if err == nil {
+			err = json.Unmarshal(content, entry)
+		}
+		if err == nil && !entry.ExpirationTime.After(now) {
+			err = os.Remove(file)
+			if err != nil && !os.IsNotExist(err) {
+				return perrors.Wrapf(err, "remove expired credentials %s", file)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/pkg/kubeconfig"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestCredentialCache(t *testing.T) {
+	cache := &credentialCache{dir: t.TempDir()}
+
+	entry, err := cache.get("loft-vcluster_a_default")
+	assert.NilError(t, err)
+	assert.Assert(t, entry == nil)
+
+	// an expired entry is collected once another one is written
+	err = os.WriteFile(cache.path("loft-vcluster_old_default"), []byte(`{"ExpirationTime":"2000-01-01T00:00:00Z"}`), 0600)
+	assert.NilError(t, err)
+	err = cache.set("loft-vcluster_a_default", VirtualClusterCertificatesEntry{CertificateData: "cert", KeyData: "key", ExpirationTime: time.Now().Add(time.Hour)})
+	assert.NilError(t, err)
+	_, err = os.Stat(cache.path("loft-vcluster_old_default"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	entry, err = cache.get("loft-vcluster_a_default")
+	assert.NilError(t, err)
+	assert.Equal(t, entry.CertificateData, "cert")
+	assert.Equal(t, entry.KeyData, "key")
+}
+
+func TestCredentialCacheConcurrent(t *testing.T) {
+	cache := &credentialCache{dir: t.TempDir()}
+
+	// an entry that can't be parsed is a cache miss, but isn't removed
+	err := os.WriteFile(cache.path("loft-vcluster_broken_default"), []byte(`{"Certificate`), 0600)
+	assert.NilError(t, err)
+	entry, err := cache.get("loft-vcluster_broken_default")
+	assert.NilError(t, err)
+	assert.Assert(t, entry == nil)
+	assert.NilError(t, cache.gc())
+	_, err = os.Stat(cache.path("loft-vcluster_broken_default"))
+	assert.NilError(t, err)
+
+	// readers never see a partially written entry while it is replaced
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 100)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			errs <- cache.set("loft-vcluster_a_default", VirtualClusterCertificatesEntry{CertificateData: fmt.Sprintf("cert-%d", i), ExpirationTime: time.Now().Add(time.Hour)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				content, err := os.ReadFile(cache.path("loft-vcluster_a_default"))
+				if err == nil && !json.Valid(content) {
+					errs <- fmt.Errorf("read partial entry %q", content)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NilError(t, err)
+	}
+
+	entry, err = cache.get("loft-vcluster_a_default")
+	assert.NilError(t, err)
+	assert.Assert(t, entry != nil)
+	files, err := filepath.Glob(filepath.Join(cache.dir, "*"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 2, "temporary files should be removed")
+}
+
+func TestMigrateCertificates(t *testing.T) {
+	dir := t.TempDir()
+
+	// the project and virtual cluster are read from the context, as names can contain underscores
+	t.Setenv(clientcmd.RecommendedConfigPathEnvVar, filepath.Join(dir, "kubeconfig"))
+	contextName := kubeconfig.VirtualClusterInstanceContextName("my_project", "my_vcluster")
+	assert.NilError(t, kubeconfig.UpdateKubeConfig(kubeconfig.ContextOptions{
+		Name:   contextName,
+		Server: "https://my-vcluster.example.com",
+		Token:  "token",
+		Metadata: kubeconfig.ContextMetadata{
+			Kind:    kubeconfig.ContextKindVirtualClusterInstance,
+			Project: "my_project",
+			Name:    "my_vcluster",
+		},
+	}))
+
+	configPath := filepath.Join(dir, "config.json")
+	config := &Config{
+		Host: "https://loft.example.com",
+		VirtualClusterAccessPointCertificates: map[string]VirtualClusterCertificatesEntry{
+			"loft-vcluster_a_default": {CertificateData: "cert", LastRequested: metav1.Now(), ExpirationTime: time.Now().Add(time.Hour)},
+			"loft-vcluster_b_default": {CertificateData: "expired", ExpirationTime: time.Now().Add(-time.Hour)},
+			contextName:               {CertificateData: "cert", LastRequested: metav1.Now(), ExpirationTime: time.Now().Add(time.Hour)},
+		},
+	}
+	out, err := json.Marshal(config)
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(configPath, out, 0600))
+
+	_, err = NewClientFromPath(configPath)
+	assert.NilError(t, err)
+
+	out, err = os.ReadFile(configPath)
+	assert.NilError(t, err)
+	migrated := &Config{}
+	assert.NilError(t, json.Unmarshal(out, migrated))
+	assert.Equal(t, len(migrated.VirtualClusterAccessPointCertificates), 0)
+	assert.Equal(t, migrated.Host, "https://loft.example.com")
+
+	files, err := filepath.Glob(filepath.Join(dir, "cache", "config", "*.json"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{
+		filepath.Join(dir, "cache", "config", "loft-vcluster_a_default.json"),
+		filepath.Join(dir, "cache", "config", contextName+".json"),
+	})
+
+	cache := &credentialCache{dir: filepath.Join(dir, "cache", "config")}
+	entry, err := cache.get(contextName)
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Project, "my_project")
+	assert.Equal(t, entry.VirtualCluster, "my_vcluster")
+
+	// contexts that don't exist anymore aren't parsed from the name
+	entry, err = cache.get("loft-vcluster_a_default")
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Project, "")
+}
+
+func TestCachedCertificates(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	cache, err := newCredentialCache(configPath)
+	assert.NilError(t, err)
+
+	notAfter := time.Now().Add(30 * time.Minute).Truncate(time.Second).UTC()
+	err = cache.set("loft-vcluster_b_default", VirtualClusterCertificatesEntry{
+		Project:         "default",
+		VirtualCluster:  "b",
+		CertificateData: testCertificate(t, notAfter),
+		TTL:             3600,
+		ExpirationTime:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+	err = cache.set("loft-vcluster_a_default", VirtualClusterCertificatesEntry{CertificateData: "invalid", ExpirationTime: notAfter})
+	assert.NilError(t, err)
+
+	certificates, err := CachedCertificates(configPath)
+	assert.NilError(t, err)
+	assert.Equal(t, len(certificates), 2)
+	assert.Equal(t, certificates[0].Context, "loft-vcluster_a_default")
+	assert.Equal(t, certificates[0].Subject, "")
+	assert.Equal(t, certificates[1].Context, "loft-vcluster_b_default")
+	assert.Equal(t, certificates[1].Subject, "CN=loft:user:admin,O=system:masters")
+	assert.Equal(t, certificates[1].VirtualCluster, "b")
+	assert.Equal(t, certificates[1].TTL, int32(3600))
+	assert.Assert(t, certificates[1].NotAfter.Equal(notAfter), "the expiry of the certificate is preferred")
+
+	removed, err := RemoveCachedCertificate(configPath, "loft-vcluster_a_default")
+	assert.NilError(t, err)
+	assert.Assert(t, removed)
+	removed, err = RemoveCachedCertificate(configPath, "loft-vcluster_a_default")
+	assert.NilError(t, err)
+	assert.Assert(t, !removed)
+
+	// context names can't point outside of the cache directory
+	assert.NilError(t, os.WriteFile(configPath, []byte("{}"), 0600))
+	removed, err = RemoveCachedCertificate(configPath, "../../config")
+	assert.NilError(t, err)
+	assert.Assert(t, !removed)
+	_, err = os.Stat(configPath)
+	assert.NilError(t, err)
+
+	err = cache.set("../my/context", VirtualClusterCertificatesEntry{CertificateData: "cert", ExpirationTime: notAfter})
+	assert.NilError(t, err)
+	assert.Equal(t, filepath.Dir(cache.path("../my/context")), cache.dir)
+	certificates, err = CachedCertificates(configPath)
+	assert.NilError(t, err)
+	assert.Equal(t, certificates[0].Context, "../my/context")
+	removed, err = RemoveCachedCertificate(configPath, "../my/context")
+	assert.NilError(t, err)
+	assert.Assert(t, removed)
+}
+
+func testCertificate(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "loft:user:admin", Organization: []string{"system:masters"}},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -81,6 +81,7 @@ type Client interface {
 	Config() *Config
 	DirectClusterEndpointToken(forceRefresh bool) (string, error)
 	VirtualClusterAccessPointCertificate(project, virtualCluster string, forceRefresh bool) (string, string, error)
+	VirtualClusterAccessPointCertificateWithTTL(project, virtualCluster string, ttl int32) (string, string, error)
 	Save() error
 }
 
@@ -107,6 +108,9 @@ type client struct {
 	configOnce sync.Once
 	configPath string
 	config     *Config
+
+	// cache holds the credentials per kube context, it is nil if the config isn't stored
+	cache *credentialCache
 }
 
 func (c *client) initConfig() error {
@@ -135,8 +139,43 @@ func (c *client) initConfig() error {
 
 		c.config = config
 	})
+	if retErr != nil || c.configPath == "" || c.cache != nil {
+		return retErr
+	}
+
+	cache, err := newCredentialCache(c.configPath)
+	if err != nil {
+		return err
+	}
+
+	c.cache = cache
+	return c.migrateCertificates()
+}
+
+// migrateCertificates moves certificates that were cached in the config into the cache directory
+func (c *client) migrateCertificates() error {
+	if len(c.config.VirtualClusterAccessPointCertificates) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for contextName, entry := range c.config.VirtualClusterAccessPointCertificates {
+		if entry.Project == "" && entry.VirtualCluster == "" {
+			metadata, ok := kubeconfig.LoadLoftContext(contextName)
+			if ok && metadata.Kind == kubeconfig.ContextKindVirtualClusterInstance {
+				entry.VirtualCluster, entry.Project = metadata.Name, metadata.Project
+			}
+		}
+		if entry.ExpirationTime.After(now) {
+			err := c.cache.set(contextName, entry)
+			if err != nil {
+				return perrors.Wrap(err, "migrate cached certificates")
+			}
+		}
+	}
 
-	return retErr
+	c.config.VirtualClusterAccessPointCertificates = nil
+	return c.Save()
 }
 
 func (c *client) VirtualClusterAccessPointCertificate(project, virtualCluster string, forceRefresh bool) (string, string, error) {
@@ -148,23 +187,53 @@ func (c *client) VirtualClusterAccessPointCertificate(project, virtualCluster st
 
 	// see if we have stored cert data for this vci
 	now := metav1.Now()
-	cachedVirtualClusterAccessPointCertificate, ok := c.config.VirtualClusterAccessPointCertificates[contextName]
-	if !forceRefresh && ok && cachedVirtualClusterAccessPointCertificate.LastRequested.Add(RefreshToken).After(now.Time) && cachedVirtualClusterAccessPointCertificate.ExpirationTime.After(now.Time) {
+	cachedVirtualClusterAccessPointCertificate, err := c.cachedCertificate(contextName)
+	if err != nil {
+		return "", "", err
+	}
+	if !forceRefresh && cachedVirtualClusterAccessPointCertificate != nil && cachedVirtualClusterAccessPointCertificate.LastRequested.Add(RefreshToken).After(now.Time) && cachedVirtualClusterAccessPointCertificate.ExpirationTime.After(now.Time) {
 		return cachedVirtualClusterAccessPointCertificate.CertificateData, cachedVirtualClusterAccessPointCertificate.KeyData, nil
 	}
 
-	// refresh token
+	// a ttl requested explicitly for this virtual cluster is kept across refreshes
+	ttl := int32(0)
+	if cachedVirtualClusterAccessPointCertificate != nil {
+		ttl = cachedVirtualClusterAccessPointCertificate.TTL
+	}
+
+	return c.fetchVirtualClusterAccessPointCertificate(project, virtualCluster, ttl)
+}
+
+func (c *client) VirtualClusterAccessPointCertificateWithTTL(project, virtualCluster string, ttl int32) (string, string, error) {
+	if c.config == nil {
+		return "", "", perrors.New("no config loaded")
+	} else if ttl <= 0 {
+		return "", "", perrors.New("certificate ttl must be positive")
+	}
+
+	return c.fetchVirtualClusterAccessPointCertificate(project, virtualCluster, ttl)
+}
+
+// fetchVirtualClusterAccessPointCertificate requests a new certificate and caches it. If ttl is 0
+// the ttl of the config is used.
+func (c *client) fetchVirtualClusterAccessPointCertificate(project, virtualCluster string, ttl int32) (string, string, error) {
 	managementClient, err := c.Management()
 	if err != nil {
 		return "", "", err
 	}
 
+	certificateTTL := ttl
+	if certificateTTL == 0 {
+		certificateTTL = c.config.CertificateTTL()
+	}
+
+	now := metav1.Now()
 	kubeConfigResponse, err := managementClient.Loft().ManagementV1().VirtualClusterInstances(naming.ProjectNamespace(project)).GetKubeConfig(
 		context.Background(),
 		virtualCluster,
 		&managementv1.VirtualClusterInstanceKubeConfig{
 			Spec: managementv1.VirtualClusterInstanceKubeConfigSpec{
-				CertificateTTL: pointer.Int32(86_400),
+				CertificateTTL: pointer.Int32(certificateTTL),
 			},
 		},
 		metav1.CreateOptions{},
@@ -178,24 +247,54 @@ func (c *client) VirtualClusterAccessPointCertificate(project, virtualCluster st
 		return "", "", err
 	}
 
-	if c.config.VirtualClusterAccessPointCertificates == nil {
-		c.config.VirtualClusterAccessPointCertificates = make(map[string]VirtualClusterCertificatesEntry)
+	// prefer the expiry of the certificate as the server might shorten the ttl
+	expirationTime := now.Add(time.Duration(certificateTTL) * time.Second)
+	if certificate, err := parseCertificate(certificateData); err == nil && certificate.NotAfter.Before(expirationTime) {
+		expirationTime = certificate.NotAfter
 	}
-	c.config.VirtualClusterAccessPointCertificates[contextName] = VirtualClusterCertificatesEntry{
+
+	err = c.cacheCertificate(kubeconfig.VirtualClusterInstanceContextName(project, virtualCluster), VirtualClusterCertificatesEntry{
+		Project:         project,
+		VirtualCluster:  virtualCluster,
 		CertificateData: certificateData,
 		KeyData:         keyData,
+		TTL:             ttl,
 		LastRequested:   now,
-		ExpirationTime:  now.Add(86_400 * time.Second),
-	}
-
-	err = c.Save()
+		ExpirationTime:  expirationTime,
+	})
 	if err != nil {
-		return "", "", perrors.Wrap(err, "save config")
+		return "", "", perrors.Wrap(err, "cache certificate")
 	}
 
 	return certificateData, keyData, nil
 }
 
+func (c *client) cachedCertificate(contextName string) (*VirtualClusterCertificatesEntry, error) {
+	if c.cache != nil {
+		return c.cache.get(contextName)
+	}
+
+	entry, ok := c.config.VirtualClusterAccessPointCertificates[contextName]
+	if !ok {
+		return nil, nil
+	}
+
+	return &entry, nil
+}
+
+func (c *client) cacheCertificate(contextName string, entry VirtualClusterCertificatesEntry) error {
+	if c.cache != nil {
+		return c.cache.set(contextName, entry)
+	}
+
+	// without a config path the certificates are only kept in memory
+	if c.config.VirtualClusterAccessPointCertificates == nil {
+		c.config.VirtualClusterAccessPointCertificates = make(map[string]VirtualClusterCertificatesEntry)
+	}
+	c.config.VirtualClusterAccessPointCertificates[contextName] = entry
+	return nil
+}
+
 func getCertificateAndKeyDataFromKubeConfig(config string) (string, string, error) {
 	clientCfg, err := clientcmd.NewClientConfigFromBytes([]byte(config))
 	if err != nil {
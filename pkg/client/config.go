@@ -34,16 +34,38 @@ type Config struct {
 	// +optional
 	DirectClusterEndpointTokenRequested *metav1.Time `json:"directClusterEndpointTokenRequested,omitempty"`
 
-	// map of cached certificates for "access point" mode virtual clusters
+	// the ttl in seconds of certificates requested for virtual clusters with an access point
 	// +optional
-	VirtualClusterAccessPointCertificates map[string]VirtualClusterCertificatesEntry
+	AccessPointCertificateTTL int32 `json:"accessPointCertificateTTL,omitempty"`
+
+	// map of cached certificates for "access point" mode virtual clusters. Certificates are now
+	// cached per context in the cache directory, this is only read to migrate older configs.
+	// +optional
+	VirtualClusterAccessPointCertificates map[string]VirtualClusterCertificatesEntry `json:"VirtualClusterAccessPointCertificates,omitempty"`
 }
 
+// DefaultAccessPointCertificateTTL is the ttl in seconds of access point certificates if the
+// config doesn't define one
+const DefaultAccessPointCertificateTTL int32 = 86_400
+
 type VirtualClusterCertificatesEntry struct {
+	Project         string `json:",omitempty"`
+	VirtualCluster  string `json:",omitempty"`
 	CertificateData string
 	KeyData         string
-	LastRequested   metav1.Time
-	ExpirationTime  time.Time
+	// TTL is set if the certificate was requested with an explicit ttl
+	TTL            int32 `json:",omitempty"`
+	LastRequested  metav1.Time
+	ExpirationTime time.Time
+}
+
+// CertificateTTL returns the ttl in seconds to request access point certificates with
+func (c *Config) CertificateTTL() int32 {
+	if c.AccessPointCertificateTTL > 0 {
+		return c.AccessPointCertificateTTL
+	}
+
+	return DefaultAccessPointCertificateTTL
 }
 
 // NewConfig creates a new config
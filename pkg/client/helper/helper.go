@@ -791,6 +791,51 @@ func GetSpaceInstances(baseClient client.Client) ([]ProjectSpace, error) {
 	return retSpaces, nil
 }
 
+type ProjectDevPodWorkspace struct {
+	DevPodWorkspaceInstance managementv1.DevPodWorkspaceInstance
+	Project                 string
+}
+
+func CanAccessDevPodWorkspaceInstance(managementClient kube.Interface, namespace, name string) (bool, error) {
+	return canAccessInstance(managementClient, namespace, name, "devpodworkspaceinstances")
+}
+
+func GetDevPodWorkspaceInstances(baseClient client.Client) ([]ProjectDevPodWorkspace, error) {
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return nil, err
+	}
+
+	projectList, err := managementClient.Loft().ManagementV1().Projects().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	retWorkspaces := []ProjectDevPodWorkspace{}
+	for _, project := range projectList.Items {
+		workspaceInstances, err := managementClient.Loft().ManagementV1().DevPodWorkspaceInstances(naming.ProjectNamespace(project.Name)).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, workspaceInstance := range workspaceInstances.Items {
+			canAccess, err := CanAccessDevPodWorkspaceInstance(managementClient, workspaceInstance.Namespace, workspaceInstance.Name)
+			if err != nil {
+				return nil, err
+			} else if !canAccess {
+				continue
+			}
+
+			retWorkspaces = append(retWorkspaces, ProjectDevPodWorkspace{
+				DevPodWorkspaceInstance: workspaceInstance,
+				Project:                 project.Name,
+			})
+		}
+	}
+
+	return retWorkspaces, nil
+}
+
 type ProjectProjectSecret struct {
 	ProjectSecret managementv1.ProjectSecret
 	Project       string
@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/pkg/kube"
+	"github.com/loft-sh/loftctl/v3/pkg/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TemplateKindVirtualCluster  = "vcluster"
+	TemplateKindSpace           = "space"
+	TemplateKindDevPodWorkspace = "devpod"
+)
+
+// TemplateKinds are all template kinds that can be used with FindProjectTemplate
+var TemplateKinds = []string{TemplateKindVirtualCluster, TemplateKindSpace, TemplateKindDevPodWorkspace}
+
+// ProjectTemplate is a template of any kind that is allowed in a project
+type ProjectTemplate struct {
+	Kind        string
+	Name        string
+	DisplayName string
+
+	// Versions holds the versions of the template, might be empty
+	Versions storagev1.VersionsAccessor
+
+	// Parameters are the parameters of a template without versions
+	Parameters []storagev1.AppParameter
+}
+
+// FindProjectTemplate finds the template with the given name in the allowed templates of the project. If
+// kind is empty, all template kinds are searched and the name needs to be unique across them.
+func FindProjectTemplate(ctx context.Context, managementClient kube.Interface, projectName, kind, templateName string) (*ProjectTemplate, error) {
+	projectTemplates, err := managementClient.Loft().ManagementV1().Projects().ListTemplates(ctx, projectName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+
+	found := []*ProjectTemplate{}
+	if kind == "" || kind == TemplateKindVirtualCluster {
+		for i, template := range projectTemplates.VirtualClusterTemplates {
+			if template.Name == templateName {
+				found = append(found, &ProjectTemplate{
+					Kind:        TemplateKindVirtualCluster,
+					Name:        template.Name,
+					DisplayName: template.Spec.DisplayName,
+					Versions:    &projectTemplates.VirtualClusterTemplates[i],
+					Parameters:  template.Spec.Parameters,
+				})
+			}
+		}
+	}
+	if kind == "" || kind == TemplateKindSpace {
+		for i, template := range projectTemplates.SpaceTemplates {
+			if template.Name == templateName {
+				found = append(found, &ProjectTemplate{
+					Kind:        TemplateKindSpace,
+					Name:        template.Name,
+					DisplayName: template.Spec.DisplayName,
+					Versions:    &projectTemplates.SpaceTemplates[i],
+					Parameters:  template.Spec.Parameters,
+				})
+			}
+		}
+	}
+	if kind == "" || kind == TemplateKindDevPodWorkspace {
+		for i, template := range projectTemplates.DevPodWorkspaceTemplates {
+			if template.Name == templateName {
+				found = append(found, &ProjectTemplate{
+					Kind:        TemplateKindDevPodWorkspace,
+					Name:        template.Name,
+					DisplayName: template.Spec.DisplayName,
+					Versions:    &projectTemplates.DevPodWorkspaceTemplates[i],
+					Parameters:  template.Spec.Parameters,
+				})
+			}
+		}
+	}
+	if kind != "" && kind != TemplateKindVirtualCluster && kind != TemplateKindSpace && kind != TemplateKindDevPodWorkspace {
+		return nil, fmt.Errorf("unknown template kind %s, expected one of: %s", kind, strings.Join(TemplateKinds, ", "))
+	} else if len(found) == 0 {
+		return nil, fmt.Errorf("couldn't find template %s as allowed template in project %s", templateName, projectName)
+	} else if len(found) > 1 {
+		return nil, fmt.Errorf("there are multiple templates with name %s in project %s, please specify the template kind", templateName, projectName)
+	}
+
+	return found[0], nil
+}
+
+// GetParameters returns the parameters of the template version matching the given pattern. An empty
+// pattern or latest selects the latest version. The resolved version is empty for templates without versions.
+func (t *ProjectTemplate) GetParameters(versionPattern string) ([]storagev1.AppParameter, string, error) {
+	if versionPattern == "latest" {
+		versionPattern = ""
+	}
+	if len(t.Versions.GetVersions()) == 0 {
+		if versionPattern != "" {
+			return nil, "", fmt.Errorf("template %s doesn't have any versions", t.Name)
+		}
+
+		return t.Parameters, "", nil
+	}
+
+	_, resolvedVersion, err := version.ResolveVersion(t.Versions, versionPattern)
+	if err != nil {
+		return nil, "", err
+	} else if resolvedVersion == nil {
+		return nil, "", fmt.Errorf("couldn't find any matching version to %s", versionPattern)
+	}
+
+	switch v := resolvedVersion.(type) {
+	case *storagev1.VirtualClusterTemplateVersion:
+		return v.Parameters, v.Version, nil
+	case *storagev1.SpaceTemplateVersion:
+		return v.Parameters, v.Version, nil
+	case *storagev1.DevPodWorkspaceTemplateVersion:
+		return v.Parameters, v.Version, nil
+	}
+
+	return nil, "", fmt.Errorf("unrecognized version type %T", resolvedVersion)
+}
@@ -10,9 +10,6 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"os"
-	"os/exec"
-	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -37,7 +34,6 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/transport/spdy"
-	"k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset"
 )
 
 const defaultReleaseName = "loft"
@@ -343,118 +339,6 @@ func IsLoftAlreadyInstalled(kubeClient kubernetes.Interface, namespace string) (
 	return true, nil
 }
 
-func UninstallLoft(kubeClient kubernetes.Interface, restConfig *rest.Config, kubeContext, namespace string, log log.Logger) error {
-	log.Infof("Uninstalling loft...")
-	releaseName := defaultReleaseName
-	deploy, err := kubeClient.AppsV1().Deployments(namespace).Get(context.TODO(), "loft", metav1.GetOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	} else if deploy != nil && deploy.Labels != nil && deploy.Labels["release"] != "" {
-		releaseName = deploy.Labels["release"]
-	}
-
-	args := []string{
-		"uninstall",
-		releaseName,
-		"--kube-context",
-		kubeContext,
-		"--namespace",
-		namespace,
-	}
-	log.Infof("Executing command: helm %s", strings.Join(args, " "))
-	output, err := exec.Command("helm", args...).CombinedOutput()
-	if err != nil {
-		log.Errorf("error during helm command: %s (%v)", string(output), err)
-	}
-
-	// we also cleanup the validating webhook configuration and apiservice
-	apiRegistrationClient, err := clientset.NewForConfig(restConfig)
-	if err != nil {
-		return err
-	}
-
-	err = apiRegistrationClient.ApiregistrationV1().APIServices().Delete(context.TODO(), "v1.management.loft.sh", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	err = deleteUser(restConfig, "admin")
-	if err != nil {
-		return err
-	}
-
-	err = kubeClient.CoreV1().Secrets(namespace).Delete(context.Background(), "loft-user-secret-admin", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	err = kubeClient.CoreV1().Secrets(namespace).Delete(context.Background(), LoftRouterDomainSecret, metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	// we also cleanup the validating webhook configuration and apiservice
-	err = kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(context.TODO(), "loft-agent", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	err = apiRegistrationClient.ApiregistrationV1().APIServices().Delete(context.TODO(), "v1alpha1.tenancy.kiosk.sh", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	err = apiRegistrationClient.ApiregistrationV1().APIServices().Delete(context.TODO(), "v1.cluster.loft.sh", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	err = kubeClient.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), "loft-agent-controller", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	err = kubeClient.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), "loft-applied-defaults", metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	log.WriteString(logrus.InfoLevel, "\n")
-	log.Done("Successfully uninstalled Loft")
-	log.WriteString(logrus.InfoLevel, "\n")
-
-	return nil
-}
-
-func deleteUser(restConfig *rest.Config, name string) error {
-	loftClient, err := loftclientset.NewForConfig(restConfig)
-	if err != nil {
-		return err
-	}
-
-	user, err := loftClient.StorageV1().Users().Get(context.TODO(), name, metav1.GetOptions{})
-	if err != nil {
-		return nil
-	} else if len(user.Finalizers) > 0 {
-		user.Finalizers = nil
-		_, err = loftClient.StorageV1().Users().Update(context.TODO(), user, metav1.UpdateOptions{})
-		if err != nil {
-			if kerrors.IsConflict(err) {
-				return deleteUser(restConfig, name)
-			}
-
-			return err
-		}
-	}
-
-	err = loftClient.StorageV1().Users().Delete(context.TODO(), name, metav1.DeleteOptions{})
-	if err != nil && !kerrors.IsNotFound(err) {
-		return err
-	}
-
-	return nil
-}
-
 func EnsureIngressController(kubeClient kubernetes.Interface, kubeContext string, log log.Logger) error {
 	// first create an ingress controller
 	const (
@@ -475,29 +359,19 @@ func EnsureIngressController(kubeClient kubernetes.Interface, kubeContext string
 	}
 
 	if answer == YesOption {
-		args := []string{
-			"install",
-			"ingress-nginx",
-			"ingress-nginx",
-			"--repository-config=''",
-			"--repo",
-			"https://kubernetes.github.io/ingress-nginx",
-			"--kube-context",
-			kubeContext,
-			"--namespace",
-			"ingress-nginx",
-			"--create-namespace",
-			"--set-string",
-			"controller.config.hsts=false",
-			"--wait",
-		}
 		log.WriteString(logrus.InfoLevel, "\n")
-		log.Infof("Executing command: helm %s\n", strings.Join(args, " "))
 		log.Info("Waiting for ingress controller deployment, this can take several minutes...")
-		helmCmd := exec.Command("helm", args...)
-		output, err := helmCmd.CombinedOutput()
+		err = DefaultHelm.Upgrade(context.TODO(), HelmOptions{
+			Release:     "ingress-nginx",
+			Chart:       "ingress-nginx",
+			Repo:        "https://kubernetes.github.io/ingress-nginx",
+			KubeContext: kubeContext,
+			Namespace:   "ingress-nginx",
+			SetString:   []string{"controller.config.hsts=false"},
+			Wait:        true,
+		})
 		if err != nil {
-			return fmt.Errorf("error during helm command: %s (%w)", string(output), err)
+			return err
 		}
 
 		list, err := kubeClient.CoreV1().Secrets("ingress-nginx").List(context.TODO(), metav1.ListOptions{
@@ -540,100 +414,32 @@ func EnsureIngressController(kubeClient kubernetes.Interface, kubeContext string
 	return nil
 }
 
-func UpgradeLoft(chartName, chartRepo, kubeContext, namespace string, extraArgs []string, log log.Logger) error {
-	// now we install loft
-	args := []string{
-		"upgrade",
-		defaultReleaseName,
-		chartName,
-		"--install",
-		"--reuse-values",
-		"--create-namespace",
-		"--repository-config=''",
-		"--kube-context",
-		kubeContext,
-		"--namespace",
-		namespace,
+// UpgradeLoft installs or upgrades the loft release. An empty release name defaults to loft.
+func UpgradeLoft(options HelmOptions, log log.Logger) error {
+	if options.Release == "" {
+		options.Release = defaultReleaseName
 	}
-	if chartRepo != "" {
-		args = append(args, "--repo", chartRepo)
-	}
-	args = append(args, extraArgs...)
 
+	// now we install loft
 	log.WriteString(logrus.InfoLevel, "\n")
-	log.Infof("Executing command: helm %s\n", strings.Join(args, " "))
-	log.Info("Waiting for helm command, this can take up to several minutes...")
-	helmCmd := exec.Command("helm", args...)
-	if chartRepo != "" {
-		helmWorkDir, err := getHelmWorkdir(chartName)
-		if err != nil {
-			return err
-		}
-
-		helmCmd.Dir = helmWorkDir
-	}
-	output, err := helmCmd.CombinedOutput()
+	log.Infof("Installing helm release %s of chart %s into namespace %s\n", options.Release, options.Chart, options.Namespace)
+	log.Info("Waiting for helm, this can take up to several minutes...")
+	err := DefaultHelm.Upgrade(context.TODO(), options)
 	if err != nil {
-		return fmt.Errorf("error during helm command: %s (%w)", string(output), err)
+		return err
 	}
 
 	log.Done("Loft has been deployed to your cluster!")
 	return nil
 }
 
-func GetLoftManifests(chartName, chartRepo, kubeContext, namespace string, extraArgs []string, log log.Logger) (string, error) {
-	args := []string{
-		"template",
-		defaultReleaseName,
-		chartName,
-		"--repository-config=''",
-		"--kube-context",
-		kubeContext,
-		"--namespace",
-		namespace,
-	}
-	if chartRepo != "" {
-		args = append(args, "--repo", chartRepo)
-	}
-	args = append(args, extraArgs...)
-
-	helmCmd := exec.Command("helm", args...)
-	if chartRepo != "" {
-		helmWorkDir, err := getHelmWorkdir(chartName)
-		if err != nil {
-			return "", err
-		}
-
-		helmCmd.Dir = helmWorkDir
-	}
-	output, err := helmCmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("error during helm command: %s (%w)", string(output), err)
-	}
-	return string(output), nil
-}
-
-// Return the directory where the `helm` commands should be executed or error if none can be found/created
-// Uses current workdir by default unless it contains a folder with the chart name
-func getHelmWorkdir(chartName string) (string, error) {
-	// If chartName folder exists, check temp dir next
-	if _, err := os.Stat(chartName); err == nil {
-		tempDir := os.TempDir()
-
-		// If tempDir/chartName folder exists, create temp folder
-		if _, err := os.Stat(path.Join(tempDir, chartName)); err == nil {
-			tempDir, err = os.MkdirTemp(tempDir, chartName)
-			if err != nil {
-				return "", errors.New("problematic directory `" + chartName + "` found: please execute command in a different folder")
-			}
-		}
-
-		// Use tempDir
-		return tempDir, nil
+// GetLoftManifests renders the manifests of the loft chart
+func GetLoftManifests(options HelmOptions) (string, error) {
+	if options.Release == "" {
+		options.Release = defaultReleaseName
 	}
 
-	// Use current workdir
-	return "", nil
+	return DefaultHelm.Template(context.TODO(), options)
 }
 
 // Makes sure that admin user and password secret exists
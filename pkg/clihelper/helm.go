@@ -0,0 +1,359 @@
+package clihelper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/log"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// helmTimeout is how long helm waits for the resources of a release, the default of the helm cli
+const helmTimeout = 5 * time.Minute
+
+// HelmError is returned if a helm action fails
+type HelmError struct {
+	// Action is the helm action that failed, e.g. install, upgrade, template or uninstall
+	Action  string
+	Release string
+	Err     error
+}
+
+func (e *HelmError) Error() string {
+	return fmt.Sprintf("helm %s of release %s: %v", e.Action, e.Release, e.Err)
+}
+
+func (e *HelmError) Unwrap() error {
+	return e.Err
+}
+
+// HelmOptions describe the release a helm action works on
+type HelmOptions struct {
+	Release     string
+	Chart       string
+	Repo        string
+	Version     string
+	KubeContext string
+	Namespace   string
+	// KubeConfig is used instead of the kube context if set, e.g. for the config of a connected
+	// cluster that isn't part of the local kube config
+	KubeConfig []byte
+
+	// ValuesFiles are merged in order before Set
+	ValuesFiles []string
+	// Set are values in the format of helm --set, e.g. admin.password=secret
+	Set []string
+	// SetString are values in the format of helm --set-string
+	SetString []string
+	// ReuseValues merges the values of an existing release on upgrade
+	ReuseValues bool
+	// Wait waits until the resources of the release are ready on install and upgrade
+	Wait bool
+}
+
+// Helm installs, renders and uninstalls the loft chart
+type Helm interface {
+	// Upgrade installs the release or upgrades it if it exists
+	Upgrade(ctx context.Context, options HelmOptions) error
+	// Template returns the manifests of the chart without contacting the cluster
+	Template(ctx context.Context, options HelmOptions) (string, error)
+	// Uninstall removes the release
+	Uninstall(ctx context.Context, options HelmOptions) error
+	// ReleaseExists returns true if the release is installed
+	ReleaseExists(ctx context.Context, options HelmOptions) (bool, error)
+}
+
+// DefaultHelm is used by UpgradeLoft, GetLoftManifests and UninstallLoft
+var DefaultHelm Helm = NewHelmClient()
+
+// HelmClient runs helm actions with the helm Go SDK, so the helm binary isn't required
+type HelmClient struct {
+	// Configuration returns the helm configuration for the kube context or kube config and the
+	// namespace of the options. Releases are stored in secrets like the helm cli does.
+	Configuration func(options HelmOptions) (*action.Configuration, error)
+}
+
+// NewHelmClient creates a helm client for the clusters of the kube config
+func NewHelmClient() *HelmClient {
+	return &HelmClient{
+		Configuration: func(options HelmOptions) (*action.Configuration, error) {
+			var getter genericclioptions.RESTClientGetter = kube.GetConfig("", options.KubeContext, options.Namespace)
+			if len(options.KubeConfig) > 0 {
+				kubeConfig, err := clientcmd.Load(options.KubeConfig)
+				if err != nil {
+					return nil, err
+				}
+
+				getter = &kubeConfigGetter{clientConfig: clientcmd.NewDefaultClientConfig(*kubeConfig, &clientcmd.ConfigOverrides{
+					Context: clientcmdapi.Context{Namespace: options.Namespace},
+				})}
+			}
+
+			config := &action.Configuration{}
+			err := config.Init(getter, options.Namespace, "secret", helmDebugLog)
+			if err != nil {
+				return nil, err
+			}
+
+			return config, nil
+		},
+	}
+}
+
+// kubeConfigGetter provides the clients of helm for a kube config that isn't stored in a file
+type kubeConfigGetter struct {
+	clientConfig clientcmd.ClientConfig
+}
+
+func (g *kubeConfigGetter) ToRESTConfig() (*rest.Config, error) {
+	return g.clientConfig.ClientConfig()
+}
+
+func (g *kubeConfigGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	restConfig, err := g.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *kubeConfigGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient), discoveryClient), nil
+}
+
+func (g *kubeConfigGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return g.clientConfig
+}
+
+func helmDebugLog(format string, v ...interface{}) {
+	log.GetInstance().Debugf(format, v...)
+}
+
+func (h *HelmClient) Upgrade(ctx context.Context, options HelmOptions) error {
+	config, err := h.Configuration(options)
+	if err != nil {
+		return &HelmError{Action: "upgrade", Release: options.Release, Err: err}
+	}
+
+	chrt, vals, err := loadChart(options)
+	if err != nil {
+		return &HelmError{Action: "upgrade", Release: options.Release, Err: err}
+	}
+
+	history := action.NewHistory(config)
+	history.Max = 1
+	_, err = history.Run(options.Release)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		install := action.NewInstall(config)
+		install.ReleaseName = options.Release
+		install.Namespace = options.Namespace
+		install.CreateNamespace = true
+		install.Wait = options.Wait
+		install.Timeout = helmTimeout
+		_, err = install.RunWithContext(ctx, chrt, vals)
+		if err != nil {
+			return &HelmError{Action: "install", Release: options.Release, Err: err}
+		}
+
+		return nil
+	} else if err != nil {
+		return &HelmError{Action: "upgrade", Release: options.Release, Err: err}
+	}
+
+	upgrade := action.NewUpgrade(config)
+	upgrade.Namespace = options.Namespace
+	upgrade.ReuseValues = options.ReuseValues
+	upgrade.Wait = options.Wait
+	upgrade.Timeout = helmTimeout
+	_, err = upgrade.RunWithContext(ctx, options.Release, chrt, vals)
+	if err != nil {
+		return &HelmError{Action: "upgrade", Release: options.Release, Err: err}
+	}
+
+	return nil
+}
+
+func (h *HelmClient) Template(ctx context.Context, options HelmOptions) (string, error) {
+	chrt, vals, err := loadChart(options)
+	if err != nil {
+		return "", &HelmError{Action: "template", Release: options.Release, Err: err}
+	}
+
+	// a client only install renders the chart with an in memory release storage
+	install := action.NewInstall(&action.Configuration{Log: helmDebugLog})
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.ReleaseName = options.Release
+	install.Namespace = options.Namespace
+	rel, err := install.RunWithContext(ctx, chrt, vals)
+	if err != nil {
+		return "", &HelmError{Action: "template", Release: options.Release, Err: err}
+	}
+
+	manifests := &strings.Builder{}
+	manifests.WriteString(strings.TrimSpace(rel.Manifest))
+	manifests.WriteString("\n")
+	for _, hook := range rel.Hooks {
+		fmt.Fprintf(manifests, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+	}
+
+	return manifests.String(), nil
+}
+
+func (h *HelmClient) Uninstall(ctx context.Context, options HelmOptions) error {
+	config, err := h.Configuration(options)
+	if err != nil {
+		return &HelmError{Action: "uninstall", Release: options.Release, Err: err}
+	}
+
+	_, err = action.NewUninstall(config).Run(options.Release)
+	if err != nil {
+		return &HelmError{Action: "uninstall", Release: options.Release, Err: err}
+	}
+
+	return nil
+}
+
+func (h *HelmClient) ReleaseExists(ctx context.Context, options HelmOptions) (bool, error) {
+	config, err := h.Configuration(options)
+	if err != nil {
+		return false, &HelmError{Action: "history", Release: options.Release, Err: err}
+	}
+
+	history := action.NewHistory(config)
+	history.Max = 1
+	_, err = history.Run(options.Release)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, &HelmError{Action: "history", Release: options.Release, Err: err}
+	}
+
+	return true, nil
+}
+
+// loadChart loads the chart from the local path or downloads it from the repository and merges
+// the values
+func loadChart(options HelmOptions) (*chart.Chart, map[string]interface{}, error) {
+	settings := cli.New()
+	getters := getter.All(settings)
+	vals, err := (&values.Options{ValueFiles: options.ValuesFiles, Values: options.Set, StringValues: options.SetString}).MergeValues(getters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options.Repo == "" {
+		chrt, err := loader.Load(options.Chart)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return chrt, vals, nil
+	}
+
+	// the chart is downloaded into a temporary directory, so neither a local directory with the
+	// name of the chart nor the repositories configured for the helm cli are used
+	tempDir, err := os.MkdirTemp("", "loft-chart-")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	chartURL, err := repo.FindChartInRepoURL(options.Repo, options.Chart, options.Version, "", "", "", getters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chartDownloader := downloader.ChartDownloader{
+		Out:              io.Discard,
+		Getters:          getters,
+		RepositoryConfig: filepath.Join(tempDir, "repositories.yaml"),
+		RepositoryCache:  tempDir,
+	}
+	chartPath, _, err := chartDownloader.DownloadTo(chartURL, options.Version, tempDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return chrt, vals, nil
+}
+
+// LoftReleaseName returns the name of the helm release loft was installed with, which is stored
+// in the release label of the loft deployment
+func LoftReleaseName(ctx context.Context, kubeClient kubernetes.Interface, namespace string) (string, error) {
+	deploy, err := kubeClient.AppsV1().Deployments(namespace).Get(ctx, "loft", metav1.GetOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return "", err
+	} else if err == nil && deploy.Labels["release"] != "" {
+		return deploy.Labels["release"], nil
+	}
+
+	return defaultReleaseName, nil
+}
+
+// LoftVersion returns the version of loft from the image tag of the loft deployment or from its
+// chart label, so it can be determined with access to the cluster and without logging into loft
+func LoftVersion(ctx context.Context, kubeClient kubernetes.Interface, namespace string) (string, error) {
+	deploy, err := kubeClient.AppsV1().Deployments(namespace).Get(ctx, "loft", metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, container := range deploy.Spec.Template.Spec.Containers {
+		image, _, _ := strings.Cut(container.Image, "@")
+		repository, tag, found := strings.Cut(image[strings.LastIndex(image, "/")+1:], ":")
+		if found && repository == "loft" && tag != "" && tag != "latest" {
+			return tag, nil
+		}
+	}
+
+	chartVersion := strings.TrimPrefix(deploy.Labels["chart"], "loft-")
+	if chartVersion != "" && chartVersion != deploy.Labels["chart"] {
+		return chartVersion, nil
+	}
+
+	return "", fmt.Errorf("no version found in the image or chart label of deployment %s/loft", namespace)
+}
@@ -0,0 +1,207 @@
+package clihelper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// writeChart creates a local chart that renders its values into a config map and has a hook
+func writeChart(t *testing.T) string {
+	chart := filepath.Join(t.TempDir(), "loft")
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: loft\nversion: 0.1.0\n",
+		"values.yaml": "admin:\n  email: \"\"\n  password: \"\"\n",
+		"templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: loft
+  namespace: {{ .Release.Namespace }}
+data:
+  email: {{ .Values.admin.email | quote }}
+  password: {{ .Values.admin.password | quote }}
+`,
+		"templates/hook.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: loft-hook
+  annotations:
+    helm.sh/hook: post-install
+`,
+	}
+	for name, content := range files {
+		assert.NilError(t, os.MkdirAll(filepath.Dir(filepath.Join(chart, name)), 0755))
+		assert.NilError(t, os.WriteFile(filepath.Join(chart, name), []byte(content), 0644))
+	}
+
+	return chart
+}
+
+func TestHelmClient(t *testing.T) {
+	// the releases are stored in memory and the resources are only printed
+	config := &action.Configuration{
+		Releases:     storage.Init(driver.NewMemory()),
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(string, ...interface{}) {},
+	}
+	helm := &HelmClient{
+		Configuration: func(options HelmOptions) (*action.Configuration, error) {
+			assert.Equal(t, options.KubeContext, "kind")
+			return config, nil
+		},
+	}
+
+	ctx := context.Background()
+	options := HelmOptions{Release: "loft", Chart: writeChart(t), KubeContext: "kind", Namespace: "loft", Set: []string{"admin.email=admin@example.com"}}
+	manifests, err := helm.Template(ctx, options)
+	assert.NilError(t, err)
+	assert.Equal(t, manifests, `---
+# Source: loft/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: loft
+  namespace: loft
+data:
+  email: "admin@example.com"
+  password: ""
+---
+# Source: loft/templates/hook.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: loft-hook
+  annotations:
+    helm.sh/hook: post-install
+`)
+
+	// template doesn't install anything
+	_, err = config.Releases.Last("loft")
+	assert.Assert(t, errors.Is(err, driver.ErrReleaseNotFound))
+
+	assert.NilError(t, helm.Upgrade(ctx, options))
+	release, err := config.Releases.Last("loft")
+	assert.NilError(t, err)
+	assert.Equal(t, release.Version, 1)
+
+	// an upgrade keeps the email only if the values are reused
+	options.Set = []string{"admin.password=secret"}
+	options.ReuseValues = true
+	assert.NilError(t, helm.Upgrade(ctx, options))
+	release, err = config.Releases.Last("loft")
+	assert.NilError(t, err)
+	assert.Equal(t, release.Version, 2)
+	assert.DeepEqual(t, release.Config, map[string]interface{}{"admin": map[string]interface{}{"email": "admin@example.com", "password": "secret"}})
+
+	options.ReuseValues = false
+	assert.NilError(t, helm.Upgrade(ctx, options))
+	release, err = config.Releases.Last("loft")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, release.Config, map[string]interface{}{"admin": map[string]interface{}{"password": "secret"}})
+
+	exists, err := helm.ReleaseExists(ctx, options)
+	assert.NilError(t, err)
+	assert.Assert(t, exists)
+
+	assert.NilError(t, helm.Uninstall(ctx, options))
+	exists, err = helm.ReleaseExists(ctx, options)
+	assert.NilError(t, err)
+	assert.Assert(t, !exists)
+
+	err = helm.Uninstall(ctx, options)
+	helmErr := &HelmError{}
+	assert.Assert(t, errors.As(err, &helmErr))
+	assert.Equal(t, helmErr.Action, "uninstall")
+	assert.Assert(t, errors.Is(err, driver.ErrReleaseNotFound))
+
+	options.Chart = filepath.Join(t.TempDir(), "missing")
+	err = helm.Upgrade(ctx, options)
+	assert.ErrorContains(t, err, "helm upgrade of release loft")
+}
+
+func TestHelmClientKubeConfig(t *testing.T) {
+	kubeConfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: remote
+  cluster:
+    server: https://remote.example.com
+users:
+- name: remote
+  user:
+    token: token
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+current-context: remote
+`)
+	config, err := NewHelmClient().Configuration(HelmOptions{Release: "loft", Namespace: "loft-agent", KubeConfig: kubeConfig})
+	assert.NilError(t, err)
+	restConfig, err := config.RESTClientGetter.ToRESTConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, restConfig.Host, "https://remote.example.com")
+	namespace, _, err := config.RESTClientGetter.(*kubeConfigGetter).ToRawKubeConfigLoader().Namespace()
+	assert.NilError(t, err)
+	assert.Equal(t, namespace, "loft-agent")
+
+	_, err = NewHelmClient().Configuration(HelmOptions{Release: "loft", Namespace: "loft-agent", KubeConfig: []byte("{")})
+	assert.Assert(t, err != nil)
+}
+
+func TestLoftReleaseName(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := fake.NewSimpleClientset()
+	releaseName, err := LoftReleaseName(ctx, kubeClient, "loft")
+	assert.NilError(t, err)
+	assert.Equal(t, releaseName, defaultReleaseName)
+
+	kubeClient = fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "loft", Namespace: "loft", Labels: map[string]string{"release": "my-loft"}},
+	})
+	releaseName, err = LoftReleaseName(ctx, kubeClient, "loft")
+	assert.NilError(t, err)
+	assert.Equal(t, releaseName, "my-loft")
+}
+
+func TestLoftVersion(t *testing.T) {
+	ctx := context.Background()
+	_, err := LoftVersion(ctx, fake.NewSimpleClientset(), "loft")
+	assert.ErrorContains(t, err, "not found")
+
+	deployment := func(image string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "loft", Namespace: "loft", Labels: labels},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "manager", Image: image}},
+			}}},
+		}
+	}
+
+	version, err := LoftVersion(ctx, fake.NewSimpleClientset(deployment("ghcr.io/loft-sh/loft:3.2.1@sha256:1234", nil)), "loft")
+	assert.NilError(t, err)
+	assert.Equal(t, version, "3.2.1")
+
+	version, err = LoftVersion(ctx, fake.NewSimpleClientset(deployment("registry:5000/loft:latest", map[string]string{"chart": "loft-3.2.0"})), "loft")
+	assert.NilError(t, err)
+	assert.Equal(t, version, "3.2.0")
+
+	_, err = LoftVersion(ctx, fake.NewSimpleClientset(deployment("registry:5000/loft", nil)), "loft")
+	assert.ErrorContains(t, err, "no version found")
+}
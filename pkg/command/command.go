@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// Run runs the given command until it exits and returns its exit code. Interrupt and terminate
+// signals received by this process are forwarded to the command instead of stopping loft, so
+// the command can shut down on its own terms.
+func Run(ctx context.Context, args []string, env []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if len(args) == 0 {
+		return 1, fmt.Errorf("no command specified")
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = env
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	err := cmd.Start()
+	if err != nil {
+		return 1, fmt.Errorf("start %s: %w", args[0], err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+
+		return 1, err
+	}
+
+	return 0, nil
+}
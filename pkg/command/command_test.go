@@ -0,0 +1,22 @@
+//go:build !windows
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRun(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	exitCode, err := Run(context.Background(), []string{"sh", "-c", "echo $TEST_VALUE; exit 3"}, []string{"TEST_VALUE=hello"}, nil, stdout, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, exitCode, 3)
+	assert.Equal(t, stdout.String(), "hello\n")
+
+	_, err = Run(context.Background(), []string{"does-not-exist-command"}, nil, nil, nil, nil)
+	assert.ErrorContains(t, err, "start does-not-exist-command")
+}
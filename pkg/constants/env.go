@@ -0,0 +1,12 @@
+package constants
+
+const (
+	// LoftProjectEnv holds the project of the instance a loft shell was opened for
+	LoftProjectEnv = "LOFT_PROJECT"
+
+	// LoftVirtualClusterEnv holds the name of the virtual cluster a loft shell was opened for
+	LoftVirtualClusterEnv = "LOFT_VCLUSTER"
+
+	// LoftSpaceEnv holds the name of the space a loft shell was opened for
+	LoftSpaceEnv = "LOFT_SPACE"
+)
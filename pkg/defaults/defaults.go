@@ -10,14 +10,15 @@ import (
 )
 
 const (
-	KeyProject = "project"
+	KeyProject          = "project"
+	KeyKubeConfigTarget = "kubeconfig-target"
 )
 
 var (
 	ConfigFile   = "defaults.json"
 	ConfigFolder = client.CacheFolder
 
-	DefaultKeys = []string{KeyProject}
+	DefaultKeys = []string{KeyProject, KeyKubeConfigTarget}
 )
 
 // Defaults holds the default values
@@ -0,0 +1,100 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	"github.com/loft-sh/loftctl/v3/pkg/client"
+	"github.com/loft-sh/loftctl/v3/pkg/client/helper"
+	"github.com/loft-sh/loftctl/v3/pkg/client/naming"
+	"github.com/loft-sh/loftctl/v3/pkg/space"
+	"github.com/loft-sh/loftctl/v3/pkg/vcluster"
+	"github.com/loft-sh/log"
+)
+
+// NewClient creates the client from the given config and verifies the version of loft
+func NewClient(configPath string) (client.Client, error) {
+	baseClient, err := client.NewClientFromPath(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.VerifyVersion(baseClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseClient, nil
+}
+
+// SelectVirtualClusterInstance selects the virtual cluster instance with the given name in the
+// given project or asks for it if the name or project is empty. It returns the project and the
+// instance after it is ready, sleeping instances are woken up.
+func SelectVirtualClusterInstance(ctx context.Context, baseClient client.Client, project, virtualClusterName string, log log.Logger) (string, *managementv1.VirtualClusterInstance, error) {
+	_, project, _, virtualClusterName, err := helper.SelectVirtualClusterInstanceOrVirtualCluster(baseClient, virtualClusterName, "", project, "", log)
+	if err != nil {
+		return "", nil, err
+	} else if project == "" {
+		return "", nil, fmt.Errorf("couldn't find a virtual cluster in a project you have access to")
+	}
+
+	virtualClusterInstance, err := WaitForVirtualClusterInstance(ctx, baseClient, project, virtualClusterName, log)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return project, virtualClusterInstance, nil
+}
+
+// WaitForVirtualClusterInstance wakes up the virtual cluster instance if it is sleeping and waits
+// until it is ready
+func WaitForVirtualClusterInstance(ctx context.Context, baseClient client.Client, project, virtualClusterName string, log log.Logger) (*managementv1.VirtualClusterInstance, error) {
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return nil, err
+	}
+
+	return vcluster.WaitForVirtualClusterInstance(ctx, managementClient, naming.ProjectNamespace(project), virtualClusterName, true, log)
+}
+
+// SelectSpaceInstance selects the space instance with the given name in the given project or asks
+// for it if the name or project is empty. It returns the project and the instance after it is
+// ready, sleeping instances are woken up.
+func SelectSpaceInstance(ctx context.Context, baseClient client.Client, project, spaceName string, log log.Logger) (string, *managementv1.SpaceInstance, error) {
+	_, project, spaceName, err := helper.SelectSpaceInstanceOrSpace(baseClient, spaceName, project, "", log)
+	if err != nil {
+		return "", nil, err
+	} else if project == "" {
+		return "", nil, fmt.Errorf("couldn't find a space in a project you have access to")
+	}
+
+	spaceInstance, err := WaitForSpaceInstance(ctx, baseClient, project, spaceName, log)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return project, spaceInstance, nil
+}
+
+// WaitForSpaceInstance wakes up the space instance if it is sleeping and waits until it is ready
+func WaitForSpaceInstance(ctx context.Context, baseClient client.Client, project, spaceName string, log log.Logger) (*managementv1.SpaceInstance, error) {
+	managementClient, err := baseClient.Management()
+	if err != nil {
+		return nil, err
+	}
+
+	return space.WaitForSpaceInstance(ctx, managementClient, naming.ProjectNamespace(project), spaceName, true, log)
+}
+
+// ExitWithCode exits loft with the given exit code of a command it ran if the command failed
+func ExitWithCode(exitCode int, err error) error {
+	if err != nil {
+		return err
+	} else if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+
+	return nil
+}
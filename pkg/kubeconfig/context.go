@@ -0,0 +1,167 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ContextExtension is the name of the kube context extension that holds the ContextMetadata
+const ContextExtension = "loft.sh/context"
+
+// The kinds of loft contexts
+const (
+	ContextKindManagement             = "management"
+	ContextKindCluster                = "cluster"
+	ContextKindSpace                  = "space"
+	ContextKindVirtualCluster         = "virtualcluster"
+	ContextKindSpaceInstance          = "spaceinstance"
+	ContextKindVirtualClusterInstance = "virtualclusterinstance"
+)
+
+// ContextMetadata describes what a kube context created by loft points to. It is stored as
+// extension of the context, so it doesn't need to be recovered from the context name.
+type ContextMetadata struct {
+	// Host is the loft instance the context was created for
+	Host string `json:"host,omitempty"`
+	Kind string `json:"kind,omitempty"`
+
+	// Project is set for space and virtual cluster instances
+	Project string `json:"project,omitempty"`
+	// Name is the name of the space instance, virtual cluster instance or virtual cluster
+	Name string `json:"name,omitempty"`
+	// Cluster is the cluster the instance runs in
+	Cluster string `json:"cluster,omitempty"`
+	// Namespace is the space of spaces and virtual clusters
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ParseLoftContext returns the metadata of the kube context with the given name. Contexts created
+// before the metadata was stored are parsed from the server path and the context name. The server
+// path is preferred, because the names of space contexts and space instance contexts are ambiguous.
+func ParseLoftContext(config *api.Config, contextName string) (ContextMetadata, bool) {
+	kubeContext, ok := config.Contexts[contextName]
+	if !ok {
+		return ContextMetadata{}, false
+	} else if metadata, ok := contextMetadata(kubeContext); ok {
+		return metadata, true
+	}
+
+	splittedName := strings.Split(contextName, "_")
+	if contextName != ManagementContextName() && splittedName[0] != "loft" && splittedName[0] != "loft-vcluster" {
+		return ContextMetadata{}, false
+	}
+
+	server := ""
+	if cluster, ok := config.Clusters[kubeContext.Cluster]; ok {
+		server = cluster.Server
+	}
+
+	metadata := ContextMetadata{}
+	path := serverPath(server)
+	switch {
+	case len(path) == 1 && path[0] == "management":
+		metadata.Kind = ContextKindManagement
+	case len(path) == 4 && path[0] == "project" && path[2] == "space":
+		metadata.Kind = ContextKindSpaceInstance
+		metadata.Project, metadata.Name = path[1], path[3]
+	case len(path) == 4 && path[0] == "project" && path[2] == "virtualcluster":
+		metadata.Kind = ContextKindVirtualClusterInstance
+		metadata.Project, metadata.Name = path[1], path[3]
+	case len(path) >= 1 && path[0] == "cluster" && splittedName[0] == "loft":
+		// direct cluster endpoints don't contain the cluster name
+		metadata.Kind = ContextKindCluster
+		metadata.Cluster = splittedName[len(splittedName)-1]
+		if len(path) == 2 {
+			metadata.Cluster = path[1]
+		}
+		if len(splittedName) == 3 {
+			metadata.Kind = ContextKindSpace
+			metadata.Namespace = splittedName[1]
+		}
+	case len(path) >= 3 && path[0] == "virtualcluster" && len(splittedName) == 4:
+		metadata.Kind = ContextKindVirtualCluster
+		metadata.Cluster, metadata.Namespace, metadata.Name = splittedName[3], splittedName[2], splittedName[1]
+	case contextName == ManagementContextName():
+		metadata.Kind = ContextKindManagement
+	case splittedName[0] == "loft-vcluster" && len(splittedName) == 3:
+		// virtual clusters with an access point use their own server
+		metadata.Kind = ContextKindVirtualClusterInstance
+		metadata.Project, metadata.Name = splittedName[2], splittedName[1]
+	default:
+		return ContextMetadata{}, false
+	}
+
+	return metadata, true
+}
+
+// LoadLoftContext returns the metadata of the kube context with the given name from the kube config
+func LoadLoftContext(contextName string) (ContextMetadata, bool) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return ContextMetadata{}, false
+	}
+
+	return ParseLoftContext(&config, contextName)
+}
+
+// contextMetadata reads the metadata from the extension of the context
+func contextMetadata(kubeContext *api.Context) (ContextMetadata, bool) {
+	extension, ok := kubeContext.Extensions[ContextExtension]
+	if !ok {
+		return ContextMetadata{}, false
+	}
+
+	var raw []byte
+	switch t := extension.(type) {
+	case *runtime.Unknown:
+		raw = t.Raw
+	default:
+		var err error
+		raw, err = json.Marshal(extension)
+		if err != nil {
+			return ContextMetadata{}, false
+		}
+	}
+
+	metadata := ContextMetadata{}
+	err := json.Unmarshal(raw, &metadata)
+	if err != nil || metadata.Kind == "" {
+		return ContextMetadata{}, false
+	}
+
+	return metadata, true
+}
+
+// setContextMetadata stores the metadata as extension of the context
+func setContextMetadata(kubeContext *api.Context, metadata ContextMetadata) error {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	kubeContext.Extensions[ContextExtension] = &runtime.Unknown{
+		Raw:         raw,
+		ContentType: runtime.ContentTypeJSON,
+	}
+	return nil
+}
+
+// serverPath returns the path segments of the server after /kubernetes
+func serverPath(server string) []string {
+	parsed, err := url.Parse(server)
+	if err != nil {
+		return nil
+	}
+
+	_, path, found := strings.Cut(parsed.Path, "/kubernetes/")
+	if !found {
+		return nil
+	}
+
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
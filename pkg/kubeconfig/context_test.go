@@ -0,0 +1,145 @@
+package kubeconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestParseLoftContext(t *testing.T) {
+	testCases := []struct {
+		name     string
+		context  string
+		server   string
+		expected ContextMetadata
+		ok       bool
+	}{
+		{
+			name:     "management",
+			context:  "loft-management",
+			server:   "https://loft.example.com/kubernetes/management",
+			expected: ContextMetadata{Kind: ContextKindManagement},
+			ok:       true,
+		},
+		{
+			name:     "cluster",
+			context:  "loft_prod",
+			server:   "https://loft.example.com/kubernetes/cluster/prod",
+			expected: ContextMetadata{Kind: ContextKindCluster, Cluster: "prod"},
+			ok:       true,
+		},
+		{
+			name:     "space",
+			context:  "loft_team_prod",
+			server:   "https://loft.example.com/kubernetes/cluster/prod",
+			expected: ContextMetadata{Kind: ContextKindSpace, Cluster: "prod", Namespace: "team"},
+			ok:       true,
+		},
+		{
+			name:     "space with direct cluster endpoint",
+			context:  "loft_team_prod",
+			server:   "https://prod.example.com/kubernetes/cluster",
+			expected: ContextMetadata{Kind: ContextKindSpace, Cluster: "prod", Namespace: "team"},
+			ok:       true,
+		},
+		{
+			name:     "space instance",
+			context:  "loft_team_default",
+			server:   "https://loft.example.com/kubernetes/project/default/space/team",
+			expected: ContextMetadata{Kind: ContextKindSpaceInstance, Project: "default", Name: "team"},
+			ok:       true,
+		},
+		{
+			name:     "virtual cluster",
+			context:  "loft-vcluster_dev_team_prod",
+			server:   "https://loft.example.com/kubernetes/virtualcluster/prod/team/dev",
+			expected: ContextMetadata{Kind: ContextKindVirtualCluster, Cluster: "prod", Namespace: "team", Name: "dev"},
+			ok:       true,
+		},
+		{
+			name:     "virtual cluster instance",
+			context:  "loft-vcluster_dev_default",
+			server:   "https://loft.example.com/kubernetes/project/default/virtualcluster/dev",
+			expected: ContextMetadata{Kind: ContextKindVirtualClusterInstance, Project: "default", Name: "dev"},
+			ok:       true,
+		},
+		{
+			name:     "virtual cluster instance with access point",
+			context:  "loft-vcluster_dev_default",
+			server:   "https://dev.example.com",
+			expected: ContextMetadata{Kind: ContextKindVirtualClusterInstance, Project: "default", Name: "dev"},
+			ok:       true,
+		},
+		{
+			name:    "foreign context",
+			context: "kind-kind",
+			server:  "https://127.0.0.1:6443",
+		},
+		{
+			name:    "unknown loft context",
+			context: "loft_team_default",
+			server:  "https://127.0.0.1:6443",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			config := api.NewConfig()
+			config.Clusters[testCase.context] = &api.Cluster{Server: testCase.server}
+			config.Contexts[testCase.context] = &api.Context{Cluster: testCase.context}
+
+			loftContext, ok := ParseLoftContext(config, testCase.context)
+			assert.Equal(t, ok, testCase.ok)
+			if testCase.ok {
+				assert.DeepEqual(t, loftContext, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestContextMetadata(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(clientcmd.RecommendedConfigPathEnvVar, filepath.Join(dir, "config"))
+	assert.NilError(t, SetTarget(filepath.Join(dir, "loft.yaml")))
+	defer func() { _ = SetTarget("") }()
+
+	// underscores in names can't be parsed from the context name
+	metadata := ContextMetadata{
+		Host:      "https://loft.example.com",
+		Kind:      ContextKindVirtualClusterInstance,
+		Project:   "my_project",
+		Name:      "my_vcluster",
+		Cluster:   "loft-cluster",
+		Namespace: "loft-p-my-project-v-my-vcluster",
+	}
+	contextName := VirtualClusterInstanceContextName(metadata.Project, metadata.Name)
+	err := UpdateKubeConfig(ContextOptions{
+		Name:                             contextName,
+		Server:                           "https://my-vcluster.example.com",
+		VirtualClusterAccessPointEnabled: true,
+		Metadata:                         metadata,
+	})
+	assert.NilError(t, err)
+
+	config, err := clientcmd.LoadFromFile(filepath.Join(dir, "loft.yaml"))
+	assert.NilError(t, err)
+	parsed, ok := ParseLoftContext(config, contextName)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, parsed, metadata)
+	configPath, err := filepath.Abs("")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, config.AuthInfos[contextName].Exec.Args, []string{"token", "--silent", "--config", configPath, "--project", "my_project", "--virtual-cluster", "my_vcluster"})
+
+	isLoftContext, _, _, _ := ParseContext(contextName)
+	assert.Assert(t, !isLoftContext, "instance contexts aren't legacy contexts")
+}
+
+func TestTokenTargetArgs(t *testing.T) {
+	assert.DeepEqual(t, tokenTargetArgs(ContextMetadata{Kind: ContextKindSpaceInstance, Project: "my-project", Name: "my-space", Cluster: "loft-cluster"}), []string{"--project", "my-project", "--space", "my-space"})
+	assert.DeepEqual(t, tokenTargetArgs(ContextMetadata{Kind: ContextKindSpace, Cluster: "loft-cluster", Namespace: "my-space"}), []string{"--cluster", "loft-cluster"})
+	assert.DeepEqual(t, tokenTargetArgs(ContextMetadata{Kind: ContextKindCluster, Cluster: "loft-cluster"}), []string{"--cluster", "loft-cluster"})
+	assert.Assert(t, tokenTargetArgs(ContextMetadata{Kind: ContextKindVirtualClusterInstance, Project: "my-project", Name: "my-vcluster"}) == nil)
+}
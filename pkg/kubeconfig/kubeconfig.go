@@ -4,8 +4,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"github.com/pkg/errors"
 	"k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -27,6 +27,9 @@ type ContextOptions struct {
 
 	CurrentNamespace string
 	SetActive        bool
+
+	// Metadata is stored in the context to identify what it points to
+	Metadata ContextMetadata
 }
 
 func SpaceInstanceContextName(projectName, spaceInstanceName string) string {
@@ -37,10 +40,6 @@ func VirtualClusterInstanceContextName(projectName, virtualClusterInstance strin
 	return "loft-vcluster_" + virtualClusterInstance + "_" + projectName
 }
 
-func virtualClusterInstanceProjectAndNameFromContextName(contextName string) (string, string) {
-	return strings.Split(contextName, "_")[2], strings.Split(contextName, "_")[1]
-}
-
 func SpaceContextName(clusterName, namespaceName string) string {
 	contextName := "loft_"
 	if namespaceName != "" {
@@ -59,33 +58,26 @@ func ManagementContextName() string {
 	return "loft-management"
 }
 
+// ParseContext returns the cluster, namespace and virtual cluster of a cluster, space or virtual
+// cluster context created without a project
 func ParseContext(contextName string) (isLoftContext bool, cluster string, namespace string, vCluster string) {
-	splitted := strings.Split(contextName, "_")
-	if len(splitted) == 0 || (splitted[0] != "loft" && splitted[0] != "loft-vcluster") {
+	metadata, ok := LoadLoftContext(contextName)
+	if !ok {
 		return false, "", "", ""
 	}
 
-	// cluster or space context
-	if splitted[0] == "loft" {
-		if len(splitted) > 3 || len(splitted) == 1 {
-			return false, "", "", ""
-		} else if len(splitted) == 2 {
-			return true, splitted[1], "", ""
-		}
-
-		return true, splitted[2], splitted[1], ""
+	switch metadata.Kind {
+	case ContextKindCluster, ContextKindSpace:
+		return true, metadata.Cluster, metadata.Namespace, ""
+	case ContextKindVirtualCluster:
+		return true, metadata.Cluster, metadata.Namespace, metadata.Name
 	}
 
-	// vCluster context
-	if len(splitted) != 4 {
-		return false, "", "", ""
-	}
-
-	return true, splitted[3], splitted[2], splitted[1]
+	return false, "", "", ""
 }
 
 func CurrentContext() (string, error) {
-	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
 	if err != nil {
 		return "", err
 	}
@@ -93,30 +85,35 @@ func CurrentContext() (string, error) {
 	return config.CurrentContext, nil
 }
 
-// DeleteContext deletes the context with the given name from the kube config
+// DeleteContext deletes the context with the given name from the kube config. If a target is
+// configured, the context is also removed from the default kube config in case it was created
+// there before.
 func DeleteContext(contextName string) error {
+	if target != "" {
+		err := deleteFromTarget(contextName)
+		if err != nil {
+			return err
+		}
+	}
+
 	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
 	if err != nil {
 		return err
+	} else if target != "" && config.Contexts[contextName] == nil {
+		return nil
 	}
 
-	delete(config.Contexts, contextName)
-	delete(config.Clusters, contextName)
-	delete(config.AuthInfos, contextName)
-
-	if config.CurrentContext == contextName {
-		config.CurrentContext = ""
-		for name := range config.Contexts {
-			config.CurrentContext = name
-			break
-		}
-	}
+	removeContext(&config, contextName)
 
 	// Save the config
 	return clientcmd.ModifyConfig(clientcmd.NewDefaultClientConfigLoadingRules(), config, false)
 }
 
-func updateKubeConfig(contextName string, cluster *api.Cluster, authInfo *api.AuthInfo, namespaceName string, setActive bool) error {
+func updateKubeConfig(contextName string, cluster *api.Cluster, authInfo *api.AuthInfo, context *api.Context, setActive bool) error {
+	if target != "" {
+		return updateTarget(contextName, cluster, authInfo, context, setActive)
+	}
+
 	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
 	if err != nil {
 		return err
@@ -124,13 +121,6 @@ func updateKubeConfig(contextName string, cluster *api.Cluster, authInfo *api.Au
 
 	config.Clusters[contextName] = cluster
 	config.AuthInfos[contextName] = authInfo
-
-	// Update kube context
-	context := api.NewContext()
-	context.Cluster = contextName
-	context.AuthInfo = contextName
-	context.Namespace = namespaceName
-
 	config.Contexts[contextName] = context
 	if setActive {
 		config.CurrentContext = contextName
@@ -140,18 +130,13 @@ func updateKubeConfig(contextName string, cluster *api.Cluster, authInfo *api.Au
 	return clientcmd.ModifyConfig(clientcmd.NewDefaultClientConfigLoadingRules(), config, false)
 }
 
-func printKubeConfigTo(contextName string, cluster *api.Cluster, authInfo *api.AuthInfo, namespaceName string, writer io.Writer) error {
+func printKubeConfigTo(contextName string, cluster *api.Cluster, authInfo *api.AuthInfo, context *api.Context, writer io.Writer) error {
 	config := api.NewConfig()
 
 	config.Clusters[contextName] = cluster
 	config.AuthInfos[contextName] = authInfo
 
 	// Update kube context
-	context := api.NewContext()
-	context.Cluster = contextName
-	context.AuthInfo = contextName
-	context.Namespace = namespaceName
-
 	config.Contexts[contextName] = context
 	config.CurrentContext = contextName
 
@@ -175,8 +160,13 @@ func UpdateKubeConfig(options ContextOptions) error {
 		return err
 	}
 
+	context, err := newKubeContext(options)
+	if err != nil {
+		return err
+	}
+
 	// we don't want to set the space name here as the default namespace in the virtual cluster, because it couldn't exist
-	return updateKubeConfig(contextName, cluster, authInfo, options.CurrentNamespace, options.SetActive)
+	return updateKubeConfig(contextName, cluster, authInfo, context, options.SetActive)
 }
 
 // PrintKubeConfigTo prints the given config to the writer
@@ -186,22 +176,47 @@ func PrintKubeConfigTo(options ContextOptions, writer io.Writer) error {
 		return err
 	}
 
+	context, err := newKubeContext(options)
+	if err != nil {
+		return err
+	}
+
 	// we don't want to set the space name here as the default namespace in the virtual cluster, because it couldn't exist
-	return printKubeConfigTo(contextName, cluster, authInfo, options.CurrentNamespace, writer)
+	return printKubeConfigTo(contextName, cluster, authInfo, context, writer)
+}
+
+// WriteTempKubeConfig writes a kube config that only contains the given context into a new
+// temporary file and returns its path. The caller is responsible for removing the file.
+func WriteTempKubeConfig(options ContextOptions) (string, error) {
+	file, err := os.CreateTemp("", "loft-kubeconfig-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	err = PrintKubeConfigTo(options, file)
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
 }
 
 // PrintTokenKubeConfig writes the kube config to the os.Stdout
 func PrintTokenKubeConfig(restConfig *rest.Config, token string) error {
-	contextName, cluster, authInfo := createTokenContext(restConfig, token)
-
-	return printKubeConfigTo(contextName, cluster, authInfo, "", os.Stdout)
+	return WriteTokenKubeConfig(restConfig, token, os.Stdout)
 }
 
 // WriteTokenKubeConfig writes the kube config to the io.Writer
 func WriteTokenKubeConfig(restConfig *rest.Config, token string, w io.Writer) error {
 	contextName, cluster, authInfo := createTokenContext(restConfig, token)
+	context, err := newKubeContext(ContextOptions{Name: contextName})
+	if err != nil {
+		return err
+	}
 
-	return printKubeConfigTo(contextName, cluster, authInfo, "", w)
+	return printKubeConfigTo(contextName, cluster, authInfo, context, w)
 }
 
 func createTokenContext(restConfig *rest.Config, token string) (string, *api.Cluster, *api.AuthInfo) {
@@ -243,19 +258,19 @@ func createContext(options ContextOptions) (string, *api.Cluster, *api.AuthInfo,
 			return "", nil, nil, err
 		}
 
+		// the config is always pinned, so the token command doesn't fall back to the default config
+		authInfo.Exec = &api.ExecConfig{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Command:    command,
+			Args:       []string{"token", "--silent", "--config", absConfigPath},
+		}
 		if options.VirtualClusterAccessPointEnabled {
-			projectName, virtualClusterName := virtualClusterInstanceProjectAndNameFromContextName(contextName)
-			authInfo.Exec = &api.ExecConfig{
-				APIVersion: v1beta1.SchemeGroupVersion.String(),
-				Command:    command,
-				Args:       []string{"token", "--silent", "--project", projectName, "--virtual-cluster", virtualClusterName},
+			if options.Metadata.Project == "" || options.Metadata.Name == "" {
+				return "", nil, nil, errors.Errorf("context %s of a virtual cluster with an access point has no project and name", contextName)
 			}
+			authInfo.Exec.Args = append(authInfo.Exec.Args, "--project", options.Metadata.Project, "--virtual-cluster", options.Metadata.Name)
 		} else {
-			authInfo.Exec = &api.ExecConfig{
-				APIVersion: v1beta1.SchemeGroupVersion.String(),
-				Command:    command,
-				Args:       []string{"token", "--silent", "--config", absConfigPath},
-			}
+			authInfo.Exec.Args = append(authInfo.Exec.Args, tokenTargetArgs(options.Metadata)...)
 			if options.DirectClusterEndpointEnabled {
 				authInfo.Exec.Args = append(authInfo.Exec.Args, "--direct-cluster-endpoint")
 			}
@@ -264,3 +279,17 @@ func createContext(options ContextOptions) (string, *api.Cluster, *api.AuthInfo,
 
 	return contextName, cluster, authInfo, nil
 }
+
+// tokenTargetArgs returns the arguments of the token command that identify the target of the
+// context. Virtual clusters without an access point use the same token as their space, so they
+// have no target, because a virtual cluster target requests an access point certificate.
+func tokenTargetArgs(metadata ContextMetadata) []string {
+	switch metadata.Kind {
+	case ContextKindSpaceInstance:
+		return []string{"--project", metadata.Project, "--space", metadata.Name}
+	case ContextKindCluster, ContextKindSpace:
+		return []string{"--cluster", metadata.Cluster}
+	}
+
+	return nil
+}
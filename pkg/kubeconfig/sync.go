@@ -0,0 +1,176 @@
+package kubeconfig
+
+import (
+	"bytes"
+	"reflect"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// The results of UpdateKubeConfigs for a single context
+const (
+	ResultAdded     = "added"
+	ResultUpdated   = "updated"
+	ResultUnchanged = "unchanged"
+)
+
+// UpdateKubeConfigs creates or updates the contexts for all given options with a single write and
+// returns for every context whether it was added, updated or unchanged. Only a directory target
+// is written once per changed context, because every context has its own file there.
+func UpdateKubeConfigs(options []ContextOptions) (map[string]string, error) {
+	if target != "" && isDirectoryTarget() {
+		return updateDirectoryTarget(options)
+	}
+
+	var (
+		config *api.Config
+		err    error
+	)
+	if target != "" {
+		config, err = loadFile(target)
+	} else {
+		var rawConfig api.Config
+		rawConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+		config = &rawConfig
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := applyContexts(config, options)
+	if err != nil {
+		return nil, err
+	} else if !changed(results) {
+		return results, nil
+	}
+
+	if target != "" {
+		return results, clientcmd.WriteToFile(*config, target)
+	}
+
+	return results, clientcmd.ModifyConfig(clientcmd.NewDefaultClientConfigLoadingRules(), *config, false)
+}
+
+func updateDirectoryTarget(options []ContextOptions) (map[string]string, error) {
+	results := map[string]string{}
+	for _, option := range options {
+		path := contextFile(option.Name)
+		config, err := loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		setActive := option.SetActive
+		option.SetActive = false
+		contextResults, err := applyContexts(config, []ContextOptions{option})
+		if err != nil {
+			return nil, err
+		}
+
+		results[option.Name] = contextResults[option.Name]
+		if changed(contextResults) {
+			err = clientcmd.WriteToFile(*config, path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if setActive {
+			err = setDirectoryCurrentContext(option.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func applyContexts(config *api.Config, options []ContextOptions) (map[string]string, error) {
+	results := map[string]string{}
+	for _, option := range options {
+		contextName, cluster, authInfo, err := createContext(option)
+		if err != nil {
+			return nil, err
+		}
+		kubeContext, err := newKubeContext(option)
+		if err != nil {
+			return nil, err
+		}
+
+		existingContext, ok := config.Contexts[contextName]
+		switch {
+		case !ok:
+			results[contextName] = ResultAdded
+		case contextEqual(existingContext, kubeContext) && clusterEqual(config.Clusters[contextName], cluster) && authInfoEqual(config.AuthInfos[contextName], authInfo):
+			results[contextName] = ResultUnchanged
+		default:
+			results[contextName] = ResultUpdated
+		}
+
+		if results[contextName] != ResultUnchanged {
+			config.Clusters[contextName] = cluster
+			config.AuthInfos[contextName] = authInfo
+			config.Contexts[contextName] = kubeContext
+		}
+		if option.SetActive && config.CurrentContext != contextName {
+			config.CurrentContext = contextName
+			if results[contextName] == ResultUnchanged {
+				results[contextName] = ResultUpdated
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func changed(results map[string]string) bool {
+	for _, result := range results {
+		if result != ResultUnchanged {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newKubeContext(options ContextOptions) (*api.Context, error) {
+	context := api.NewContext()
+	context.Cluster = options.Name
+	context.AuthInfo = options.Name
+	context.Namespace = options.CurrentNamespace
+	if options.Metadata.Kind != "" {
+		err := setContextMetadata(context, options.Metadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return context, nil
+}
+
+func contextEqual(a, b *api.Context) bool {
+	aMetadata, _ := contextMetadata(a)
+	bMetadata, _ := contextMetadata(b)
+	return a.Cluster == b.Cluster && a.AuthInfo == b.AuthInfo && a.Namespace == b.Namespace && aMetadata == bMetadata
+}
+
+func clusterEqual(a, b *api.Cluster) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Server == b.Server && a.InsecureSkipTLSVerify == b.InsecureSkipTLSVerify && bytes.Equal(a.CertificateAuthorityData, b.CertificateAuthorityData)
+}
+
+func authInfoEqual(a, b *api.AuthInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	} else if a.Token != b.Token || !bytes.Equal(a.ClientCertificateData, b.ClientCertificateData) || !bytes.Equal(a.ClientKeyData, b.ClientKeyData) {
+		return false
+	} else if a.Exec == nil || b.Exec == nil {
+		return a.Exec == b.Exec
+	}
+
+	return a.Exec.Command == b.Exec.Command && a.Exec.APIVersion == b.Exec.APIVersion && reflect.DeepEqual(a.Exec.Args, b.Exec.Args)
+}
@@ -0,0 +1,36 @@
+package kubeconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestUpdateKubeConfigs(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(clientcmd.RecommendedConfigPathEnvVar, filepath.Join(dir, "config"))
+	assert.NilError(t, SetTarget(filepath.Join(dir, "loft.yaml")))
+	defer func() { _ = SetTarget("") }()
+
+	options := []ContextOptions{
+		{Name: "loft_a_default", Server: "https://loft/kubernetes/project/default/space/a", Token: "token"},
+		{Name: "loft_b_default", Server: "https://loft/kubernetes/project/default/space/b", Token: "token"},
+	}
+	results, err := UpdateKubeConfigs(options)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results, map[string]string{"loft_a_default": ResultAdded, "loft_b_default": ResultAdded})
+
+	options[1].Token = "other"
+	options = append(options, ContextOptions{Name: "loft_c_default", Server: "https://loft/kubernetes/project/default/space/c", Token: "token"})
+	results, err = UpdateKubeConfigs(options)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results, map[string]string{"loft_a_default": ResultUnchanged, "loft_b_default": ResultUpdated, "loft_c_default": ResultAdded})
+
+	config, err := clientcmd.LoadFromFile(filepath.Join(dir, "loft.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(config.Contexts), 3)
+	assert.Equal(t, config.CurrentContext, "")
+	assert.Equal(t, config.AuthInfos["loft_b_default"].Token, "other")
+}
@@ -0,0 +1,226 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// currentContextFile is the file within a target directory that holds the current context
+const currentContextFile = "current-context.yaml"
+
+var target string
+
+// SetTarget sets the file or directory loft contexts are written to instead of the default
+// kubeconfig. If the target is a directory, or ends with a path separator, every context is
+// written to its own file. An empty target restores the default behaviour.
+func SetTarget(path string) error {
+	if path == "" {
+		target = ""
+		return nil
+	}
+
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		return errors.Wrapf(err, "expand kubeconfig target %s", path)
+	}
+
+	target, err = filepath.Abs(expanded)
+	if err != nil {
+		return errors.Wrapf(err, "resolve kubeconfig target %s", path)
+	}
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator)) {
+		target += string(os.PathSeparator)
+	}
+
+	return nil
+}
+
+// Target returns the configured target or an empty string if contexts are written to the
+// default kubeconfig
+func Target() string {
+	return target
+}
+
+func isDirectoryTarget() bool {
+	if strings.HasSuffix(target, string(os.PathSeparator)) {
+		return true
+	}
+
+	stat, err := os.Stat(target)
+	return err == nil && stat.IsDir()
+}
+
+// TargetFiles returns the kubeconfig files that currently make up the target. For a directory
+// target the file holding the current context comes first, so it takes precedence when merged.
+func TargetFiles() ([]string, error) {
+	if target == "" {
+		return nil, nil
+	} else if !isDirectoryTarget() {
+		return []string{target}, nil
+	}
+
+	dir := filepath.Clean(target)
+	matches, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	files := []string{filepath.Join(dir, currentContextFile)}
+	for _, match := range matches {
+		if filepath.Base(match) != currentContextFile {
+			files = append(files, match)
+		}
+	}
+
+	return files, nil
+}
+
+// LoadingRules returns the rules to load the kubeconfig including the contexts in the target
+func LoadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	files, err := TargetFiles()
+	if err != nil || len(files) == 0 {
+		return rules
+	}
+
+	rules.Precedence = mergePaths(files, rules.Precedence)
+	return rules
+}
+
+// MergedPath returns a KUBECONFIG value that merges the target files with the given KUBECONFIG
+// value, which falls back to the default kubeconfig. The target files come first, so the current
+// context set by loft takes precedence.
+func MergedPath(kubeConfigEnv string) (string, error) {
+	files, err := TargetFiles()
+	if err != nil {
+		return "", err
+	}
+
+	userFiles := filepath.SplitList(kubeConfigEnv)
+	if len(userFiles) == 0 {
+		userFiles = []string{clientcmd.RecommendedHomeFile}
+	}
+
+	return strings.Join(mergePaths(files, userFiles), string(os.PathListSeparator)), nil
+}
+
+// mergePaths appends the other paths to the given ones and removes duplicates
+func mergePaths(paths []string, other []string) []string {
+	merged := []string{}
+	seen := map[string]bool{}
+	for _, path := range append(append([]string{}, paths...), other...) {
+		if path == "" || seen[filepath.Clean(path)] {
+			continue
+		}
+
+		seen[filepath.Clean(path)] = true
+		merged = append(merged, path)
+	}
+
+	return merged
+}
+
+func contextFile(contextName string) string {
+	return filepath.Join(filepath.Clean(target), contextName+".yaml")
+}
+
+func loadFile(path string) (*api.Config, error) {
+	config, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return api.NewConfig(), nil
+		}
+
+		return nil, errors.Wrapf(err, "load kubeconfig %s", path)
+	}
+
+	return config, nil
+}
+
+func updateTarget(contextName string, cluster *api.Cluster, authInfo *api.AuthInfo, context *api.Context, setActive bool) error {
+	path := target
+	if isDirectoryTarget() {
+		path = contextFile(contextName)
+	}
+
+	config, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+
+	config.Clusters[contextName] = cluster
+	config.AuthInfos[contextName] = authInfo
+	config.Contexts[contextName] = context
+	if setActive && !isDirectoryTarget() {
+		config.CurrentContext = contextName
+	}
+
+	err = clientcmd.WriteToFile(*config, path)
+	if err != nil {
+		return err
+	}
+
+	if setActive && isDirectoryTarget() {
+		return setDirectoryCurrentContext(contextName)
+	}
+
+	return nil
+}
+
+func deleteFromTarget(contextName string) error {
+	if isDirectoryTarget() {
+		err := os.Remove(contextFile(contextName))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		current, err := loadFile(filepath.Join(filepath.Clean(target), currentContextFile))
+		if err != nil {
+			return err
+		} else if current.CurrentContext == contextName {
+			return setDirectoryCurrentContext("")
+		}
+
+		return nil
+	}
+
+	config, err := loadFile(target)
+	if err != nil {
+		return err
+	} else if config.Contexts[contextName] == nil {
+		return nil
+	}
+
+	removeContext(config, contextName)
+	return clientcmd.WriteToFile(*config, target)
+}
+
+func setDirectoryCurrentContext(contextName string) error {
+	config := api.NewConfig()
+	config.CurrentContext = contextName
+	return clientcmd.WriteToFile(*config, filepath.Join(filepath.Clean(target), currentContextFile))
+}
+
+// removeContext removes the context together with its cluster and auth info and switches to
+// another context if it was the current one
+func removeContext(config *api.Config, contextName string) {
+	delete(config.Contexts, contextName)
+	delete(config.Clusters, contextName)
+	delete(config.AuthInfos, contextName)
+
+	if config.CurrentContext == contextName {
+		config.CurrentContext = ""
+		for name := range config.Contexts {
+			config.CurrentContext = name
+			break
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestTarget(t *testing.T) {
+	testCases := []struct {
+		name      string
+		directory bool
+	}{
+		{name: "file"},
+		{name: "directory", directory: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv(clientcmd.RecommendedConfigPathEnvVar, filepath.Join(dir, "config"))
+
+			path := filepath.Join(dir, "loft.yaml")
+			if testCase.directory {
+				path = filepath.Join(dir, "loft") + string(os.PathSeparator)
+			}
+			assert.NilError(t, SetTarget(path))
+			defer func() { _ = SetTarget("") }()
+
+			for _, name := range []string{"loft_a_cluster", "loft_b_cluster"} {
+				err := UpdateKubeConfig(ContextOptions{Name: name, Server: "https://loft/kubernetes/cluster/cluster", Token: "token", SetActive: true})
+				assert.NilError(t, err)
+			}
+
+			_, err := os.Stat(filepath.Join(dir, "config"))
+			assert.Assert(t, os.IsNotExist(err), "default kubeconfig must not be written")
+
+			config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+			assert.NilError(t, err)
+			assert.Equal(t, config.CurrentContext, "loft_b_cluster")
+			assert.Equal(t, len(config.Contexts), 2)
+
+			assert.NilError(t, DeleteContext("loft_b_cluster"))
+			config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(LoadingRules(), &clientcmd.ConfigOverrides{}).RawConfig()
+			assert.NilError(t, err)
+			assert.Equal(t, len(config.Contexts), 1)
+			assert.Assert(t, config.Contexts["loft_a_cluster"] != nil)
+			assert.Assert(t, config.CurrentContext != "loft_b_cluster")
+
+			mergedPath, err := MergedPath("")
+			assert.NilError(t, err)
+			assert.Assert(t, strings.HasSuffix(mergedPath, string(os.PathListSeparator)+clientcmd.RecommendedHomeFile), mergedPath)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package parameters
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/ghodss/yaml"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/log"
+	"github.com/loft-sh/log/terminal"
+	"github.com/pkg/errors"
+)
+
+// ParameterChange holds the old and new definition of a parameter that changed between two template versions
+type ParameterChange struct {
+	Old storagev1.AppParameter
+	New storagev1.AppParameter
+}
+
+// ParameterDiff holds the differences between the parameters of two template versions
+type ParameterDiff struct {
+	Added   []storagev1.AppParameter
+	Removed []storagev1.AppParameter
+	Changed []ParameterChange
+}
+
+// Empty returns true if there are no differences
+func (d ParameterDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffParameters compares two parameter lists by their variable
+func DiffParameters(oldParameters, newParameters []storagev1.AppParameter) ParameterDiff {
+	diff := ParameterDiff{}
+	for _, newParameter := range newParameters {
+		oldParameter, ok := findParameter(oldParameters, newParameter.Variable)
+		if !ok {
+			diff.Added = append(diff.Added, newParameter)
+		} else if !reflect.DeepEqual(oldParameter, newParameter) {
+			diff.Changed = append(diff.Changed, ParameterChange{
+				Old: oldParameter,
+				New: newParameter,
+			})
+		}
+	}
+	for _, oldParameter := range oldParameters {
+		if _, ok := findParameter(newParameters, oldParameter.Variable); !ok {
+			diff.Removed = append(diff.Removed, oldParameter)
+		}
+	}
+
+	return diff
+}
+
+// MigrateParameters resolves the parameter values of an existing instance for a new set of
+// parameters. Values from the sources take precedence, existing values are carried over if they
+// are still valid and everything else falls back to the default value or is asked from the user.
+// Values of parameters that don't exist anymore are dropped. Secret parameters are checked against
+// the given project secrets, which may be nil.
+func MigrateParameters(values string, parameters []storagev1.AppParameter, sources *Sources, secrets *ProjectSecrets, log log.Logger) (string, error) {
+	oldValues := map[string]interface{}{}
+	if values != "" {
+		err := yaml.Unmarshal([]byte(values), &oldValues)
+		if err != nil {
+			return "", errors.Wrap(err, "parse existing parameters")
+		}
+	}
+
+	fileValues, err := sources.loadFiles(parseTemplateParametersFile)
+	if err != nil {
+		return "", err
+	}
+
+	overrides, err := sources.overrides(parameters)
+	if err != nil {
+		return "", err
+	}
+
+	newValues := map[string]interface{}{}
+	for _, parameter := range parameters {
+		val, ok := overrides[parameter.Variable]
+		if !ok {
+			val = GetDeepValue(fileValues, parameter.Variable)
+		}
+		if val != nil {
+			strVal, err := valueToString(val, parameter)
+			if err != nil {
+				return "", err
+			}
+
+			outVal, err := verifyValue(strVal, parameter, secrets)
+			if err != nil {
+				return "", errors.Wrap(err, "validate parameters")
+			}
+
+			SetDeepValue(newValues, parameter.Variable, outVal)
+			continue
+		}
+
+		strVal := ""
+		val = GetDeepValue(oldValues, parameter.Variable)
+		if val != nil {
+			strVal, err = valueToString(val, parameter)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		outVal, err := verifyValue(strVal, parameter, secrets)
+		if err != nil && strVal != "" {
+			log.Warnf("Existing value of parameter %s (%s) is not valid anymore: %v", parameter.Label, parameter.Variable, err)
+			outVal, err = VerifyValue("", parameter)
+		}
+		if err != nil {
+			if !terminal.IsTerminal(os.Stdin) {
+				return "", fmt.Errorf("parameter %s (%s) needs a new value, please specify it via --set %s=VALUE", parameter.Label, parameter.Variable, parameter.Variable)
+			}
+
+			outVal, err = askParameter(parameter, secrets, log)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		SetDeepValue(newValues, parameter.Variable, outVal)
+	}
+
+	out, err := yaml.Marshal(newValues)
+	if err != nil {
+		return "", errors.Wrap(err, "marshal parameters")
+	}
+
+	return string(out), nil
+}
+
+func findParameter(parameters []storagev1.AppParameter, variable string) (storagev1.AppParameter, bool) {
+	for _, parameter := range parameters {
+		if parameter.Variable == variable {
+			return parameter, true
+		}
+	}
+
+	return storagev1.AppParameter{}, false
+}
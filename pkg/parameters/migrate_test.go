@@ -0,0 +1,127 @@
+package parameters
+
+import (
+	"testing"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/log"
+	"gotest.tools/v3/assert"
+)
+
+func TestDiffParameters(t *testing.T) {
+	type testCase struct {
+		desc            string
+		oldParameters   []storagev1.AppParameter
+		newParameters   []storagev1.AppParameter
+		expectedAdded   []string
+		expectedRemoved []string
+		expectedChanged []string
+	}
+
+	testTable := []testCase{
+		{
+			desc:          "no changes",
+			oldParameters: []storagev1.AppParameter{{Variable: "a"}, {Variable: "b", Type: "number"}},
+			newParameters: []storagev1.AppParameter{{Variable: "a"}, {Variable: "b", Type: "number"}},
+		},
+		{
+			desc:            "added, removed and changed",
+			oldParameters:   []storagev1.AppParameter{{Variable: "a"}, {Variable: "b", Type: "number"}, {Variable: "c"}},
+			newParameters:   []storagev1.AppParameter{{Variable: "a"}, {Variable: "b", Type: "boolean"}, {Variable: "d", Required: true}},
+			expectedAdded:   []string{"d"},
+			expectedRemoved: []string{"c"},
+			expectedChanged: []string{"b"},
+		},
+	}
+
+	for _, tc := range testTable {
+		diff := DiffParameters(tc.oldParameters, tc.newParameters)
+		assert.DeepEqual(t, variables(diff.Added), tc.expectedAdded)
+		assert.DeepEqual(t, variables(diff.Removed), tc.expectedRemoved)
+
+		var changed []string
+		for _, change := range diff.Changed {
+			changed = append(changed, change.New.Variable)
+		}
+		assert.DeepEqual(t, changed, tc.expectedChanged)
+		assert.Equal(t, diff.Empty(), len(tc.expectedAdded)+len(tc.expectedRemoved)+len(tc.expectedChanged) == 0, tc.desc)
+	}
+}
+
+func TestMigrateParameters(t *testing.T) {
+	type testCase struct {
+		desc           string
+		values         string
+		parameters     []storagev1.AppParameter
+		set            []string
+		expectedValues string
+		expectedErr    bool
+	}
+
+	testTable := []testCase{
+		{
+			desc:   "carry over compatible values and drop removed ones",
+			values: "a: hello\nb: 5\nremoved: true\n",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a"},
+				{Variable: "b", Type: "number"},
+			},
+			expectedValues: "a: hello\nb: 5\n",
+		},
+		{
+			desc:   "use default for incompatible value",
+			values: "a: hello\n",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a", Type: "boolean", DefaultValue: "true"},
+			},
+			expectedValues: "a: true\n",
+		},
+		{
+			desc:   "set overrides existing value",
+			values: "nested:\n  a: hello\n",
+			parameters: []storagev1.AppParameter{
+				{Variable: "nested.a", Options: []string{"hello", "world"}},
+			},
+			set:            []string{"nested.a=world"},
+			expectedValues: "nested:\n  a: world\n",
+		},
+		{
+			desc:   "new required parameter without value",
+			values: "a: hello\n",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a"},
+				{Variable: "b", Required: true},
+			},
+			expectedErr: true,
+		},
+		{
+			desc:   "invalid set value",
+			values: "",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a", Type: "number"},
+			},
+			set:         []string{"a=abc"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testTable {
+		out, err := MigrateParameters(tc.values, tc.parameters, &Sources{Set: tc.set}, nil, log.Discard)
+		if tc.expectedErr {
+			assert.Assert(t, err != nil, tc.desc)
+			continue
+		}
+
+		assert.NilError(t, err, tc.desc)
+		assert.Equal(t, out, tc.expectedValues, tc.desc)
+	}
+}
+
+func variables(parameters []storagev1.AppParameter) []string {
+	var ret []string
+	for _, parameter := range parameters {
+		ret = append(ret, parameter.Variable)
+	}
+
+	return ret
+}
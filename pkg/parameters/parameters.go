@@ -1,11 +1,14 @@
 package parameters
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
@@ -15,6 +18,7 @@ import (
 	"github.com/loft-sh/log/survey"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type ParametersFile struct {
@@ -98,37 +102,33 @@ func GetDeepValue(parameters interface{}, path string) interface{} {
 	return nil
 }
 
-func ResolveTemplateParameters(set []string, parameters []storagev1.AppParameter, fileName string) (string, error) {
-	var parametersFile map[string]interface{}
-	if fileName != "" {
-		out, err := os.ReadFile(fileName)
-		if err != nil {
-			return "", errors.Wrap(err, "read parameters file")
-		}
+// ResolveTemplateParameters resolves the values of the given template parameters from the sources.
+// Secret parameters are checked against the given project secrets, which may be nil.
+func ResolveTemplateParameters(parameters []storagev1.AppParameter, sources *Sources, secrets *ProjectSecrets) (string, error) {
+	values, err := sources.loadFiles(parseTemplateParametersFile)
+	if err != nil {
+		return "", err
+	}
 
-		parametersFile = map[string]interface{}{}
-		err = yaml.Unmarshal(out, &parametersFile)
-		if err != nil {
-			return "", errors.Wrap(err, "parse parameters file")
-		}
+	overrides, err := sources.overrides(parameters)
+	if err != nil {
+		return "", err
 	}
 
-	return fillParameters(parameters, set, parametersFile)
+	return fillParameters(parameters, values, overrides, secrets)
 }
 
-func ResolveAppParameters(apps []NamespacedApp, appFilename string, log log.Logger) ([]NamespacedAppWithParameters, error) {
-	var appFile *AppFile
-	if appFilename != "" {
-		out, err := os.ReadFile(appFilename)
-		if err != nil {
-			return nil, errors.Wrap(err, "read parameters file")
-		}
+// ResolveAppParameters resolves the parameters of the given apps from the sources or asks the user
+// for them if no source was specified. Single values can be specified as APP_NAME/PARAMETER=VALUE.
+func ResolveAppParameters(apps []NamespacedApp, sources *Sources, secrets *ProjectSecrets, log log.Logger) ([]NamespacedAppWithParameters, error) {
+	appValues, err := sources.loadFiles(parseAppParametersFile)
+	if err != nil {
+		return nil, err
+	}
 
-		appFile = &AppFile{}
-		err = yaml.Unmarshal(out, appFile)
-		if err != nil {
-			return nil, errors.Wrap(err, "parse parameters file")
-		}
+	err = sources.checkAppSources(apps)
+	if err != nil {
+		return nil, err
 	}
 
 	ret := []NamespacedAppWithParameters{}
@@ -141,8 +141,19 @@ func ResolveAppParameters(apps []NamespacedApp, appFilename string, log log.Logg
 			continue
 		}
 
-		if appFile != nil {
-			parameters, err := getParametersInAppFile(app.App, appFile)
+		appSources := sources.appSources(app.App.Name)
+		overrides, err := appSources.overrides(app.App.Spec.Parameters)
+		if err != nil {
+			return nil, errors.Wrapf(err, "app %s", clihelper.GetDisplayName(app.App.Name, app.App.Spec.DisplayName))
+		}
+
+		values, inFile := appValues[app.App.Name].(map[string]interface{})
+		if len(sources.Files) > 0 || len(overrides) > 0 {
+			if !inFile && len(overrides) == 0 {
+				return nil, fmt.Errorf("couldn't find app %s (%s) in provided parameters file", clihelper.GetDisplayName(app.App.Name, app.App.Spec.DisplayName), app.App.Name)
+			}
+
+			parameters, err := fillParameters(app.App.Spec.Parameters, values, overrides, secrets)
 			if err != nil {
 				return nil, err
 			}
@@ -164,31 +175,12 @@ func ResolveAppParameters(apps []NamespacedApp, appFilename string, log log.Logg
 
 		parameters := map[string]interface{}{}
 		for _, parameter := range app.App.Spec.Parameters {
-			question := parameter.Label
-			if parameter.Required {
-				question += " (Required)"
+			outVal, err := askParameter(parameter, secrets, log)
+			if err != nil {
+				return nil, err
 			}
 
-			for {
-				value, err := log.Question(&survey.QuestionOptions{
-					Question:     question,
-					DefaultValue: parameter.DefaultValue,
-					Options:      parameter.Options,
-					IsPassword:   parameter.Type == "password",
-				})
-				if err != nil {
-					return nil, err
-				}
-
-				outVal, err := VerifyValue(value, parameter)
-				if err != nil {
-					log.Errorf(err.Error())
-					continue
-				}
-
-				SetDeepValue(parameters, parameter.Variable, outVal)
-				break
-			}
+			SetDeepValue(parameters, parameter.Variable, outVal)
 		}
 
 		out, err := yaml.Marshal(parameters)
@@ -205,6 +197,62 @@ func ResolveAppParameters(apps []NamespacedApp, appFilename string, log log.Logg
 	return ret, nil
 }
 
+func askParameter(parameter storagev1.AppParameter, secrets *ProjectSecrets, log log.Logger) (interface{}, error) {
+	question := parameter.Label
+	options := parameter.Options
+	defaultValue := parameter.DefaultValue
+	switch parameter.Type {
+	case "list":
+		// survey has no multi select, so ask for a comma separated list instead
+		if len(options) > 0 {
+			question += " (comma separated, allowed: " + strings.Join(options, ", ") + ")"
+		} else {
+			question += " (comma separated)"
+		}
+		options = nil
+	case "duration":
+		question += " (e.g. 30m or 1h)"
+	case "quantity":
+		question += " (e.g. 500m or 10Gi)"
+	case "secret":
+		question += " (SECRET_NAME.KEY)"
+
+		// offer the existing project secret keys
+		secretOptions, err := secrets.Options(context.TODO())
+		if err != nil {
+			return nil, err
+		} else if len(secretOptions) > 0 {
+			options = secretOptions
+			if !contains(options, defaultValue) {
+				defaultValue = ""
+			}
+		}
+	}
+	if parameter.Required {
+		question += " (Required)"
+	}
+
+	for {
+		value, err := log.Question(&survey.QuestionOptions{
+			Question:     question,
+			DefaultValue: defaultValue,
+			Options:      options,
+			IsPassword:   parameter.Type == "password",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		outVal, err := verifyValue(value, parameter, secrets)
+		if err != nil {
+			log.Errorf(err.Error())
+			continue
+		}
+
+		return outVal, nil
+	}
+}
+
 func VerifyValue(value string, parameter storagev1.AppParameter) (interface{}, error) {
 	switch parameter.Type {
 	case "":
@@ -268,78 +316,132 @@ func VerifyValue(value string, parameter storagev1.AppParameter) (interface{}, e
 		return boolValue, nil
 	case "number":
 		if parameter.DefaultValue != "" && value == "" {
-			intValue, err := strconv.Atoi(parameter.DefaultValue)
+			num, err := parseNumber(parameter.DefaultValue)
 			if err != nil {
 				return nil, errors.Wrapf(err, "parse default value for parameter %s (%s)", parameter.Label, parameter.Variable)
 			}
 
-			return intValue, nil
+			return num, nil
 		}
 		if parameter.Required && value == "" {
 			return nil, fmt.Errorf("parameter %s (%s) is required", parameter.Label, parameter.Variable)
 		}
-		num, err := strconv.Atoi(value)
+		num, err := parseNumber(value)
 		if err != nil {
 			return nil, errors.Wrapf(err, "parse value for parameter %s (%s)", parameter.Label, parameter.Variable)
 		}
-		if parameter.Min != nil && num < *parameter.Min {
+		floatNum, _ := strconv.ParseFloat(value, 64)
+		if parameter.Min != nil && floatNum < float64(*parameter.Min) {
 			return nil, fmt.Errorf("parameter %s (%s) cannot be smaller than %d", parameter.Label, parameter.Variable, *parameter.Min)
 		}
-		if parameter.Max != nil && num > *parameter.Max {
+		if parameter.Max != nil && floatNum > float64(*parameter.Max) {
 			return nil, fmt.Errorf("parameter %s (%s) cannot be greater than %d", parameter.Label, parameter.Variable, *parameter.Max)
 		}
 
 		return num, nil
-	}
+	case "list":
+		if parameter.DefaultValue != "" && value == "" {
+			value = parameter.DefaultValue
+		}
 
-	return nil, fmt.Errorf("unrecognized type %s for parameter %s (%s)", parameter.Type, parameter.Label, parameter.Variable)
-}
+		items, err := parseList(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse value for parameter %s (%s)", parameter.Label, parameter.Variable)
+		}
+		if parameter.Required && len(items) == 0 {
+			return nil, fmt.Errorf("parameter %s (%s) is required", parameter.Label, parameter.Variable)
+		}
 
-func getParametersInAppFile(appObj *managementv1.App, appFile *AppFile) (string, error) {
-	if appFile == nil {
-		return "", nil
-	}
+		out := []interface{}{}
+		for _, item := range items {
+			if len(parameter.Options) > 0 && !contains(parameter.Options, item) {
+				return nil, fmt.Errorf("parameter %s (%s) only allows the values %s, but got %s", parameter.Label, parameter.Variable, strings.Join(parameter.Options, ", "), item)
+			}
+
+			out = append(out, item)
+		}
+
+		return out, nil
+	case "duration":
+		if parameter.DefaultValue != "" && value == "" {
+			value = parameter.DefaultValue
+		}
+		if value == "" {
+			if parameter.Required {
+				return nil, fmt.Errorf("parameter %s (%s) is required", parameter.Label, parameter.Variable)
+			}
+
+			return "", nil
+		}
+
+		_, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse value for parameter %s (%s)", parameter.Label, parameter.Variable)
+		}
+		return value, nil
+	case "quantity":
+		if parameter.DefaultValue != "" && value == "" {
+			value = parameter.DefaultValue
+		}
+		if value == "" {
+			if parameter.Required {
+				return nil, fmt.Errorf("parameter %s (%s) is required", parameter.Label, parameter.Variable)
+			}
+
+			return "", nil
+		}
+
+		_, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse value for parameter %s (%s)", parameter.Label, parameter.Variable)
+		}
+		return value, nil
+	case "secret":
+		if parameter.DefaultValue != "" && value == "" {
+			value = parameter.DefaultValue
+		}
+		if value == "" {
+			if parameter.Required {
+				return nil, fmt.Errorf("parameter %s (%s) is required", parameter.Label, parameter.Variable)
+			}
 
-	for _, app := range appFile.Apps {
-		if app.Name == appObj.Name {
-			return fillParameters(appObj.Spec.Parameters, nil, app.Parameters)
+			return "", nil
 		}
+
+		idx := strings.Index(value, ".")
+		if idx <= 0 || idx == len(value)-1 {
+			return nil, fmt.Errorf("parameter %s (%s) needs to reference a project secret key in the format SECRET_NAME.KEY", parameter.Label, parameter.Variable)
+		}
+		return value, nil
 	}
 
-	return "", fmt.Errorf("couldn't find app %s (%s) in provided parameters file", clihelper.GetDisplayName(appObj.Name, appObj.Spec.DisplayName), appObj.Name)
+	return nil, fmt.Errorf("unrecognized type %s for parameter %s (%s)", parameter.Type, parameter.Label, parameter.Variable)
 }
 
-func fillParameters(parameters []storagev1.AppParameter, set []string, values map[string]interface{}) (string, error) {
+// fillParameters verifies and sets the value of each parameter. Values from overrides take
+// precedence over the ones in values, parameters without a value fall back to their default.
+func fillParameters(parameters []storagev1.AppParameter, values map[string]interface{}, overrides map[string]interface{}, secrets *ProjectSecrets) (string, error) {
 	if values == nil {
 		values = map[string]interface{}{}
 	}
 
-	// parse set array
-	setMap, err := parseSet(parameters, set)
-	if err != nil {
-		return "", err
-	}
-
 	// apply parameters
 	for _, parameter := range parameters {
-		strVal, ok := setMap[parameter.Variable]
+		val, ok := overrides[parameter.Variable]
 		if !ok {
-			val := GetDeepValue(values, parameter.Variable)
-			if val != nil {
-				switch t := val.(type) {
-				case string:
-					strVal = t
-				case int:
-					strVal = strconv.Itoa(t)
-				case bool:
-					strVal = strconv.FormatBool(t)
-				default:
-					return "", fmt.Errorf("unrecognized type for parameter %s (%s) in file: %v", parameter.Label, parameter.Variable, t)
-				}
+			val = GetDeepValue(values, parameter.Variable)
+		}
+
+		strVal := ""
+		if val != nil {
+			var err error
+			strVal, err = valueToString(val, parameter)
+			if err != nil {
+				return "", err
 			}
 		}
 
-		outVal, err := VerifyValue(strVal, parameter)
+		outVal, err := verifyValue(strVal, parameter, secrets)
 		if err != nil {
 			return "", errors.Wrap(err, "validate parameters")
 		}
@@ -358,22 +460,9 @@ func fillParameters(parameters []storagev1.AppParameter, set []string, values ma
 func parseSet(parameters []storagev1.AppParameter, set []string) (map[string]string, error) {
 	setValues := map[string]string{}
 	for _, s := range set {
-		splitted := strings.Split(s, "=")
-		if len(splitted) <= 1 {
-			return nil, fmt.Errorf("error parsing --set %s: need parameter=value format", s)
-		}
-
-		key := splitted[0]
-		value := strings.Join(splitted[1:], "=")
-		found := false
-		for _, parameter := range parameters {
-			if parameter.Variable == key {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return nil, fmt.Errorf("parameter %s doesn't exist on template", key)
+		key, value, err := parseKeyValue(parameters, "--set", s)
+		if err != nil {
+			return nil, err
 		}
 
 		setValues[key] = value
@@ -381,3 +470,112 @@ func parseSet(parameters []storagev1.AppParameter, set []string) (map[string]str
 
 	return setValues, nil
 }
+
+func parseKeyValue(parameters []storagev1.AppParameter, flag string, s string) (string, string, error) {
+	splitted := strings.Split(s, "=")
+	if len(splitted) <= 1 {
+		return "", "", fmt.Errorf("error parsing %s %s: need parameter=value format", flag, s)
+	}
+
+	key := splitted[0]
+	value := strings.Join(splitted[1:], "=")
+	if _, found := findParameter(parameters, key); !found {
+		return "", "", fmt.Errorf("parameter %s doesn't exist on template", key)
+	}
+
+	return key, value, nil
+}
+
+func valueToString(val interface{}, parameter storagev1.AppParameter) (string, error) {
+	switch t := val.(type) {
+	case string:
+		return t, nil
+	case int:
+		return strconv.Itoa(t), nil
+	case int64:
+		return strconv.FormatInt(t, 10), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case []interface{}:
+		out, err := json.Marshal(t)
+		if err != nil {
+			return "", errors.Wrapf(err, "marshal value for parameter %s (%s)", parameter.Label, parameter.Variable)
+		}
+
+		return string(out), nil
+	}
+
+	return "", fmt.Errorf("unrecognized type for parameter %s (%s): %v", parameter.Label, parameter.Variable, val)
+}
+
+// parseNumber parses an integer or decimal number. Integral values are returned as int so they
+// are written as integers into the parameters.
+func parseNumber(value string) (interface{}, error) {
+	intValue, err := strconv.Atoi(value)
+	if err == nil {
+		return intValue, nil
+	}
+
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	} else if math.IsInf(floatValue, 0) || math.IsNaN(floatValue) {
+		return nil, fmt.Errorf("%s is not a finite number", value)
+	}
+
+	return floatValue, nil
+}
+
+// parseList parses either a JSON array of strings or a comma separated list
+func parseList(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(value, "[") {
+		items := []interface{}{}
+		err := json.Unmarshal([]byte(value), &items)
+		if err != nil {
+			return nil, err
+		}
+
+		out := []string{}
+		for _, item := range items {
+			switch t := item.(type) {
+			case string:
+				out = append(out, t)
+			case float64:
+				out = append(out, strconv.FormatFloat(t, 'f', -1, 64))
+			case bool:
+				out = append(out, strconv.FormatBool(t))
+			default:
+				return nil, fmt.Errorf("unsupported list item %v", item)
+			}
+		}
+
+		return out, nil
+	}
+
+	out := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+
+	return out, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
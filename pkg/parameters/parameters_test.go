@@ -0,0 +1,286 @@
+package parameters
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/log"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyValue(t *testing.T) {
+	type testCase struct {
+		desc          string
+		value         string
+		parameter     storagev1.AppParameter
+		expectedValue interface{}
+		expectedErr   string
+	}
+
+	min, max := 1, 10
+	testTable := []testCase{
+		{
+			desc:          "integer number",
+			value:         "3",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "number"},
+			expectedValue: 3,
+		},
+		{
+			desc:          "decimal number",
+			value:         "2.5",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "number", Min: &min, Max: &max},
+			expectedValue: 2.5,
+		},
+		{
+			desc:        "decimal number below minimum",
+			value:       "0.5",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "number", Min: &min},
+			expectedErr: "cannot be smaller than 1",
+		},
+		{
+			desc:          "decimal default",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "number", DefaultValue: "0.25"},
+			expectedValue: 0.25,
+		},
+		{
+			desc:          "comma separated list",
+			value:         "a, b",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "list", Options: []string{"a", "b", "c"}},
+			expectedValue: []interface{}{"a", "b"},
+		},
+		{
+			desc:          "json list",
+			value:         `["c"]`,
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "list", Options: []string{"a", "b", "c"}},
+			expectedValue: []interface{}{"c"},
+		},
+		{
+			desc:        "list value not in options",
+			value:       "a,d",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "list", Options: []string{"a", "b", "c"}},
+			expectedErr: "only allows the values a, b, c, but got d",
+		},
+		{
+			desc:        "required empty list",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "list", Required: true},
+			expectedErr: "is required",
+		},
+		{
+			desc:          "duration",
+			value:         "1h30m",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "duration"},
+			expectedValue: "1h30m",
+		},
+		{
+			desc:        "invalid duration",
+			value:       "1 hour",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "duration"},
+			expectedErr: "parse value for parameter",
+		},
+		{
+			desc:          "quantity",
+			value:         "10Gi",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "quantity"},
+			expectedValue: "10Gi",
+		},
+		{
+			desc:        "invalid quantity",
+			value:       "10GB",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "quantity"},
+			expectedErr: "parse value for parameter",
+		},
+		{
+			desc:          "secret reference",
+			value:         "my-secret.password",
+			parameter:     storagev1.AppParameter{Variable: "a", Type: "secret"},
+			expectedValue: "my-secret.password",
+		},
+		{
+			desc:        "secret without key",
+			value:       "my-secret",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "secret"},
+			expectedErr: "SECRET_NAME.KEY",
+		},
+		{
+			desc:        "unknown type",
+			parameter:   storagev1.AppParameter{Variable: "a", Type: "unknown"},
+			expectedErr: "unrecognized type unknown",
+		},
+	}
+
+	for _, tc := range testTable {
+		out, err := VerifyValue(tc.value, tc.parameter)
+		if tc.expectedErr != "" {
+			assert.ErrorContains(t, err, tc.expectedErr, tc.desc)
+			continue
+		}
+
+		assert.NilError(t, err, tc.desc)
+		assert.DeepEqual(t, out, tc.expectedValue)
+	}
+}
+
+func TestFillParameters(t *testing.T) {
+	type testCase struct {
+		desc           string
+		parameters     []storagev1.AppParameter
+		values         map[string]interface{}
+		overrides      map[string]interface{}
+		expectedValues string
+		expectedErr    bool
+	}
+
+	testTable := []testCase{
+		{
+			desc: "values from file",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a"},
+				{Variable: "nested.b", Type: "number"},
+				{Variable: "c", Type: "boolean"},
+				{Variable: "d", Type: "list"},
+			},
+			values: map[string]interface{}{
+				"a":      "hello",
+				"nested": map[string]interface{}{"b": float64(3)},
+				"c":      true,
+				"d":      []interface{}{"x", "y"},
+			},
+			expectedValues: "a: hello\nc: true\nd:\n- x\n- \"y\"\nnested:\n  b: 3\n",
+		},
+		{
+			desc: "overrides take precedence over file values",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a"},
+				{Variable: "b", Type: "number"},
+			},
+			values:         map[string]interface{}{"a": "file", "b": float64(1)},
+			overrides:      map[string]interface{}{"a": "set", "b": float64(2)},
+			expectedValues: "a: set\nb: 2\n",
+		},
+		{
+			desc: "default value for missing parameter",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a", DefaultValue: "default"},
+			},
+			expectedValues: "a: default\n",
+		},
+		{
+			desc: "missing required parameter",
+			parameters: []storagev1.AppParameter{
+				{Variable: "a", Required: true},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testTable {
+		out, err := fillParameters(tc.parameters, tc.values, tc.overrides, nil)
+		if tc.expectedErr {
+			assert.Assert(t, err != nil, tc.desc)
+			continue
+		}
+
+		assert.NilError(t, err, tc.desc)
+		assert.Equal(t, out, tc.expectedValues, tc.desc)
+	}
+}
+
+func TestResolveTemplateParameters(t *testing.T) {
+	dir := t.TempDir()
+	firstFile := writeFile(t, dir, "first.yaml", "a: first\nb: first\nnested:\n  c: first\n  d: first\n")
+	secondFile := writeFile(t, dir, "second.yaml", "b: second\nnested:\n  c: ${TEST_PARAMETER_VALUE}\n  e: $${NOT_INTERPOLATED}\n")
+	certFile := writeFile(t, dir, "cert.pem", "-----BEGIN CERTIFICATE-----\nabc\n-----END CERTIFICATE-----\n")
+	t.Setenv("TEST_PARAMETER_VALUE", "from-env")
+
+	parameters := []storagev1.AppParameter{
+		{Variable: "a"},
+		{Variable: "b"},
+		{Variable: "nested.c"},
+		{Variable: "nested.d"},
+		{Variable: "nested.e"},
+		{Variable: "cert", Type: "multiline"},
+		{Variable: "replicas", Type: "number"},
+		{Variable: "precedence"},
+	}
+
+	out, err := ResolveTemplateParameters(parameters, &Sources{
+		Files:   []string{firstFile, secondFile},
+		Set:     []string{"precedence=set"},
+		SetFile: []string{"cert=" + certFile, "precedence=" + certFile},
+		SetJSON: []string{"replicas=3", "precedence=\"json\""},
+	}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, out, `a: first
+b: second
+cert: |
+  -----BEGIN CERTIFICATE-----
+  abc
+  -----END CERTIFICATE-----
+nested:
+  c: from-env
+  d: first
+  e: ${NOT_INTERPOLATED}
+precedence: set
+replicas: 3
+`)
+
+	// environment variables can't change the structure of the file
+	t.Setenv("TEST_PARAMETER_INJECTION", "{b: injected}")
+	out, err = ResolveTemplateParameters([]storagev1.AppParameter{{Variable: "a"}}, &Sources{Files: []string{writeFile(t, dir, "injection.yaml", "a: ${TEST_PARAMETER_INJECTION}\n")}}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, out, "a: '{b: injected}'\n")
+
+	_, err = ResolveTemplateParameters(parameters, &Sources{Files: []string{writeFile(t, dir, "missing.yaml", "a: ${TEST_PARAMETER_MISSING}\n")}}, nil)
+	assert.ErrorContains(t, err, "TEST_PARAMETER_MISSING")
+
+	_, err = ResolveTemplateParameters(parameters, &Sources{SetJSON: []string{"unknown=1"}}, nil)
+	assert.ErrorContains(t, err, "parameter unknown doesn't exist on template")
+}
+
+func TestResolveAppParameters(t *testing.T) {
+	dir := t.TempDir()
+	appFile := writeFile(t, dir, "apps.yaml", "apps:\n- name: first\n  parameters:\n    a: file\n    b: file\n")
+
+	apps := []NamespacedApp{
+		{App: newApp("first", storagev1.AppParameter{Variable: "a"}, storagev1.AppParameter{Variable: "b"})},
+		{App: newApp("second", storagev1.AppParameter{Variable: "a"})},
+	}
+
+	out, err := ResolveAppParameters(apps, &Sources{
+		Files: []string{appFile},
+		Set:   []string{"first/b=set", "second/a=set"},
+	}, nil, log.Discard)
+	assert.NilError(t, err)
+	assert.Equal(t, len(out), 2)
+	assert.Equal(t, out[0].Parameters, "a: file\nb: set\n")
+	assert.Equal(t, out[1].Parameters, "a: set\n")
+
+	_, err = ResolveAppParameters(apps, &Sources{Files: []string{appFile}}, nil, log.Discard)
+	assert.ErrorContains(t, err, "couldn't find app second")
+
+	_, err = ResolveAppParameters(apps, &Sources{Set: []string{"a=set"}}, nil, log.Discard)
+	assert.ErrorContains(t, err, "need APP_NAME/PARAMETER=VALUE format")
+
+	_, err = ResolveAppParameters(apps, &Sources{SetJSON: []string{"third/a=1"}}, nil, log.Discard)
+	assert.ErrorContains(t, err, "app third doesn't exist or has no parameters")
+
+	_, err = ResolveAppParameters(apps, &Sources{Set: []string{"first/c=set"}}, nil, log.Discard)
+	assert.ErrorContains(t, err, "parameter c doesn't exist on template")
+}
+
+func newApp(name string, parameters ...storagev1.AppParameter) *managementv1.App {
+	app := &managementv1.App{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	app.Spec.Parameters = parameters
+	return app
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(content), 0644)
+	assert.NilError(t, err)
+	return path
+}
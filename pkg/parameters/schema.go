@@ -0,0 +1,147 @@
+package parameters
+
+import (
+	"strconv"
+	"strings"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/pkg/errors"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+const (
+	durationPattern = `^(0|-?([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+	quantityPattern = `^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+|[numkMGTPE]|[KMGTPE]i)?$`
+	secretPattern   = `^[^.]+\..+$`
+)
+
+// JSONSchema is the subset of a JSON schema that is needed to describe template parameters
+type JSONSchema struct {
+	Schema string `json:"$schema,omitempty"`
+	Title  string `json:"title,omitempty"`
+
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+
+	Default   interface{}   `json:"default,omitempty"`
+	Examples  []interface{} `json:"examples,omitempty"`
+	WriteOnly bool          `json:"writeOnly,omitempty"`
+	Enum      []string      `json:"enum,omitempty"`
+	Pattern   string        `json:"pattern,omitempty"`
+	Not       *JSONSchema   `json:"not,omitempty"`
+	Minimum   *int          `json:"minimum,omitempty"`
+	Maximum   *int          `json:"maximum,omitempty"`
+}
+
+// GenerateJSONSchema creates a JSON schema for a parameters file of the given parameters. Nested
+// variables such as a.b are converted into nested objects.
+func GenerateJSONSchema(title string, parameters []storagev1.AppParameter) (*JSONSchema, error) {
+	schema := &JSONSchema{
+		Schema:     jsonSchemaDraft,
+		Title:      title,
+		Type:       "object",
+		Properties: map[string]*JSONSchema{},
+	}
+
+	for _, parameter := range parameters {
+		property, err := parameterSchema(parameter)
+		if err != nil {
+			return nil, err
+		}
+
+		// walk down to the parent object
+		parent := schema
+		segments := strings.Split(parameter.Variable, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := parent.Properties[segment]
+			if !ok || child.Type != "object" {
+				child = &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+				parent.Properties[segment] = child
+			}
+
+			parent = child
+		}
+
+		name := segments[len(segments)-1]
+		parent.Properties[name] = property
+		if parameter.Required && parameter.DefaultValue == "" {
+			parent.Required = append(parent.Required, name)
+		}
+	}
+
+	return schema, nil
+}
+
+func parameterSchema(parameter storagev1.AppParameter) (*JSONSchema, error) {
+	schema := &JSONSchema{
+		Title:       parameter.Label,
+		Description: parameter.Description,
+	}
+
+	switch parameter.Type {
+	case "", "string", "multiline", "password":
+		schema.Type = "string"
+		schema.WriteOnly = parameter.Type == "password"
+		schema.Pattern = parameter.Validation
+		if parameter.Invalidation != "" {
+			schema.Not = &JSONSchema{Pattern: parameter.Invalidation}
+		}
+		if parameter.DefaultValue != "" {
+			schema.Default = parameter.DefaultValue
+		}
+		for _, option := range parameter.Options {
+			schema.Examples = append(schema.Examples, option)
+		}
+	case "boolean":
+		schema.Type = "boolean"
+		if parameter.DefaultValue != "" {
+			defaultValue, err := strconv.ParseBool(parameter.DefaultValue)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse default value for parameter %s (%s)", parameter.Label, parameter.Variable)
+			}
+
+			schema.Default = defaultValue
+		}
+	case "number":
+		schema.Type = "number"
+		schema.Minimum = parameter.Min
+		schema.Maximum = parameter.Max
+		if parameter.DefaultValue != "" {
+			defaultValue, err := parseNumber(parameter.DefaultValue)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse default value for parameter %s (%s)", parameter.Label, parameter.Variable)
+			}
+
+			schema.Default = defaultValue
+		}
+	case "list":
+		schema.Type = "array"
+		schema.Items = &JSONSchema{Type: "string", Enum: parameter.Options}
+		if parameter.DefaultValue != "" {
+			items, err := parseList(parameter.DefaultValue)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse default value for parameter %s (%s)", parameter.Label, parameter.Variable)
+			}
+
+			schema.Default = items
+		}
+	case "duration", "quantity", "secret":
+		schema.Type = "string"
+		schema.Pattern = map[string]string{
+			"duration": durationPattern,
+			"quantity": quantityPattern,
+			"secret":   secretPattern,
+		}[parameter.Type]
+		if parameter.DefaultValue != "" {
+			schema.Default = parameter.DefaultValue
+		}
+	default:
+		return nil, errors.Errorf("unrecognized type %s for parameter %s (%s)", parameter.Type, parameter.Label, parameter.Variable)
+	}
+
+	return schema, nil
+}
@@ -0,0 +1,34 @@
+package parameters
+
+import (
+	"encoding/json"
+	"testing"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestGenerateJSONSchema(t *testing.T) {
+	min, max := 1, 5
+	schema, err := GenerateJSONSchema("my-template", []storagev1.AppParameter{
+		{Variable: "name", Label: "Name", Required: true, Validation: "^[a-z]+$", Invalidation: "^admin$"},
+		{Variable: "password", Type: "password"},
+		{Variable: "nested.replicas", Type: "number", Min: &min, Max: &max, DefaultValue: "2", Required: true},
+		{Variable: "nested.enabled", Type: "boolean", DefaultValue: "true"},
+		{Variable: "size", Options: []string{"small", "large"}},
+		{Variable: "zones", Type: "list", Options: []string{"a", "b"}, DefaultValue: "a"},
+	})
+	assert.NilError(t, err)
+
+	out, err := json.Marshal(schema)
+	assert.NilError(t, err)
+	assert.Equal(t, string(out), `{"$schema":"http://json-schema.org/draft-07/schema#","title":"my-template","type":"object",`+
+		`"properties":{"name":{"title":"Name","type":"string","pattern":"^[a-z]+$","not":{"pattern":"^admin$"}},`+
+		`"nested":{"type":"object","properties":{"enabled":{"type":"boolean","default":true},"replicas":{"type":"number","default":2,"minimum":1,"maximum":5}}},`+
+		`"password":{"type":"string","writeOnly":true},`+
+		`"size":{"type":"string","examples":["small","large"]},`+
+		`"zones":{"type":"array","items":{"type":"string","enum":["a","b"]},"default":["a"]}},"required":["name"]}`)
+
+	_, err = GenerateJSONSchema("invalid", []storagev1.AppParameter{{Variable: "a", Type: "unknown"}})
+	assert.ErrorContains(t, err, "unrecognized type unknown")
+}
@@ -0,0 +1,116 @@
+package parameters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/pkg/client/naming"
+	"github.com/loft-sh/loftctl/v3/pkg/kube"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProjectSecrets checks the values of secret parameters against the project secrets of a project.
+// A nil ProjectSecrets only checks the format of the values.
+type ProjectSecrets struct {
+	client  kube.Interface
+	project string
+
+	secrets []managementv1.ProjectSecret
+}
+
+// NewProjectSecrets creates a new ProjectSecrets for the given project
+func NewProjectSecrets(managementClient kube.Interface, project string) *ProjectSecrets {
+	return &ProjectSecrets{
+		client:  managementClient,
+		project: project,
+	}
+}
+
+// list returns the project secrets of the project, they are only retrieved once
+func (p *ProjectSecrets) list(ctx context.Context) ([]managementv1.ProjectSecret, error) {
+	if p.secrets != nil {
+		return p.secrets, nil
+	}
+
+	projectSecrets, err := p.client.Loft().ManagementV1().ProjectSecrets(naming.ProjectNamespace(p.project)).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "list project secrets in project %s", p.project)
+	}
+
+	p.secrets = projectSecrets.Items
+	if p.secrets == nil {
+		p.secrets = []managementv1.ProjectSecret{}
+	}
+	return p.secrets, nil
+}
+
+// Options returns all keys of the project secrets in the format SECRET_NAME.KEY
+func (p *ProjectSecrets) Options(ctx context.Context) ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	projectSecrets, err := p.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []string{}
+	for _, projectSecret := range projectSecrets {
+		for key := range projectSecret.Spec.Data {
+			options = append(options, projectSecret.Name+"."+key)
+		}
+	}
+
+	sort.Strings(options)
+	return options, nil
+}
+
+// Verify returns an error if the value of the given secret parameter doesn't reference an
+// existing key of a project secret
+func (p *ProjectSecrets) Verify(ctx context.Context, value string, parameter storagev1.AppParameter) error {
+	if p == nil || parameter.Type != "secret" || value == "" {
+		return nil
+	}
+
+	projectSecrets, err := p.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	secretName, key, _ := strings.Cut(value, ".")
+	for _, projectSecret := range projectSecrets {
+		if projectSecret.Name != secretName {
+			continue
+		}
+
+		if _, ok := projectSecret.Spec.Data[key]; !ok {
+			return fmt.Errorf("parameter %s (%s) references key %s that doesn't exist in project secret %s", parameter.Label, parameter.Variable, key, secretName)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("parameter %s (%s) references project secret %s that doesn't exist in project %s", parameter.Label, parameter.Variable, secretName, p.project)
+}
+
+// verifyValue verifies the value like VerifyValue and additionally checks that secret parameters
+// reference an existing project secret key
+func verifyValue(value string, parameter storagev1.AppParameter, secrets *ProjectSecrets) (interface{}, error) {
+	outVal, err := VerifyValue(value, parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	strVal, _ := outVal.(string)
+	err = secrets.Verify(context.TODO(), strVal, parameter)
+	if err != nil {
+		return nil, err
+	}
+
+	return outVal, nil
+}
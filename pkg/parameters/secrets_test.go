@@ -0,0 +1,69 @@
+package parameters
+
+import (
+	"context"
+	"testing"
+
+	agentloftclient "github.com/loft-sh/agentapi/v3/pkg/client/loft/clientset_generated/clientset"
+	managementv1 "github.com/loft-sh/api/v3/pkg/apis/management/v1"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	loftclient "github.com/loft-sh/api/v3/pkg/client/clientset_generated/clientset"
+	loftfake "github.com/loft-sh/api/v3/pkg/client/clientset_generated/clientset/fake"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeKube struct {
+	*fake.Clientset
+	loftClient *loftfake.Clientset
+}
+
+func (f *fakeKube) Loft() loftclient.Interface {
+	return f.loftClient
+}
+
+func (f *fakeKube) Agent() agentloftclient.Interface {
+	return nil
+}
+
+func newFakeProjectSecrets() *ProjectSecrets {
+	return NewProjectSecrets(&fakeKube{
+		Clientset: fake.NewSimpleClientset(),
+		loftClient: loftfake.NewSimpleClientset(
+			&managementv1.ProjectSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "database", Namespace: "loft-p-my-project"},
+				Spec:       managementv1.ProjectSecretSpec{Data: map[string][]byte{"password": []byte("secret"), "user": []byte("admin")}},
+			},
+			&managementv1.ProjectSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "loft-p-other-project"},
+				Spec:       managementv1.ProjectSecretSpec{Data: map[string][]byte{"token": []byte("secret")}},
+			},
+		),
+	}, "my-project")
+}
+
+func TestProjectSecrets(t *testing.T) {
+	secrets := newFakeProjectSecrets()
+	parameter := storagev1.AppParameter{Label: "Password", Variable: "password", Type: "secret"}
+
+	options, err := secrets.Options(context.TODO())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, options, []string{"database.password", "database.user"})
+
+	assert.NilError(t, secrets.Verify(context.TODO(), "database.password", parameter))
+	assert.NilError(t, secrets.Verify(context.TODO(), "", parameter))
+	assert.NilError(t, secrets.Verify(context.TODO(), "other.token", storagev1.AppParameter{Variable: "plain"}))
+	assert.Error(t, secrets.Verify(context.TODO(), "database.token", parameter), "parameter Password (password) references key token that doesn't exist in project secret database")
+	assert.Error(t, secrets.Verify(context.TODO(), "other.token", parameter), "parameter Password (password) references project secret other that doesn't exist in project my-project")
+
+	// without project secrets only the format is checked
+	var noSecrets *ProjectSecrets
+	options, err = noSecrets.Options(context.TODO())
+	assert.NilError(t, err)
+	assert.Equal(t, len(options), 0)
+	_, err = verifyValue("other.token", parameter, noSecrets)
+	assert.NilError(t, err)
+	_, err = verifyValue("other", parameter, noSecrets)
+	assert.ErrorContains(t, err, "SECRET_NAME.KEY")
+}
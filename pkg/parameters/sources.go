@@ -0,0 +1,268 @@
+package parameters
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/pkg/errors"
+	flag "github.com/spf13/pflag"
+)
+
+var envVariableRegEx = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Sources holds the parameter values specified by the user. If a parameter is specified in
+// several sources, the value with the highest precedence wins. From lowest to highest:
+//
+//  1. parameter files (--parameters), later files override earlier ones
+//  2. typed values (--set-json)
+//  3. file contents (--set-file)
+//  4. plain values (--set)
+//
+// String values in parameter files may reference environment variables as ${NAME}, use $${NAME}
+// for a literal ${NAME}. Variables are replaced after the file is parsed, so their values are
+// always strings and can't change the structure of the file.
+type Sources struct {
+	Files   []string
+	Set     []string
+	SetFile []string
+	SetJSON []string
+}
+
+// AddFlags adds the flags for all parameter sources to the given flag set
+func (s *Sources) AddFlags(flags *flag.FlagSet) {
+	flags.StringArrayVar(&s.Files, "parameters", []string{}, ParametersFileUsage)
+	flags.StringSliceVar(&s.Set, "set", []string{}, SetUsage)
+	flags.StringArrayVar(&s.SetFile, "set-file", []string{}, SetFileUsage)
+	flags.StringArrayVar(&s.SetJSON, "set-json", []string{}, SetJSONUsage)
+}
+
+// The usages of the parameter source flags state the precedence of the sources
+const (
+	ParametersFileUsage = "A file with parameter values. Can be specified multiple times, later files override earlier ones. ${NAME} in string values is replaced with the environment variable NAME. Has the lowest precedence"
+	SetJSONUsage        = "Sets a template parameter to a JSON value, overrides parameter files. E.g. --set-json myParameter=5"
+	SetFileUsage        = "Sets a template parameter to the contents of a file, overrides parameter files and --set-json. E.g. --set-file myParameter=./cert.pem"
+	SetUsage            = "Allows specific template parameters to be set, overrides all other parameter sources. E.g. --set myParameter=myValue"
+)
+
+// Empty returns true if no parameter values were specified
+func (s *Sources) Empty() bool {
+	return len(s.Files) == 0 && len(s.Set) == 0 && len(s.SetFile) == 0 && len(s.SetJSON) == 0
+}
+
+// loadFiles reads all parameter files and merges them in order
+func (s *Sources) loadFiles(into func(out []byte) (map[string]interface{}, error)) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, fileName := range s.Files {
+		out, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, errors.Wrap(err, "read parameters file")
+		}
+
+		fileValues, err := into(out)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse parameters file %s", fileName)
+		}
+
+		err = interpolateEnv(fileValues)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parameters file %s", fileName)
+		}
+
+		mergeValues(values, fileValues)
+	}
+
+	return values, nil
+}
+
+// overrides returns the values specified via --set-json, --set-file and --set
+func (s *Sources) overrides(parameters []storagev1.AppParameter) (map[string]interface{}, error) {
+	overrides := map[string]interface{}{}
+	for _, setJSON := range s.SetJSON {
+		key, value, err := parseKeyValue(parameters, "--set-json", setJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		var jsonValue interface{}
+		err = json.Unmarshal([]byte(value), &jsonValue)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing --set-json %s: %w", setJSON, err)
+		}
+
+		overrides[key] = jsonValue
+	}
+	for _, setFile := range s.SetFile {
+		key, value, err := parseKeyValue(parameters, "--set-file", setFile)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("error reading --set-file %s: %w", setFile, err)
+		}
+
+		overrides[key] = string(out)
+	}
+
+	setValues, err := parseSet(parameters, s.Set)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range setValues {
+		overrides[key] = value
+	}
+
+	return overrides, nil
+}
+
+// appSources returns the --set, --set-file and --set-json values that were specified for the
+// given app in the format APP_NAME/PARAMETER=VALUE
+func (s *Sources) appSources(appName string) *Sources {
+	prefix := appName + "/"
+	filter := func(values []string) []string {
+		ret := []string{}
+		for _, value := range values {
+			if strings.HasPrefix(value, prefix) {
+				ret = append(ret, strings.TrimPrefix(value, prefix))
+			}
+		}
+		return ret
+	}
+
+	return &Sources{
+		Set:     filter(s.Set),
+		SetFile: filter(s.SetFile),
+		SetJSON: filter(s.SetJSON),
+	}
+}
+
+// checkAppSources returns an error for --set, --set-file and --set-json values that don't start
+// with the name of one of the given apps that has parameters
+func (s *Sources) checkAppSources(apps []NamespacedApp) error {
+	for _, flagValues := range []struct {
+		flag   string
+		values []string
+	}{
+		{flag: "--set", values: s.Set},
+		{flag: "--set-file", values: s.SetFile},
+		{flag: "--set-json", values: s.SetJSON},
+	} {
+		for _, value := range flagValues.values {
+			key, _, _ := strings.Cut(value, "=")
+			appName, _, found := strings.Cut(key, "/")
+			if !found {
+				return fmt.Errorf("error parsing %s %s: need APP_NAME/PARAMETER=VALUE format", flagValues.flag, value)
+			}
+
+			matched := false
+			for _, app := range apps {
+				if app.App.Name == appName && len(app.App.Spec.Parameters) > 0 {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("error parsing %s %s: app %s doesn't exist or has no parameters", flagValues.flag, value, appName)
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseTemplateParametersFile(out []byte) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	err := yaml.Unmarshal(out, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func parseAppParametersFile(out []byte) (map[string]interface{}, error) {
+	appFile := &AppFile{}
+	err := yaml.Unmarshal(out, appFile)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	for _, app := range appFile.Apps {
+		appValues, ok := values[app.Name].(map[string]interface{})
+		if !ok {
+			appValues = map[string]interface{}{}
+			values[app.Name] = appValues
+		}
+
+		mergeValues(appValues, app.Parameters)
+	}
+
+	return values, nil
+}
+
+// interpolateEnv replaces ${NAME} in all string values with the value of the environment
+// variable NAME
+func interpolateEnv(values map[string]interface{}) error {
+	missing := []string{}
+	for key, value := range values {
+		values[key] = interpolateEnvValue(value, &missing)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("environment variables %s are not set", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func interpolateEnvValue(value interface{}, missing *[]string) interface{} {
+	switch t := value.(type) {
+	case string:
+		return envVariableRegEx.ReplaceAllStringFunc(t, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+
+			name := envVariableRegEx.FindStringSubmatch(match)[1]
+			envValue, ok := os.LookupEnv(name)
+			if !ok {
+				*missing = append(*missing, name)
+				return match
+			}
+
+			return envValue
+		})
+	case map[string]interface{}:
+		for key, item := range t {
+			t[key] = interpolateEnvValue(item, missing)
+		}
+	case []interface{}:
+		for i, item := range t {
+			t[i] = interpolateEnvValue(item, missing)
+		}
+	}
+
+	return value
+}
+
+// mergeValues deep merges src into dst
+func mergeValues(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+
+		dst[key] = srcValue
+	}
+}
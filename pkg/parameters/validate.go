@@ -0,0 +1,77 @@
+package parameters
+
+import (
+	"fmt"
+	"sort"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+)
+
+// ValidateParameters checks the values from the sources against the given parameters without
+// resolving them. In contrast to ResolveTemplateParameters it doesn't stop at the first invalid
+// value, but returns all problems found. Secret parameters are checked against the given project
+// secrets, which may be nil. The returned error is only set if the sources couldn't be read.
+func ValidateParameters(parameters []storagev1.AppParameter, sources *Sources, secrets *ProjectSecrets) ([]error, error) {
+	values, err := sources.loadFiles(parseTemplateParametersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := sources.overrides(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	validationErrors := []error{}
+	for _, parameter := range parameters {
+		val, ok := overrides[parameter.Variable]
+		if !ok {
+			val = GetDeepValue(values, parameter.Variable)
+		}
+
+		strVal := ""
+		if val != nil {
+			strVal, err = valueToString(val, parameter)
+			if err != nil {
+				validationErrors = append(validationErrors, err)
+				continue
+			}
+		}
+
+		_, err = verifyValue(strVal, parameter, secrets)
+		if err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+	}
+
+	// check for values that don't belong to any parameter
+	for _, path := range valuePaths(values, "") {
+		if _, ok := findParameter(parameters, path); !ok {
+			validationErrors = append(validationErrors, fmt.Errorf("parameter %s doesn't exist on template", path))
+		}
+	}
+
+	return validationErrors, nil
+}
+
+// valuePaths returns the sorted paths of all values that are not maps in the format used by
+// parameter variables. Lists are values of list parameters and therefore not descended into.
+func valuePaths(values interface{}, prefix string) []string {
+	paths := []string{}
+	switch t := values.(type) {
+	case map[string]interface{}:
+		for key, value := range t {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+
+			paths = append(paths, valuePaths(value, path)...)
+		}
+	default:
+		paths = append(paths, prefix)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
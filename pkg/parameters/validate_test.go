@@ -0,0 +1,47 @@
+package parameters
+
+import (
+	"testing"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateParameters(t *testing.T) {
+	dir := t.TempDir()
+	min := 1
+	parameters := []storagev1.AppParameter{
+		{Label: "Name", Variable: "name", Required: true},
+		{Label: "Replicas", Variable: "nested.replicas", Type: "number", Min: &min},
+		{Label: "Enabled", Variable: "enabled", Type: "boolean"},
+		{Label: "Domain", Variable: "domain", Validation: "^[a-z.]+$"},
+		{Label: "Zones", Variable: "zones", Type: "list"},
+		{Label: "Password", Variable: "password", Type: "secret"},
+	}
+
+	validationErrors, err := ValidateParameters(parameters, &Sources{
+		Files: []string{writeFile(t, dir, "valid.yaml", "name: test\nnested:\n  replicas: 2\nenabled: true\ndomain: loft.sh\nzones: [a, b]\npassword: database.password\n")},
+	}, newFakeProjectSecrets())
+	assert.NilError(t, err)
+	assert.Equal(t, len(validationErrors), 0)
+
+	validationErrors, err = ValidateParameters(parameters, &Sources{
+		Files: []string{writeFile(t, dir, "invalid.yaml", "nested:\n  replicas: 0\n  unknown: a\nenabled: maybe\ndomain: LOFT\npassword: database.token\n")},
+	}, newFakeProjectSecrets())
+	assert.NilError(t, err)
+	messages := []string{}
+	for _, validationErr := range validationErrors {
+		messages = append(messages, validationErr.Error())
+	}
+	assert.DeepEqual(t, messages, []string{
+		"parameter Name (name) is required",
+		"parameter Replicas (nested.replicas) cannot be smaller than 1",
+		`parse value for parameter Enabled (enabled): strconv.ParseBool: parsing "maybe": invalid syntax`,
+		"parameter Domain (domain) needs to match regex ^[a-z.]+$",
+		"parameter Password (password) references key token that doesn't exist in project secret database",
+		"parameter nested.unknown doesn't exist on template",
+	})
+
+	_, err = ValidateParameters(parameters, &Sources{Files: []string{"does-not-exist.yaml"}}, nil)
+	assert.ErrorContains(t, err, "read parameters file")
+}
@@ -0,0 +1,171 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/log"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport/spdy"
+)
+
+var (
+	reconnectDelay   = 2 * time.Second
+	podCheckInterval = 2 * time.Second
+)
+
+// ForwardWithReconnect forwards the given ports to a pod of the target until the context is
+// cancelled. If the pod goes away, e.g. because it restarted, a new pod is selected and the
+// ports are forwarded again on the same local ports.
+func ForwardWithReconnect(ctx context.Context, restConfig *rest.Config, namespace string, target Target, ports []string, log log.Logger) error {
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	forwardedPorts, err := parsePorts(ports)
+	if err != nil {
+		return err
+	}
+
+	connected := false
+	for {
+		pod, podPorts, err := resolveTarget(ctx, kubeClient, namespace, target, forwardedPorts)
+		if err != nil {
+			if !connected {
+				return err
+			}
+
+			log.Warnf("Error selecting pod for %s: %v, retrying...", target.String(), err)
+		} else {
+			localPorts, err := forwardToPod(ctx, restConfig, kubeClient, pod, podPorts, log)
+			if err != nil && !connected {
+				return err
+			}
+
+			// keep the local ports stable across reconnects
+			if localPorts != nil {
+				connected = true
+				for i := range forwardedPorts {
+					forwardedPorts[i].Local = localPorts[i].Local
+				}
+			}
+			if ctx.Err() == nil {
+				log.Warnf("Lost connection to pod %s: %v, reconnecting...", pod.Name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// forwardToPod forwards the ports to the pod until the context is cancelled or the connection
+// breaks. It returns the used ports once the forwarding was ready.
+func forwardToPod(ctx context.Context, restConfig *rest.Config, kubeClient kubernetes.Interface, pod *corev1.Pod, ports []ForwardedPort, log log.Logger) ([]ForwardedPort, error) {
+	execRequest := kubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	portStrings := []string{}
+	for _, port := range ports {
+		portStrings = append(portStrings, strconv.Itoa(int(port.Local))+":"+strconv.Itoa(int(port.Remote)))
+	}
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", execRequest.URL())
+	errChan := make(chan error, 1)
+	readyChan := make(chan struct{})
+	stopChan := make(chan struct{})
+	defer close(stopChan)
+
+	forwarder, err := New(dialer, portStrings, stopChan, readyChan, errChan, io.Discard, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := forwarder.ForwardPorts(ctx)
+		if err != nil {
+			errChan <- err
+		}
+	}()
+
+	// wait till ready
+	select {
+	case err = <-errChan:
+		return nil, err
+	case <-ctx.Done():
+		return nil, nil
+	case <-readyChan:
+	}
+
+	localPorts, err := forwarder.GetPorts()
+	if err != nil {
+		return nil, err
+	}
+	for _, port := range localPorts {
+		log.Donef("Forwarding from 127.0.0.1:%d -> %d (pod %s)", port.Local, port.Remote, pod.Name)
+	}
+
+	// the stream isn't closed reliably if the pod is gone, so check the pod as well
+	ticker := time.NewTicker(podCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return localPorts, nil
+		case err = <-errChan:
+			return localPorts, err
+		case <-ticker.C:
+			err = checkPod(ctx, kubeClient, pod)
+			if err != nil {
+				return localPorts, err
+			}
+		}
+	}
+}
+
+func checkPod(ctx context.Context, kubeClient kubernetes.Interface, pod *corev1.Pod) error {
+	current, err := kubeClient.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		return err
+	} else if current.UID != pod.UID || current.DeletionTimestamp != nil {
+		return fmt.Errorf("pod was replaced")
+	} else if current.Status.Phase != corev1.PodRunning {
+		return fmt.Errorf("pod is in phase %s", current.Status.Phase)
+	} else if restarts(current) != restarts(pod) {
+		return fmt.Errorf("container restarted")
+	}
+
+	return nil
+}
+
+func restarts(pod *corev1.Pod) int32 {
+	count := int32(0)
+	for _, status := range pod.Status.ContainerStatuses {
+		count += status.RestartCount
+	}
+
+	return count
+}
@@ -0,0 +1,160 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Target is a pod or service to forward ports to
+type Target struct {
+	// Kind is either pod or service
+	Kind string
+	Name string
+}
+
+func (t Target) String() string {
+	return t.Kind + "/" + t.Name
+}
+
+// ParseTarget parses a target in the format [pod|po|service|svc/]NAME. Without a prefix the name is a pod.
+func ParseTarget(target string) (Target, error) {
+	kind, name, found := strings.Cut(target, "/")
+	if !found {
+		kind, name = "pod", target
+	}
+	if name == "" {
+		return Target{}, fmt.Errorf("invalid target %s: name is missing", target)
+	}
+
+	switch strings.ToLower(kind) {
+	case "pod", "pods", "po":
+		return Target{Kind: "pod", Name: name}, nil
+	case "service", "services", "svc":
+		return Target{Kind: "service", Name: name}, nil
+	}
+
+	return Target{}, fmt.Errorf("invalid target %s: only pods and services are supported", target)
+}
+
+// resolveTarget selects the pod to forward to and translates the remote ports, which refer to
+// service ports for services, into container ports of the selected pod
+func resolveTarget(ctx context.Context, kubeClient kubernetes.Interface, namespace string, target Target, ports []ForwardedPort) (*corev1.Pod, []ForwardedPort, error) {
+	if target.Kind == "pod" {
+		pod, err := kubeClient.CoreV1().Pods(namespace).Get(ctx, target.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		} else if pod.Status.Phase != corev1.PodRunning {
+			return nil, nil, fmt.Errorf("pod %s is not running (phase %s)", pod.Name, pod.Status.Phase)
+		}
+
+		return pod, ports, nil
+	}
+
+	service, err := kubeClient.CoreV1().Services(namespace).Get(ctx, target.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	} else if len(service.Spec.Selector) == 0 {
+		return nil, nil, fmt.Errorf("service %s has no selector", service.Name)
+	}
+
+	podList, err := kubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String()})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pod := selectPod(podList.Items)
+	if pod == nil {
+		return nil, nil, fmt.Errorf("couldn't find a running pod for service %s", service.Name)
+	}
+
+	podPorts, err := translateServicePorts(service, pod, ports)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pod, podPorts, nil
+}
+
+// selectPod returns a running pod that isn't terminating. Ready pods are preferred.
+func selectPod(pods []corev1.Pod) *corev1.Pod {
+	candidates := []*corev1.Pod{}
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil && pods[i].Status.Phase == corev1.PodRunning {
+			candidates = append(candidates, &pods[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if isPodReady(candidates[i]) != isPodReady(candidates[j]) {
+			return isPodReady(candidates[i])
+		}
+
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0]
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func translateServicePorts(service *corev1.Service, pod *corev1.Pod, ports []ForwardedPort) ([]ForwardedPort, error) {
+	translated := []ForwardedPort{}
+	for _, port := range ports {
+		var servicePort *corev1.ServicePort
+		for i := range service.Spec.Ports {
+			if service.Spec.Ports[i].Port == int32(port.Remote) {
+				servicePort = &service.Spec.Ports[i]
+				break
+			}
+		}
+		if servicePort == nil {
+			return nil, fmt.Errorf("service %s doesn't expose port %d", service.Name, port.Remote)
+		}
+
+		containerPort, err := containerPortForServicePort(servicePort, pod)
+		if err != nil {
+			return nil, err
+		}
+
+		translated = append(translated, ForwardedPort{Local: port.Local, Remote: containerPort})
+	}
+
+	return translated, nil
+}
+
+func containerPortForServicePort(servicePort *corev1.ServicePort, pod *corev1.Pod) (uint16, error) {
+	switch {
+	case servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "":
+		for _, container := range pod.Spec.Containers {
+			for _, port := range container.Ports {
+				if port.Name == servicePort.TargetPort.StrVal {
+					return uint16(port.ContainerPort), nil
+				}
+			}
+		}
+
+		return 0, fmt.Errorf("pod %s has no container port named %s", pod.Name, servicePort.TargetPort.StrVal)
+	case servicePort.TargetPort.Type == intstr.Int && servicePort.TargetPort.IntVal != 0:
+		return uint16(servicePort.TargetPort.IntVal), nil
+	}
+
+	return uint16(servicePort.Port), nil
+}
@@ -0,0 +1,77 @@
+package portforward
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestParseTarget(t *testing.T) {
+	type testCase struct {
+		target      string
+		expected    Target
+		expectedErr bool
+	}
+
+	testTable := []testCase{
+		{target: "my-pod", expected: Target{Kind: "pod", Name: "my-pod"}},
+		{target: "po/my-pod", expected: Target{Kind: "pod", Name: "my-pod"}},
+		{target: "svc/my-api", expected: Target{Kind: "service", Name: "my-api"}},
+		{target: "service/my-api", expected: Target{Kind: "service", Name: "my-api"}},
+		{target: "deployment/my-api", expectedErr: true},
+		{target: "svc/", expectedErr: true},
+	}
+
+	for _, tc := range testTable {
+		target, err := ParseTarget(tc.target)
+		if tc.expectedErr {
+			assert.Assert(t, err != nil, tc.target)
+			continue
+		}
+
+		assert.NilError(t, err, tc.target)
+		assert.Equal(t, target, tc.expected, tc.target)
+	}
+}
+
+func TestSelectPod(t *testing.T) {
+	now := metav1.Now()
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", DeletionTimestamp: &now}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "d"}, Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}},
+	}
+
+	assert.Equal(t, selectPod(pods).Name, "d")
+	assert.Equal(t, selectPod(pods[:3]).Name, "c")
+	assert.Assert(t, selectPod(pods[:2]) == nil)
+}
+
+func TestTranslateServicePorts(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-api"},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Port: 80, TargetPort: intstr.FromString("http")},
+				{Port: 443, TargetPort: intstr.FromInt(8443)},
+				{Port: 9000},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}}},
+		},
+	}
+
+	ports, err := translateServicePorts(service, pod, []ForwardedPort{{Local: 8080, Remote: 80}, {Local: 0, Remote: 443}, {Local: 9000, Remote: 9000}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ports, []ForwardedPort{{Local: 8080, Remote: 8080}, {Local: 0, Remote: 8443}, {Local: 9000, Remote: 9000}})
+
+	_, err = translateServicePorts(service, pod, []ForwardedPort{{Local: 8080, Remote: 8080}})
+	assert.ErrorContains(t, err, "doesn't expose port 8080")
+}
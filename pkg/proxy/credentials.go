@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CachedToken returns a token func that calls fetch at most once per interval. Fetch is expected
+// to refresh the token itself when it is about to expire.
+func CachedToken(interval time.Duration, fetch func() (string, error)) func() (string, error) {
+	lock := sync.Mutex{}
+	token := ""
+	fetched := time.Time{}
+	return func() (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if token != "" && time.Since(fetched) < interval {
+			return token, nil
+		}
+
+		newToken, err := fetch()
+		if err != nil {
+			return "", err
+		}
+
+		token = newToken
+		fetched = time.Now()
+		return token, nil
+	}
+}
+
+// CachedCertificate returns a certificate func that caches the certificate and requests a new
+// one via fetch once 80% of its lifetime, as given by NotBefore and NotAfter, have passed.
+func CachedCertificate(fetch func(forceRefresh bool) (string, string, error)) func() (*tls.Certificate, error) {
+	lock := sync.Mutex{}
+	var certificate *tls.Certificate
+	return func() (*tls.Certificate, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if certificate != nil && time.Now().Before(refreshAt(certificate.Leaf)) {
+			return certificate, nil
+		}
+
+		newCertificate, err := loadCertificate(fetch(certificate != nil))
+		if err != nil {
+			return nil, err
+		}
+
+		certificate = newCertificate
+		return certificate, nil
+	}
+}
+
+// refreshAt returns the time after which the certificate should be replaced
+func refreshAt(certificate *x509.Certificate) time.Time {
+	lifetime := certificate.NotAfter.Sub(certificate.NotBefore)
+	return certificate.NotBefore.Add(lifetime * 4 / 5)
+}
+
+func loadCertificate(certificateData, keyData string, err error) (*tls.Certificate, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(certificateData), []byte(keyData))
+	if err != nil {
+		return nil, fmt.Errorf("parse client certificate: %w", err)
+	}
+
+	certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse client certificate: %w", err)
+	}
+
+	return &certificate, nil
+}
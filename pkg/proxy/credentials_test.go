@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func clientCertificate(t *testing.T, notBefore, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "loft"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestCachedCertificate(t *testing.T) {
+	tests := map[string]struct {
+		notBefore time.Time
+		notAfter  time.Time
+		fetches   []bool
+	}{
+		"fresh short lived certificate is cached": {
+			notBefore: time.Now().Add(-time.Minute),
+			notAfter:  time.Now().Add(29 * time.Minute),
+			fetches:   []bool{false},
+		},
+		"certificate past 80% of its lifetime is refreshed": {
+			notBefore: time.Now().Add(-50 * time.Minute),
+			notAfter:  time.Now().Add(10 * time.Minute),
+			fetches:   []bool{false, true, true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fetches := []bool{}
+			certificate := CachedCertificate(func(forceRefresh bool) (string, string, error) {
+				fetches = append(fetches, forceRefresh)
+				certificateData, keyData := clientCertificate(t, test.notBefore, test.notAfter)
+				return certificateData, keyData, nil
+			})
+
+			for i := 0; i < 3; i++ {
+				_, err := certificate()
+				assert.NilError(t, err)
+			}
+			assert.DeepEqual(t, fetches, test.fetches)
+		})
+	}
+}
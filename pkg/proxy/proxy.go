@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type tokenKey struct{}
+
+// Options configure the upstream of the proxy
+type Options struct {
+	// Server is the upstream kubernetes endpoint including the path prefix
+	Server string
+
+	// CAData is used to verify the upstream, if empty the system roots are used
+	CAData []byte
+
+	// Insecure skips the verification of the upstream certificate
+	Insecure bool
+
+	// Token returns the bearer token to authenticate against the upstream. Either
+	// Token or Certificate should be set.
+	Token func() (string, error)
+
+	// Certificate returns the client certificate to authenticate against the upstream
+	Certificate func() (*tls.Certificate, error)
+}
+
+// New creates a reverse proxy that forwards all requests to the upstream and authenticates them
+// itself. Incoming credentials are dropped. Upgraded connections as used by exec, attach and
+// port-forward as well as streamed responses like watches are supported. Requests for any host
+// other than a loopback host are rejected, so websites can't reach the proxy via DNS rebinding.
+func New(options Options) (http.Handler, error) {
+	target, err := url.Parse(options.Server)
+	if err != nil {
+		return nil, fmt.Errorf("parse server %s: %w", options.Server, err)
+	} else if target.Scheme != "https" && target.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported scheme in server %s", options.Server)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: options.Insecure,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if len(options.CAData) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(options.CAData) {
+			return nil, fmt.Errorf("couldn't parse certificate authority data")
+		}
+	}
+	if options.Certificate != nil {
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return options.Certificate()
+		}
+	}
+
+	// a custom transport only speaks HTTP/1.1, which is required for upgrades
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConnsPerHost: 25,
+	}
+
+	reverseProxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = joinPath(target.Path, req.URL.Path)
+			req.URL.RawPath = ""
+			req.Host = target.Host
+			req.Header.Del("Authorization")
+			if token, ok := req.Context().Value(tokenKey{}).(string); ok {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		},
+		Transport:     transport,
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			http.Error(w, fmt.Sprintf("loft proxy: %v", err), http.StatusBadGateway)
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !IsLoopbackHost(req.Host) {
+			http.Error(w, fmt.Sprintf("loft proxy: host %s not accepted", req.Host), http.StatusForbidden)
+			return
+		}
+
+		if options.Token != nil {
+			token, err := options.Token()
+			if err != nil {
+				http.Error(w, fmt.Sprintf("loft proxy: retrieve token: %v", err), http.StatusBadGateway)
+				return
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), tokenKey{}, token))
+		}
+
+		reverseProxy.ServeHTTP(w, req)
+	}), nil
+}
+
+// IsLoopbackHost returns true if host is localhost or a loopback ip, optionally with a port
+func IsLoopbackHost(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func joinPath(prefix, path string) string {
+	if path == "" || path == "/" {
+		if prefix == "" {
+			return "/"
+		}
+
+		return prefix
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
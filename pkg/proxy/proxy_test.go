@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Upgrade") == "test-protocol" {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			assert.NilError(t, err)
+			defer conn.Close()
+
+			_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test-protocol\r\n\r\n")
+			_ = buf.Flush()
+			line, _ := buf.ReadString('\n')
+			_, _ = conn.Write([]byte("echo: " + line))
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, "%s %s", req.URL.Path, req.Header.Get("Authorization"))
+	}))
+	defer upstream.Close()
+
+	tokens := []string{"first", "second"}
+	handler, err := New(Options{
+		Server:   upstream.URL + "/kubernetes/project/p/virtualcluster/v",
+		Insecure: true,
+		Token: func() (string, error) {
+			token := tokens[0]
+			tokens = tokens[1:]
+			return token, nil
+		},
+	})
+	assert.NilError(t, err)
+
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	// credentials are replaced and the path is prefixed
+	for _, expectedToken := range []string{"first", "second"} {
+		req, err := http.NewRequest(http.MethodGet, proxyServer.URL+"/api/v1/pods", nil)
+		assert.NilError(t, err)
+		req.Header.Set("Authorization", "Bearer client")
+
+		resp, err := http.DefaultClient.Do(req)
+		assert.NilError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.NilError(t, err)
+		assert.Equal(t, string(body), "/kubernetes/project/p/virtualcluster/v/api/v1/pods Bearer "+expectedToken)
+	}
+
+	// upgraded connections are passed through
+	tokens = []string{"upgrade"}
+	conn, err := net.Dial("tcp", strings.TrimPrefix(proxyServer.URL, "http://"))
+	assert.NilError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /api/v1/namespaces/default/pods/test/exec HTTP/1.1\r\nHost: localhost\r\nConnection: Upgrade\r\nUpgrade: test-protocol\r\n\r\n"))
+	assert.NilError(t, err)
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, resp.StatusCode, http.StatusSwitchingProtocols)
+
+	_, err = conn.Write([]byte("hello\n"))
+	assert.NilError(t, err)
+	line, err := reader.ReadString('\n')
+	assert.NilError(t, err)
+	assert.Equal(t, line, "echo: hello\n")
+}
+
+func TestProxyRejectsForeignHosts(t *testing.T) {
+	handler, err := New(Options{Server: "https://upstream.example.com"})
+	assert.NilError(t, err)
+
+	for host, expected := range map[string]int{
+		"evil.example.com":      http.StatusForbidden,
+		"evil.example.com:8001": http.StatusForbidden,
+		"10.0.0.1:8001":         http.StatusForbidden,
+		"localhost.evil.com":    http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api", nil)
+		req.Host = host
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, recorder.Code, expected, host)
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	for host, expected := range map[string]bool{
+		"localhost":        true,
+		"LOCALHOST:8001":   true,
+		"127.0.0.1":        true,
+		"127.0.0.1:8001":   true,
+		"::1":              true,
+		"[::1]":            true,
+		"[::1]:8001":       true,
+		"0.0.0.0":          false,
+		"192.168.1.1:8001": false,
+		"example.com":      false,
+		"":                 false,
+	} {
+		assert.Equal(t, IsLoopbackHost(host), expected, host)
+	}
+}
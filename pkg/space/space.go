@@ -90,3 +90,26 @@ func wakeup(ctx context.Context, managementClient kube.Interface, spaceInstance
 
 	return nil
 }
+
+// UpdateLastActivity marks the space instance as active, which resets its sleep mode timer
+func UpdateLastActivity(ctx context.Context, managementClient kube.Interface, namespace, name string) error {
+	spaceInstance, err := managementClient.Loft().ManagementV1().SpaceInstances(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	oldSpaceInstance := spaceInstance.DeepCopy()
+	if spaceInstance.Annotations == nil {
+		spaceInstance.Annotations = map[string]string{}
+	}
+	spaceInstance.Annotations[clusterv1.SleepModeLastActivityAnnotation] = strconv.FormatInt(time.Now().Unix(), 10)
+
+	patch := client.MergeFrom(oldSpaceInstance)
+	patchData, err := patch.Data(spaceInstance)
+	if err != nil {
+		return err
+	}
+
+	_, err = managementClient.Loft().ManagementV1().SpaceInstances(namespace).Patch(ctx, name, patch.Type(), patchData, metav1.PatchOptions{})
+	return err
+}
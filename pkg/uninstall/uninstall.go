@@ -0,0 +1,778 @@
+package uninstall
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"github.com/loft-sh/loftctl/v3/pkg/clihelper"
+	"github.com/loft-sh/log"
+	"github.com/sirupsen/logrus"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ProjectNamespacePrefix is the prefix of the namespaces loft creates for projects
+const ProjectNamespacePrefix = "loft-p-"
+
+// adminSecret holds the password of the admin user
+const adminSecret = "loft-user-secret-admin"
+
+// DefaultClusterConfigKey is the key of the kube config in the secret of a connected cluster
+const DefaultClusterConfigKey = "config"
+
+const listLimit = 500
+
+// loftSelector selects the objects of the loft chart, which installs loft and its agents
+const loftSelector = "app=loft"
+
+// defaultAgentRelease is the name of the helm release of the agent in connected clusters
+const defaultAgentRelease = "loft"
+
+// verifyInterval is how often Verify checks whether the resources are gone
+const verifyInterval = 2 * time.Second
+
+var (
+	deployments                     = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	namespaces                      = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	secrets                         = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	configMaps                      = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	apiServices                     = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+	validatingWebhookConfigurations = schema.GroupVersionResource{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations"}
+	customResourceDefinitions       = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	clusters                        = schema.GroupVersionResource{Group: "storage.loft.sh", Version: "v1", Resource: "clusters"}
+	clusterRoles                    = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+	clusterRoleBindings             = schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"}
+)
+
+// agentAPIServices are registered by the loft agent, which runs in every connected cluster
+var agentAPIServices = []string{"v1.cluster.loft.sh", "v1alpha1.tenancy.kiosk.sh"}
+
+// Options select what is kept
+type Options struct {
+	// KeepCRDs keeps the custom resource definitions of loft
+	KeepCRDs bool
+	// KeepData keeps the objects of the loft custom resources, the project namespaces and the
+	// admin secret. The custom resource definitions are kept as well, as deleting them would
+	// delete their objects.
+	KeepData bool
+	// ResetAdmin removes the admin user and its secret even if the data is kept, so a new install
+	// creates them again
+	ResetAdmin bool
+	// SkipConnectedClusters leaves the agents in connected clusters alone
+	SkipConnectedClusters bool
+}
+
+// Resource is an object that is removed
+type Resource struct {
+	// Cluster is the name of the connected cluster or empty for the cluster loft runs in
+	Cluster   string
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Name      string
+
+	// Data is true if the object holds state of loft, e.g. users, projects or project namespaces
+	Data bool
+}
+
+// Kind returns the resource and the group of the object, e.g. apiservices.apiregistration.k8s.io
+func (r Resource) Kind() string {
+	if r.Resource.Group == "" {
+		return r.Resource.Resource
+	}
+
+	return r.Resource.Resource + "." + r.Resource.Group
+}
+
+// AgentRelease is the helm release of the loft agent in a connected cluster
+type AgentRelease struct {
+	Cluster   string
+	Namespace string
+	Release   string
+}
+
+// Plan holds everything an uninstall removes in the order it is removed
+type Plan struct {
+	// Release is the helm release of loft or empty if loft isn't installed
+	Release string
+	// AgentReleases are the helm releases of the agents in connected clusters, which are
+	// uninstalled after loft
+	AgentReleases []AgentRelease
+	Resources     []Resource
+
+	// Warnings are the connected clusters that couldn't be accessed, whose leftovers are missing
+	Warnings []string
+
+	options   Options
+	connected []unstructured.Unstructured
+}
+
+// Leftover is a planned resource that still exists after the uninstall
+type Leftover struct {
+	Resource
+	Reason string
+}
+
+// Uninstaller removes loft from the cluster it runs in and its agent from connected clusters
+type Uninstaller struct {
+	KubeClient  kubernetes.Interface
+	Dynamic     dynamic.Interface
+	KubeContext string
+	Namespace   string
+
+	Helm clihelper.Helm
+	// NewClusterClient creates the client of a connected cluster from its kube config
+	NewClusterClient func(kubeConfig []byte) (dynamic.Interface, error)
+
+	clusterClients     map[string]dynamic.Interface
+	clusterKubeConfigs map[string][]byte
+}
+
+// NewUninstaller creates an uninstaller for the loft instance in the namespace
+func NewUninstaller(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, kubeContext, namespace string) *Uninstaller {
+	return &Uninstaller{
+		KubeClient:       kubeClient,
+		Dynamic:          dynamicClient,
+		KubeContext:      kubeContext,
+		Namespace:        namespace,
+		Helm:             clihelper.DefaultHelm,
+		NewClusterClient: NewClusterClient,
+	}
+}
+
+// NewClusterClient creates a dynamic client from a kube config
+func NewClusterClient(kubeConfig []byte) (dynamic.Interface, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewForConfig(restConfig)
+}
+
+// Plan looks up everything the uninstall would remove. Only existing objects are part of the plan.
+// Connected clusters are looked up first, as their configs are removed with the data.
+func (u *Uninstaller) Plan(ctx context.Context, options Options) (*Plan, error) {
+	if options.KeepData {
+		options.KeepCRDs = true
+	}
+
+	plan := &Plan{}
+	releaseName, err := clihelper.LoftReleaseName(ctx, u.KubeClient, u.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := u.Helm.ReleaseExists(ctx, u.helmOptions(releaseName))
+	if err != nil {
+		return nil, err
+	} else if exists {
+		plan.Release = releaseName
+	}
+
+	u.clusterClients = map[string]dynamic.Interface{"": u.Dynamic}
+	u.clusterKubeConfigs = map[string][]byte{}
+	connected := []unstructured.Unstructured{}
+	if !options.SkipConnectedClusters {
+		connected, err = u.connectedClusters(ctx, plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the agents in connected clusters are uninstalled with their helm release, the management
+	// namespace is kept as it might contain objects loft didn't create
+	for _, cluster := range connected {
+		err = u.planAgentRelease(ctx, plan, cluster)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	plan.options = options
+	plan.connected = connected
+	plan.Resources, err = u.lookup(ctx, options, connected)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// lookup returns the existing objects of loft and its agents in the order they are removed. It is
+// used for the plan and again to verify that nothing is left.
+func (u *Uninstaller) lookup(ctx context.Context, options Options, connected []unstructured.Unstructured) ([]Resource, error) {
+	plan := &Plan{}
+
+	// the leftovers of loft and its agent in the cluster loft runs in
+	candidates := []Resource{
+		{Resource: deployments, Namespace: u.Namespace, Name: "loft"},
+		{Resource: apiServices, Name: "v1.management.loft.sh"},
+		{Resource: validatingWebhookConfigurations, Name: "loft-agent"},
+		{Resource: configMaps, Namespace: u.Namespace, Name: "loft-agent-controller"},
+		{Resource: configMaps, Namespace: u.Namespace, Name: "loft-applied-defaults"},
+		{Resource: secrets, Namespace: u.Namespace, Name: clihelper.LoftRouterDomainSecret},
+	}
+	for _, name := range agentAPIServices {
+		candidates = append(candidates, Resource{Resource: apiServices, Name: name})
+	}
+
+	// the leftovers of the agents in connected clusters
+	for _, cluster := range connected {
+		candidates = append(candidates, Resource{Cluster: cluster.GetName(), Resource: validatingWebhookConfigurations, Name: "loft-agent"})
+		for _, name := range agentAPIServices {
+			candidates = append(candidates, Resource{Cluster: cluster.GetName(), Resource: apiServices, Name: name})
+		}
+	}
+
+	for _, candidate := range candidates {
+		err := u.planIfExists(ctx, plan, candidate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	clusterNames := []string{""}
+	for _, cluster := range connected {
+		clusterNames = append(clusterNames, cluster.GetName())
+	}
+	for _, cluster := range clusterNames {
+		err := u.planAgentRBAC(ctx, plan, cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		crds, err := u.loftCRDs(ctx, cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		if !options.KeepData {
+			for _, crd := range crds {
+				objects, err := u.list(ctx, cluster, crdResource(crd))
+				if err != nil {
+					return nil, err
+				}
+
+				for _, object := range objects {
+					plan.Resources = append(plan.Resources, Resource{Cluster: cluster, Resource: crdResource(crd), Namespace: object.GetNamespace(), Name: object.GetName(), Data: true})
+				}
+			}
+
+			if cluster == "" {
+				err = u.planProjectData(ctx, plan)
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else if cluster == "" && options.ResetAdmin {
+			err = u.planAdmin(ctx, plan, crds)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !options.KeepCRDs {
+			for _, crd := range crds {
+				plan.Resources = append(plan.Resources, Resource{Cluster: cluster, Resource: customResourceDefinitions, Name: crd.GetName()})
+			}
+		}
+	}
+
+	return plan.Resources, nil
+}
+
+// planProjectData adds the project namespaces, the kube config secrets of the clusters and the
+// admin secret
+func (u *Uninstaller) planProjectData(ctx context.Context, plan *Plan) error {
+	namespaceList, err := u.list(ctx, "", namespaces)
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaceList {
+		if strings.HasPrefix(namespace.GetName(), ProjectNamespacePrefix) {
+			plan.Resources = append(plan.Resources, Resource{Resource: namespaces, Name: namespace.GetName(), Data: true})
+		}
+	}
+
+	clusterList, err := u.list(ctx, "", clusters)
+	if err != nil {
+		return err
+	}
+
+	for _, cluster := range clusterList {
+		secretName, secretNamespace, _ := clusterConfigSecret(cluster, u.Namespace)
+		if secretName == "" {
+			continue
+		}
+
+		err = u.planIfExists(ctx, plan, Resource{Resource: secrets, Namespace: secretNamespace, Name: secretName, Data: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	return u.planIfExists(ctx, plan, Resource{Resource: secrets, Namespace: u.Namespace, Name: adminSecret, Data: true})
+}
+
+// planAdmin adds the admin user and its secret
+func (u *Uninstaller) planAdmin(ctx context.Context, plan *Plan, crds []unstructured.Unstructured) error {
+	for _, crd := range crds {
+		resource := crdResource(crd)
+		if resource.Group == storagev1.SchemeGroupVersion.Group && resource.Resource == "users" {
+			err := u.planIfExists(ctx, plan, Resource{Resource: resource, Name: "admin", Data: true})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return u.planIfExists(ctx, plan, Resource{Resource: secrets, Namespace: u.Namespace, Name: adminSecret, Data: true})
+}
+
+// planAgentRelease adds the helm release of the agent in the connected cluster if it exists. The
+// agent runs in the management namespace of the cluster and is installed as release loft, unless
+// the release label of its deployment says otherwise.
+func (u *Uninstaller) planAgentRelease(ctx context.Context, plan *Plan, cluster unstructured.Unstructured) error {
+	managementNamespace, _, _ := unstructured.NestedString(cluster.Object, "spec", "managementNamespace")
+	if managementNamespace == "" {
+		managementNamespace = "loft"
+	}
+
+	agentRelease := AgentRelease{Cluster: cluster.GetName(), Namespace: managementNamespace, Release: defaultAgentRelease}
+	deployment, err := u.get(ctx, Resource{Cluster: cluster.GetName(), Resource: deployments, Namespace: managementNamespace, Name: "loft"})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	} else if err == nil && deployment.GetLabels()["release"] != "" {
+		agentRelease.Release = deployment.GetLabels()["release"]
+	}
+
+	exists, err := u.Helm.ReleaseExists(ctx, u.agentHelmOptions(agentRelease))
+	if err != nil {
+		return err
+	} else if exists {
+		plan.AgentReleases = append(plan.AgentReleases, agentRelease)
+	}
+
+	return nil
+}
+
+// planAgentRBAC adds the cluster roles and cluster role bindings of loft and its agent, which are
+// labeled with app=loft
+func (u *Uninstaller) planAgentRBAC(ctx context.Context, plan *Plan, cluster string) error {
+	for _, resource := range []schema.GroupVersionResource{clusterRoleBindings, clusterRoles} {
+		objects, err := u.listSelected(ctx, cluster, resource, loftSelector)
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			plan.Resources = append(plan.Resources, Resource{Cluster: cluster, Resource: resource, Name: object.GetName()})
+		}
+	}
+
+	return nil
+}
+
+func (u *Uninstaller) planIfExists(ctx context.Context, plan *Plan, resource Resource) error {
+	exists, err := u.exists(ctx, resource)
+	if err != nil {
+		return err
+	} else if exists {
+		plan.Resources = append(plan.Resources, resource)
+	}
+
+	return nil
+}
+
+// connectedClusters returns the clusters connected to loft that are reachable with the config
+// in their secret. The cluster loft runs in is skipped, as it is handled anyway.
+func (u *Uninstaller) connectedClusters(ctx context.Context, plan *Plan) ([]unstructured.Unstructured, error) {
+	clusterList, err := u.list(ctx, "", clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	connected := []unstructured.Unstructured{}
+	for _, cluster := range clusterList {
+		local, _, _ := unstructured.NestedBool(cluster.Object, "spec", "local")
+		if local {
+			continue
+		}
+
+		client, kubeConfig, err := u.clusterClient(ctx, cluster)
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("cluster %s: %v", cluster.GetName(), err))
+			continue
+		}
+
+		u.clusterClients[cluster.GetName()] = client
+		u.clusterKubeConfigs[cluster.GetName()] = kubeConfig
+		connected = append(connected, cluster)
+	}
+
+	return connected, nil
+}
+
+func (u *Uninstaller) clusterClient(ctx context.Context, cluster unstructured.Unstructured) (dynamic.Interface, []byte, error) {
+	secretName, secretNamespace, key := clusterConfigSecret(cluster, u.Namespace)
+	if secretName == "" {
+		return nil, nil, fmt.Errorf("no kube config secret")
+	}
+
+	secret, err := u.Dynamic.Resource(secrets).Namespace(secretNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get kube config secret: %w", err)
+	}
+
+	encoded, _, _ := unstructured.NestedString(secret.Object, "data", key)
+	kubeConfig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(kubeConfig) == 0 {
+		return nil, nil, fmt.Errorf("secret %s/%s has no kube config in key %s", secretNamespace, secretName, key)
+	}
+
+	client, err := u.NewClusterClient(kubeConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// make sure the cluster can be reached before anything is planned for it
+	_, err = client.Resource(namespaces).List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, kubeConfig, nil
+}
+
+// clusterConfigSecret returns the name, namespace and key of the secret with the kube config of the
+// connected cluster
+func clusterConfigSecret(cluster unstructured.Unstructured, namespace string) (string, string, string) {
+	secretName, _, _ := unstructured.NestedString(cluster.Object, "spec", "config", "secretName")
+	secretNamespace, _, _ := unstructured.NestedString(cluster.Object, "spec", "config", "secretNamespace")
+	key, _, _ := unstructured.NestedString(cluster.Object, "spec", "config", "key")
+	if secretNamespace == "" {
+		secretNamespace = namespace
+	}
+	if key == "" {
+		key = DefaultClusterConfigKey
+	}
+
+	return secretName, secretNamespace, key
+}
+
+// loftCRDs returns the custom resource definitions of loft and kiosk sorted by name
+func (u *Uninstaller) loftCRDs(ctx context.Context, cluster string) ([]unstructured.Unstructured, error) {
+	crdList, err := u.list(ctx, cluster, customResourceDefinitions)
+	if err != nil {
+		return nil, err
+	}
+
+	crds := []unstructured.Unstructured{}
+	for _, crd := range crdList {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		if IsLoftGroup(group) {
+			crds = append(crds, crd)
+		}
+	}
+
+	sort.Slice(crds, func(i, j int) bool {
+		return crds[i].GetName() < crds[j].GetName()
+	})
+	return crds, nil
+}
+
+// IsLoftGroup returns true for the api groups of loft and kiosk
+func IsLoftGroup(group string) bool {
+	return group == "loft.sh" || strings.HasSuffix(group, ".loft.sh") || group == "kiosk.sh" || strings.HasSuffix(group, ".kiosk.sh")
+}
+
+// crdResource returns the resource of the storage version of the custom resource definition
+func crdResource(crd unstructured.Unstructured) schema.GroupVersionResource {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+	version := ""
+	for _, v := range versions {
+		versionMap, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(versionMap, "name")
+		storage, _, _ := unstructured.NestedBool(versionMap, "storage")
+		if version == "" || storage {
+			version = name
+		}
+	}
+
+	return schema.GroupVersionResource{Group: group, Version: version, Resource: plural}
+}
+
+// Apply uninstalls the helm release and removes the planned resources. Failures are logged and
+// counted, so as much as possible is removed.
+func (u *Uninstaller) Apply(ctx context.Context, plan *Plan, log log.Logger) int {
+	failed := 0
+	if plan.Release != "" {
+		log.Infof("Uninstalling helm release %s in namespace %s...", plan.Release, u.Namespace)
+		err := u.Helm.Uninstall(ctx, u.helmOptions(plan.Release))
+		if err != nil {
+			log.Warnf("Error uninstalling helm release %s: %v", plan.Release, err)
+			failed++
+		}
+	}
+
+	for _, agentRelease := range plan.AgentReleases {
+		log.Infof("Uninstalling helm release %s in namespace %s of cluster %s...", agentRelease.Release, agentRelease.Namespace, agentRelease.Cluster)
+		err := u.Helm.Uninstall(ctx, u.agentHelmOptions(agentRelease))
+		if err != nil {
+			log.Warnf("Error uninstalling helm release %s in cluster %s: %v", agentRelease.Release, agentRelease.Cluster, err)
+			failed++
+		}
+	}
+
+	for _, resource := range plan.Resources {
+		err := u.delete(ctx, resource)
+		if err != nil {
+			log.Warnf("Error deleting %s: %v", Describe(resource), err)
+			failed++
+			continue
+		}
+
+		log.Debugf("Deleted %s", Describe(resource))
+	}
+
+	return failed
+}
+
+// Verification holds what is left after an uninstall
+type Verification struct {
+	// Release is true if the helm release still exists
+	Release bool
+	// AgentReleases are the helm releases of agents that still exist or couldn't be checked
+	AgentReleases []AgentRelease
+	// Leftovers are the resources that still exist or couldn't be checked. Besides the planned
+	// resources these are the objects of loft that are found again after the uninstall.
+	Leftovers []Leftover
+	// LookupError is set if the objects of loft couldn't be looked up again
+	LookupError error
+	// Terminating are the planned resources that are being deleted. Namespaces and custom
+	// resource definitions are deleted asynchronously after their content is gone.
+	Terminating []Resource
+}
+
+// Complete returns true if nothing is left
+func (v *Verification) Complete() bool {
+	return !v.Release && len(v.AgentReleases) == 0 && len(v.Leftovers) == 0 && len(v.Terminating) == 0 && v.LookupError == nil
+}
+
+// Verify checks that the helm release and the planned resources are gone. It polls until
+// everything is gone or the timeout expires and returns what is left at that point.
+func (u *Uninstaller) Verify(ctx context.Context, plan *Plan, timeout time.Duration) *Verification {
+	deadline := time.Now().Add(timeout)
+	for {
+		verification := u.verify(ctx, plan)
+		if verification.Complete() || !time.Now().Before(deadline) {
+			return verification
+		}
+
+		select {
+		case <-ctx.Done():
+			return verification
+		case <-time.After(verifyInterval):
+		}
+	}
+}
+
+func (u *Uninstaller) verify(ctx context.Context, plan *Plan) *Verification {
+	verification := &Verification{}
+	if plan.Release != "" {
+		exists, err := u.Helm.ReleaseExists(ctx, u.helmOptions(plan.Release))
+		verification.Release = exists || err != nil
+	}
+	for _, agentRelease := range plan.AgentReleases {
+		exists, err := u.Helm.ReleaseExists(ctx, u.agentHelmOptions(agentRelease))
+		if exists || err != nil {
+			verification.AgentReleases = append(verification.AgentReleases, agentRelease)
+		}
+	}
+
+	// objects the plan missed, e.g. custom resources created after planning, are left as well
+	resources := plan.Resources
+	found, err := u.lookup(ctx, plan.options, plan.connected)
+	if err != nil {
+		verification.LookupError = err
+	}
+	planned := map[Resource]bool{}
+	for _, resource := range plan.Resources {
+		planned[resource] = true
+	}
+	for _, resource := range found {
+		if !planned[resource] {
+			resources = append(resources, resource)
+		}
+	}
+
+	for _, resource := range resources {
+		object, err := u.get(ctx, resource)
+		if kerrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			verification.Leftovers = append(verification.Leftovers, Leftover{Resource: resource, Reason: fmt.Sprintf("couldn't be checked: %v", err)})
+		} else if object.GetDeletionTimestamp() != nil {
+			verification.Terminating = append(verification.Terminating, resource)
+		} else {
+			verification.Leftovers = append(verification.Leftovers, Leftover{Resource: resource, Reason: "still exists"})
+		}
+	}
+
+	return verification
+}
+
+func (u *Uninstaller) helmOptions(release string) clihelper.HelmOptions {
+	return clihelper.HelmOptions{
+		Release:     release,
+		KubeContext: u.KubeContext,
+		Namespace:   u.Namespace,
+	}
+}
+
+func (u *Uninstaller) agentHelmOptions(agentRelease AgentRelease) clihelper.HelmOptions {
+	return clihelper.HelmOptions{
+		Release:    agentRelease.Release,
+		Namespace:  agentRelease.Namespace,
+		KubeConfig: u.clusterKubeConfigs[agentRelease.Cluster],
+	}
+}
+
+// Describe returns the kind, name and cluster of the resource for messages
+func Describe(resource Resource) string {
+	name := resource.Name
+	if resource.Namespace != "" {
+		name = resource.Namespace + "/" + name
+	}
+	if resource.Cluster != "" {
+		return fmt.Sprintf("%s %s in cluster %s", resource.Kind(), name, resource.Cluster)
+	}
+
+	return resource.Kind() + " " + name
+}
+
+// delete removes the resource. The finalizers of loft objects are removed first, as the controllers
+// that would remove them aren't running anymore.
+func (u *Uninstaller) delete(ctx context.Context, resource Resource) error {
+	client := u.resourceClient(resource)
+	if IsLoftGroup(resource.Resource.Group) {
+		_, err := client.Patch(ctx, resource.Name, types.MergePatchType, []byte(`{"metadata":{"finalizers":null}}`), metav1.PatchOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	err := client.Delete(ctx, resource.Name, metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (u *Uninstaller) exists(ctx context.Context, resource Resource) (bool, error) {
+	_, err := u.get(ctx, resource)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (u *Uninstaller) get(ctx context.Context, resource Resource) (*unstructured.Unstructured, error) {
+	return u.resourceClient(resource).Get(ctx, resource.Name, metav1.GetOptions{})
+}
+
+func (u *Uninstaller) resourceClient(resource Resource) dynamic.ResourceInterface {
+	client := u.clusterClients[resource.Cluster]
+	if client == nil {
+		client = u.Dynamic
+	}
+	if resource.Namespace != "" {
+		return client.Resource(resource.Resource).Namespace(resource.Namespace)
+	}
+
+	return client.Resource(resource.Resource)
+}
+
+// list returns the objects of the resource in all namespaces. A resource that isn't served has no
+// objects.
+func (u *Uninstaller) list(ctx context.Context, cluster string, resource schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	return u.listSelected(ctx, cluster, resource, "")
+}
+
+// listSelected returns the objects of the resource in all namespaces that match the label selector
+func (u *Uninstaller) listSelected(ctx context.Context, cluster string, resource schema.GroupVersionResource, selector string) ([]unstructured.Unstructured, error) {
+	client := u.clusterClients[cluster]
+	if client == nil {
+		client = u.Dynamic
+	}
+
+	objects := []unstructured.Unstructured{}
+	options := metav1.ListOptions{Limit: listLimit, LabelSelector: selector}
+	for {
+		list, err := client.Resource(resource).List(ctx, options)
+		if kerrors.IsNotFound(err) {
+			return objects, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, list.Items...)
+		if list.GetContinue() == "" {
+			return objects, nil
+		}
+		options.Continue = list.GetContinue()
+	}
+}
+
+// Reset removes loft from the cluster of the rest config before it is installed again, as loft
+// start --reset does. The data is kept apart from the admin user, which the new install creates
+// again, and connected clusters aren't touched.
+func Reset(ctx context.Context, kubeClient kubernetes.Interface, restConfig *rest.Config, kubeContext, namespace string, log log.Logger) error {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Uninstalling loft...")
+	uninstaller := NewUninstaller(kubeClient, dynamicClient, kubeContext, namespace)
+	plan, err := uninstaller.Plan(ctx, Options{KeepData: true, ResetAdmin: true, SkipConnectedClusters: true})
+	if err != nil {
+		return err
+	}
+
+	failed := uninstaller.Apply(ctx, plan, log)
+	if failed > 0 {
+		return fmt.Errorf("%d objects of loft couldn't be removed", failed)
+	}
+
+	log.WriteString(logrus.InfoLevel, "\n")
+	log.Done("Successfully uninstalled Loft")
+	log.WriteString(logrus.InfoLevel, "\n")
+	return nil
+}
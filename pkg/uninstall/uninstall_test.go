@@ -0,0 +1,322 @@
+package uninstall
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/loftctl/v3/pkg/clihelper"
+	"github.com/loft-sh/log"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeHelm struct {
+	releases    map[string]bool
+	uninstalled []string
+}
+
+func (f *fakeHelm) Upgrade(ctx context.Context, options clihelper.HelmOptions) error {
+	return nil
+}
+
+func (f *fakeHelm) Template(ctx context.Context, options clihelper.HelmOptions) (string, error) {
+	return "", nil
+}
+
+func (f *fakeHelm) Uninstall(ctx context.Context, options clihelper.HelmOptions) error {
+	f.uninstalled = append(f.uninstalled, helmKey(options))
+	delete(f.releases, helmKey(options))
+	return nil
+}
+
+func (f *fakeHelm) ReleaseExists(ctx context.Context, options clihelper.HelmOptions) (bool, error) {
+	return f.releases[helmKey(options)], nil
+}
+
+// helmKey identifies a release by its namespace and name and the kube config of connected clusters
+func helmKey(options clihelper.HelmOptions) string {
+	key := options.Namespace + "/" + options.Release
+	if len(options.KubeConfig) > 0 {
+		key = string(options.KubeConfig) + ":" + key
+	}
+
+	return key
+}
+
+func object(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for key, value := range fields {
+		u.Object[key] = value
+	}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func labeled(u *unstructured.Unstructured, key, value string) *unstructured.Unstructured {
+	u.SetLabels(map[string]string{key: value})
+	return u
+}
+
+func crd(group, plural string) *unstructured.Unstructured {
+	return object("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", plural+"."+group, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"group": group,
+			"names": map[string]interface{}{"plural": plural},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1beta1", "storage": false},
+				map[string]interface{}{"name": "v1", "storage": true},
+			},
+		},
+	})
+}
+
+func newDynamicClient(objects ...runtime.Object) dynamic.Interface {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		customResourceDefinitions: "CustomResourceDefinitionList",
+		namespaces:                "NamespaceList",
+		clusters:                  "ClusterList",
+		clusterRoles:              "ClusterRoleList",
+		clusterRoleBindings:       "ClusterRoleBindingList",
+		{Group: "storage.loft.sh", Version: "v1", Resource: "users"}: "UserList",
+	}, objects...)
+}
+
+func newUninstaller() (*Uninstaller, *fakeHelm) {
+	admin := object("storage.loft.sh/v1", "User", "", "admin", nil)
+	admin.SetFinalizers([]string{"loft.sh/cleanup"})
+
+	host := newDynamicClient(
+		crd("storage.loft.sh", "users"),
+		crd("storage.loft.sh", "clusters"),
+		crd("example.com", "foos"),
+		admin,
+		object("storage.loft.sh/v1", "Cluster", "", "loft-cluster", map[string]interface{}{"spec": map[string]interface{}{"local": true}}),
+		object("storage.loft.sh/v1", "Cluster", "", "remote", map[string]interface{}{"spec": map[string]interface{}{
+			"config":              map[string]interface{}{"secretName": "remote-config"},
+			"managementNamespace": "loft-agent",
+		}}),
+		object("storage.loft.sh/v1", "Cluster", "", "unreachable", map[string]interface{}{"spec": map[string]interface{}{
+			"config": map[string]interface{}{"secretName": "missing"},
+		}}),
+		object("v1", "Secret", "loft", "remote-config", map[string]interface{}{"data": map[string]interface{}{
+			"config": base64.StdEncoding.EncodeToString([]byte("remote kube config")),
+		}}),
+		object("v1", "Secret", "loft", "loft-user-secret-admin", nil),
+		object("v1", "Namespace", "", "loft", nil),
+		object("v1", "Namespace", "", "loft-p-default", nil),
+		object("v1", "Namespace", "", "other", nil),
+		object("apiregistration.k8s.io/v1", "APIService", "", "v1.management.loft.sh", nil),
+		object("apiregistration.k8s.io/v1", "APIService", "", "v1.cluster.loft.sh", nil),
+		object("apps/v1", "Deployment", "loft", "loft", nil),
+		labeled(object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "loft-management-admin", nil), "app", "loft"),
+		object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "other", nil),
+	)
+	agent := object("apps/v1", "Deployment", "loft-agent", "loft", nil)
+	agent.SetLabels(map[string]string{"release": "my-agent"})
+	remote := newDynamicClient(
+		object("v1", "Namespace", "", "loft-agent", nil),
+		agent,
+		object("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "", "loft-agent", nil),
+		labeled(object("rbac.authorization.k8s.io/v1", "ClusterRoleBinding", "", "loft-agent", nil), "app", "loft"),
+		crd("storage.loft.sh", "users"),
+	)
+	kubeClient := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "loft", Namespace: "loft", Labels: map[string]string{"release": "my-loft"}},
+	})
+
+	helm := &fakeHelm{releases: map[string]bool{"loft/my-loft": true, "remote kube config:loft-agent/my-agent": true}}
+	uninstaller := NewUninstaller(kubeClient, host, "kind", "loft")
+	uninstaller.Helm = helm
+	uninstaller.NewClusterClient = func(kubeConfig []byte) (dynamic.Interface, error) {
+		if string(kubeConfig) != "remote kube config" {
+			return nil, fmt.Errorf("unexpected kube config %q", kubeConfig)
+		}
+
+		return remote, nil
+	}
+	return uninstaller, helm
+}
+
+func describe(resources []Resource) []string {
+	described := []string{}
+	for _, resource := range resources {
+		described = append(described, Describe(resource))
+	}
+
+	return described
+}
+
+func TestPlan(t *testing.T) {
+	tests := map[string]struct {
+		options  Options
+		expected []string
+	}{
+		"everything": {
+			expected: []string{
+				"deployments.apps loft/loft",
+				"apiservices.apiregistration.k8s.io v1.management.loft.sh",
+				"apiservices.apiregistration.k8s.io v1.cluster.loft.sh",
+				"validatingwebhookconfigurations.admissionregistration.k8s.io loft-agent in cluster remote",
+				"clusterroles.rbac.authorization.k8s.io loft-management-admin",
+				"clusters.storage.loft.sh loft-cluster",
+				"clusters.storage.loft.sh remote",
+				"clusters.storage.loft.sh unreachable",
+				"users.storage.loft.sh admin",
+				"namespaces loft-p-default",
+				"secrets loft/remote-config",
+				"secrets loft/loft-user-secret-admin",
+				"customresourcedefinitions.apiextensions.k8s.io clusters.storage.loft.sh",
+				"customresourcedefinitions.apiextensions.k8s.io users.storage.loft.sh",
+				"clusterrolebindings.rbac.authorization.k8s.io loft-agent in cluster remote",
+				"customresourcedefinitions.apiextensions.k8s.io users.storage.loft.sh in cluster remote",
+			},
+		},
+		"keep crds": {
+			options: Options{KeepCRDs: true},
+			expected: []string{
+				"deployments.apps loft/loft",
+				"apiservices.apiregistration.k8s.io v1.management.loft.sh",
+				"apiservices.apiregistration.k8s.io v1.cluster.loft.sh",
+				"validatingwebhookconfigurations.admissionregistration.k8s.io loft-agent in cluster remote",
+				"clusterroles.rbac.authorization.k8s.io loft-management-admin",
+				"clusters.storage.loft.sh loft-cluster",
+				"clusters.storage.loft.sh remote",
+				"clusters.storage.loft.sh unreachable",
+				"users.storage.loft.sh admin",
+				"namespaces loft-p-default",
+				"secrets loft/remote-config",
+				"secrets loft/loft-user-secret-admin",
+				"clusterrolebindings.rbac.authorization.k8s.io loft-agent in cluster remote",
+			},
+		},
+		"keep data": {
+			options: Options{KeepData: true},
+			expected: []string{
+				"deployments.apps loft/loft",
+				"apiservices.apiregistration.k8s.io v1.management.loft.sh",
+				"apiservices.apiregistration.k8s.io v1.cluster.loft.sh",
+				"validatingwebhookconfigurations.admissionregistration.k8s.io loft-agent in cluster remote",
+				"clusterroles.rbac.authorization.k8s.io loft-management-admin",
+				"clusterrolebindings.rbac.authorization.k8s.io loft-agent in cluster remote",
+			},
+		},
+		"reset": {
+			options: Options{KeepData: true, ResetAdmin: true, SkipConnectedClusters: true},
+			expected: []string{
+				"deployments.apps loft/loft",
+				"apiservices.apiregistration.k8s.io v1.management.loft.sh",
+				"apiservices.apiregistration.k8s.io v1.cluster.loft.sh",
+				"clusterroles.rbac.authorization.k8s.io loft-management-admin",
+				"users.storage.loft.sh admin",
+				"secrets loft/loft-user-secret-admin",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			uninstaller, _ := newUninstaller()
+			plan, err := uninstaller.Plan(context.Background(), test.options)
+			assert.NilError(t, err)
+			assert.Equal(t, plan.Release, "my-loft")
+			if test.options.SkipConnectedClusters {
+				assert.Equal(t, len(plan.AgentReleases), 0)
+			} else {
+				assert.DeepEqual(t, plan.AgentReleases, []AgentRelease{{Cluster: "remote", Namespace: "loft-agent", Release: "my-agent"}})
+			}
+			assert.DeepEqual(t, describe(plan.Resources), test.expected)
+			if !test.options.SkipConnectedClusters {
+				assert.Equal(t, len(plan.Warnings), 1)
+				assert.Assert(t, strings.HasPrefix(plan.Warnings[0], "cluster unreachable: get kube config secret"), plan.Warnings[0])
+			}
+		})
+	}
+}
+
+func TestApplyAndVerify(t *testing.T) {
+	ctx := context.Background()
+	uninstaller, helm := newUninstaller()
+	plan, err := uninstaller.Plan(ctx, Options{})
+	assert.NilError(t, err)
+
+	// a plan of a dry run doesn't change anything
+	verification := uninstaller.Verify(ctx, plan, 0)
+	assert.Assert(t, verification.Release)
+	assert.Equal(t, len(verification.AgentReleases), 1)
+	assert.Equal(t, len(verification.Leftovers), len(plan.Resources))
+
+	failed := uninstaller.Apply(ctx, plan, log.Discard)
+	assert.Equal(t, failed, 0)
+	assert.DeepEqual(t, helm.uninstalled, []string{"loft/my-loft", "remote kube config:loft-agent/my-agent"})
+	assert.Assert(t, uninstaller.Verify(ctx, plan, time.Minute).Complete())
+
+	// the management namespace of connected clusters is kept
+	_, err = uninstaller.clusterClients["remote"].Resource(namespaces).Get(ctx, "loft-agent", metav1.GetOptions{})
+	assert.NilError(t, err)
+
+	// objects that aren't part of loft are kept
+	_, err = uninstaller.Dynamic.Resource(namespaces).Get(ctx, "other", metav1.GetOptions{})
+	assert.NilError(t, err)
+	_, err = uninstaller.Dynamic.Resource(customResourceDefinitions).Get(ctx, "foos.example.com", metav1.GetOptions{})
+	assert.NilError(t, err)
+}
+
+func TestVerifyUnplanned(t *testing.T) {
+	ctx := context.Background()
+	uninstaller, _ := newUninstaller()
+	plan, err := uninstaller.Plan(ctx, Options{KeepCRDs: true})
+	assert.NilError(t, err)
+	assert.Equal(t, uninstaller.Apply(ctx, plan, log.Discard), 0)
+
+	// objects created after planning are reported as well
+	late := labeled(object("rbac.authorization.k8s.io/v1", "ClusterRole", "", "loft-late", nil), "app", "loft")
+	_, err = uninstaller.Dynamic.Resource(clusterRoles).Create(ctx, late, metav1.CreateOptions{})
+	assert.NilError(t, err)
+	_, err = uninstaller.Dynamic.Resource(schema.GroupVersionResource{Group: "storage.loft.sh", Version: "v1", Resource: "users"}).Create(ctx, object("storage.loft.sh/v1", "User", "", "late", nil), metav1.CreateOptions{})
+	assert.NilError(t, err)
+
+	verification := uninstaller.Verify(ctx, plan, 0)
+	leftovers := []string{}
+	for _, leftover := range verification.Leftovers {
+		leftovers = append(leftovers, Describe(leftover.Resource))
+	}
+	assert.DeepEqual(t, leftovers, []string{
+		"clusterroles.rbac.authorization.k8s.io loft-late",
+		"users.storage.loft.sh late",
+	})
+	assert.Assert(t, !verification.Complete())
+}
+
+func TestVerifyTerminating(t *testing.T) {
+	ctx := context.Background()
+	terminating := object("v1", "Namespace", "", "loft-p-default", nil)
+	terminating.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	uninstaller, _ := newUninstaller()
+	uninstaller.Dynamic = newDynamicClient(terminating, object("v1", "Namespace", "", "loft-p-other", nil))
+	uninstaller.clusterClients = nil
+
+	plan := &Plan{Resources: []Resource{
+		{Resource: namespaces, Name: "loft-p-default", Data: true},
+		{Resource: namespaces, Name: "loft-p-other", Data: true},
+		{Resource: namespaces, Name: "loft-p-gone", Data: true},
+	}}
+	verification := uninstaller.Verify(ctx, plan, 0)
+	assert.Assert(t, !verification.Release)
+	assert.DeepEqual(t, describe(verification.Terminating), []string{"namespaces loft-p-default"})
+	assert.DeepEqual(t, verification.Leftovers, []Leftover{{Resource: plan.Resources[1], Reason: "still exists"}})
+	assert.Assert(t, !verification.Complete())
+}
@@ -119,3 +119,26 @@ func wakeup(ctx context.Context, managementClient kube.Interface, virtualCluster
 
 	return nil
 }
+
+// UpdateLastActivity marks the virtual cluster instance as active, which resets its sleep mode timer
+func UpdateLastActivity(ctx context.Context, managementClient kube.Interface, namespace, name string) error {
+	virtualClusterInstance, err := managementClient.Loft().ManagementV1().VirtualClusterInstances(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	oldVirtualClusterInstance := virtualClusterInstance.DeepCopy()
+	if virtualClusterInstance.Annotations == nil {
+		virtualClusterInstance.Annotations = map[string]string{}
+	}
+	virtualClusterInstance.Annotations[clusterv1.SleepModeLastActivityAnnotation] = strconv.FormatInt(time.Now().Unix(), 10)
+
+	patch := client2.MergeFrom(oldVirtualClusterInstance)
+	patchData, err := patch.Data(virtualClusterInstance)
+	if err != nil {
+		return err
+	}
+
+	_, err = managementClient.Loft().ManagementV1().VirtualClusterInstances(namespace).Patch(ctx, name, patch.Type(), patchData, metav1.PatchOptions{})
+	return err
+}
@@ -84,3 +84,32 @@ func GetLatestMatchedVersion(versions storagev1.VersionsAccessor, versionPattern
 
 	return latestVersion, latestMatchedVersion, nil
 }
+
+// ResolveVersion returns the latest version and the version the given pattern resolves to. An empty
+// pattern always resolves to the latest version.
+func ResolveVersion(versions storagev1.VersionsAccessor, versionPattern string) (latestVersion storagev1.VersionAccessor, resolvedVersion storagev1.VersionAccessor, err error) {
+	if versionPattern == "" {
+		latestVersion = GetLatestVersion(versions)
+		return latestVersion, latestVersion, nil
+	}
+
+	return GetLatestMatchedVersion(versions, versionPattern)
+}
+
+// IsOlder returns true if version is older than other. Versions that cannot be parsed are never older.
+func IsOlder(version, other storagev1.VersionAccessor) bool {
+	if version == nil || other == nil {
+		return false
+	}
+
+	parsedVersion, err := semver.Parse(strings.TrimPrefix(version.GetVersion(), "v"))
+	if err != nil {
+		return false
+	}
+	parsedOther, err := semver.Parse(strings.TrimPrefix(other.GetVersion(), "v"))
+	if err != nil {
+		return false
+	}
+
+	return parsedVersion.LT(parsedOther)
+}
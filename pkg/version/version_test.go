@@ -0,0 +1,61 @@
+package version
+
+import (
+	"testing"
+
+	storagev1 "github.com/loft-sh/api/v3/pkg/apis/storage/v1"
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveVersion(t *testing.T) {
+	type testCase struct {
+		desc             string
+		pattern          string
+		expectedResolved string
+		expectedOutdated bool
+	}
+
+	template := &storagev1.VirtualClusterTemplate{
+		Spec: storagev1.VirtualClusterTemplateSpec{
+			Versions: []storagev1.VirtualClusterTemplateVersion{
+				{Version: "1.0.0"},
+				{Version: "1.1.0"},
+				{Version: "v2.0.1"},
+				{Version: "invalid"},
+			},
+		},
+	}
+
+	testTable := []testCase{
+		{
+			desc:             "empty pattern resolves to latest",
+			pattern:          "",
+			expectedResolved: "v2.0.1",
+		},
+		{
+			desc:             "pinned to old version",
+			pattern:          "1.0.0",
+			expectedResolved: "1.0.0",
+			expectedOutdated: true,
+		},
+		{
+			desc:             "pinned to old minor",
+			pattern:          "1.x.x",
+			expectedResolved: "1.1.0",
+			expectedOutdated: true,
+		},
+		{
+			desc:             "pinned to latest major",
+			pattern:          "2.x.x",
+			expectedResolved: "v2.0.1",
+		},
+	}
+
+	for _, tc := range testTable {
+		latest, resolved, err := ResolveVersion(template, tc.pattern)
+		assert.NilError(t, err, tc.desc)
+		assert.Equal(t, latest.GetVersion(), "v2.0.1", tc.desc)
+		assert.Equal(t, resolved.GetVersion(), tc.expectedResolved, tc.desc)
+		assert.Equal(t, IsOlder(resolved, latest), tc.expectedOutdated, tc.desc)
+	}
+}